@@ -74,12 +74,6 @@ func main() {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	go func() {
-		if err := validator.Run(options); err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
-		}
-	}()
 	leaderElection := defaultLeaderElectionConfiguration()
 	if len(runConfig.ElectionResourceLock) != 0 {
 		leaderElection.ResourceLock = runConfig.ElectionResourceLock
@@ -98,6 +92,15 @@ func main() {
 
 	coreFactory := informers.NewSharedInformerFactory(client, runConfig.Resync)
 	scalerFactory := autoscalinginformer.NewSharedInformerFactory(gpaClient, runConfig.Resync)
+	calendarInformer := scalerFactory.Autoscaling().V1alpha1().HolidayCalendars()
+
+	go func() {
+		if err := validator.Run(options, calendarInformer.Lister(), calendarInformer.Informer().HasSynced,
+			client.CoreV1()); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}()
 
 	cachedClient := cacheddiscovery.NewMemCacheClient(discovery.NewDiscoveryClientForConfigOrDie(kubeconfig))
 	restMapper := restmapper.NewDeferredDiscoveryRESTMapper(cachedClient)
@@ -125,6 +128,8 @@ func main() {
 		metricsClient,
 		scalerFactory.Autoscaling().V1alpha1().GeneralPodAutoscalers(),
 		coreFactory.Core().V1().Pods(),
+		calendarInformer,
+		client.CoreV1(),
 		runConfig.GeneralPodAutoscalerSyncPeriod.Duration,
 		runConfig.GeneralPodAutoscalerDownscaleStabilizationWindow.Duration,
 		runConfig.GeneralPodAutoscalerTolerance,
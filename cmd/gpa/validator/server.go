@@ -26,16 +26,25 @@ import (
 	"strconv"
 	"time"
 
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
+	listers "github.com/ocgi/general-pod-autoscaler/pkg/client/listers/autoscaling/v1alpha1"
 	"github.com/ocgi/general-pod-autoscaler/pkg/util"
 	"github.com/ocgi/general-pod-autoscaler/pkg/validator"
 )
 
-func Run(s *ServerRunOptions) error {
+func Run(s *ServerRunOptions, calendars listers.HolidayCalendarLister, calendarsSynced cache.InformerSynced,
+	secrets corev1client.SecretsGetter) error {
 	stopCh := util.SetupSignalHandler()
 
-	webHook := webhook.NewWebhookServer()
+	// the referenced calendars are checked by the lister, do not serve before it is synced
+	if !cache.WaitForNamedCacheSync("validator", stopCh, calendarsSynced) {
+		return nil
+	}
+
+	webHook := webhook.NewWebhookServer(calendars, secrets)
 
 	// Start debug monitor.
 	mux := http.NewServeMux()
@@ -0,0 +1,86 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// webhook-example is a webhook of GPA webhook mode built on pkg/requests/server. It keeps `buffer` idle
+// pods above the ready pods of workload, `buffer` is the parameter of webhook mode, default 1.
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests/server"
+	"github.com/ocgi/general-pod-autoscaler/pkg/util"
+)
+
+// bufferDecider recommends the ready pods of workload plus buffer, within the replicas limits
+func bufferDecider(ctx context.Context, req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+	buffer := int32(1)
+	if value, ok := req.Parameters["buffer"]; ok {
+		b, err := strconv.Atoi(value)
+		if err != nil || b < 0 {
+			return nil, server.Reject(http.StatusBadRequest, "InvalidParameter", "buffer should be a non-negative integer")
+		}
+		buffer = int32(b)
+	}
+	if req.ReadyPods == nil {
+		// the pods are unknown, keep the replicas
+		return &requests.AutoscaleResponse{}, nil
+	}
+	replicas := *req.ReadyPods + buffer
+	if req.MinReplicas != nil && replicas < *req.MinReplicas {
+		replicas = *req.MinReplicas
+	}
+	if req.MaxReplicas > 0 && replicas > req.MaxReplicas {
+		replicas = req.MaxReplicas
+	}
+	return &requests.AutoscaleResponse{Scale: true, Replicas: replicas}, nil
+}
+
+func main() {
+	var (
+		options   server.Options
+		tokenFile string
+	)
+	pflag.StringVar(&options.Address, "address", "0.0.0.0:8000", "The address the webhook listens on.")
+	pflag.StringVar(&options.TLSCert, "tlscert", "", "Path to TLS certificate file, serve without TLS if not set.")
+	pflag.StringVar(&options.TLSKey, "tlskey", "", "Path to TLS key file.")
+	pflag.StringVar(&options.TLSCA, "CA", "", "Path to CA verifying the client certificates.")
+	pflag.BoolVar(&options.GRPC, "grpc", false, "Serve the gRPC protocol instead of JSON.")
+	pflag.StringVar(&tokenFile, "token-file", "", "Path to the bearer token required from GPA.")
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+	defer klog.Flush()
+
+	var token string
+	if len(tokenFile) != 0 {
+		data, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			klog.Fatalf("Read token failed: %v", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	s := server.NewServer(server.DeciderFunc(bufferDecider), token)
+	if err := s.Run(options, util.SetupSignalHandler()); err != nil {
+		klog.Fatal(err)
+	}
+}
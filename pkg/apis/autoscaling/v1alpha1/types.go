@@ -60,10 +60,9 @@ type GeneralPodAutoscalerSpec struct {
 	ScaleTargetRef CrossVersionObjectReference `json:"scaleTargetRef" protobuf:"bytes,1,opt,name=scaleTargetRef"`
 
 	// minReplicas is the lower limit for the number of replicas to which the autoscaler
-	// can scale down.  It defaults to 1 pod.  minReplicas is allowed to be 0 if the
-	// alpha feature gate GPAScaleToZero is enabled and at least one Object or External
-	// metric is configured.  Scaling is active as long as at least one metric value is
-	// available.
+	// can scale down.  It defaults to 1 pod.  minReplicas is allowed to be 0 if at least
+	// one Object or External metric or webhook mode is configured, see idle for the scaling
+	// to zero.  Scaling is active as long as at least one metric value is available.
 	// +optional
 	MinReplicas *int32 `json:"minReplicas,omitempty" protobuf:"varint,2,opt,name=minReplicas"`
 
@@ -77,6 +76,94 @@ type GeneralPodAutoscalerSpec struct {
 	// If not set, the default GPAScalingRules for scale up and scale down are used.
 	// +optional
 	Behavior *GeneralPodAutoscalerBehavior `json:"behavior,omitempty" protobuf:"bytes,4,opt,name=behavior"`
+
+	// modeSelectPolicy is used to specify how the replicas proposals of all the driven modes are combined:
+	// Max, Min, or the name of a driven mode (metric, cronMetric, webhook, time, event) whose proposal wins.
+	// If not set, the default value Max is used.
+	// +optional
+	ModeSelectPolicy *ModeSelectPolicy `json:"modeSelectPolicy,omitempty" protobuf:"bytes,5,opt,name=modeSelectPolicy"`
+
+	// dryRun makes the autoscaler compute the desired replicas with the metrics, cron limits, behaviors
+	// and stabilization as usual, but never update the scale of target. The decision is recorded in
+	// status.dryRun and events only, so that it can be compared with the autoscaler in control.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty" protobuf:"varint,6,opt,name=dryRun"`
+
+	// override suspends the autoscaling, e.g. during an incident: the target is left as it is or held at
+	// the replicas of override until it expires. The recommendation is still computed and reported.
+	// +optional
+	Override *ScaleOverride `json:"override,omitempty" protobuf:"bytes,7,opt,name=override"`
+
+	// idle configures scaling the target to zero replicas and activating it from zero, it is used if
+	// minReplicas is 0. If not set, the target is scaled to zero as soon as the signals recommend zero
+	// replicas, and activated with 1 replica.
+	// +optional
+	Idle *IdleScaling `json:"idle,omitempty" protobuf:"bytes,8,opt,name=idle"`
+
+	// metricAggregation is used to specify how the replicas proposals of the metrics in metric and cron
+	// metric modes are combined. If not set, the highest proposal is used.
+	// +optional
+	MetricAggregation *MetricAggregation `json:"metricAggregation,omitempty" protobuf:"bytes,9,opt,name=metricAggregation"`
+}
+
+// MetricAggregationPolicy is used to specify how the replicas proposals of the metrics are combined
+type MetricAggregationPolicy string
+
+const (
+	// MaxMetricAggregation uses the highest proposal of metrics.
+	MaxMetricAggregation MetricAggregationPolicy = "Max"
+	// MinMetricAggregation uses the lowest proposal of metrics.
+	MinMetricAggregation MetricAggregationPolicy = "Min"
+	// AverageMetricAggregation uses the average of proposals of metrics, rounded up.
+	AverageMetricAggregation MetricAggregationPolicy = "Average"
+	// WeightedAverageMetricAggregation uses the average of proposals of metrics weighted by the weight of
+	// metric, rounded up.
+	WeightedAverageMetricAggregation MetricAggregationPolicy = "WeightedAverage"
+	// QuorumMetricAggregation uses the highest replicas proposed, or exceeded, by a quorum of metrics.
+	QuorumMetricAggregation MetricAggregationPolicy = "Quorum"
+)
+
+// MetricAggregation specifies how the replicas proposals of the metrics are combined
+type MetricAggregation struct {
+	// policy is Max, Min, Average, WeightedAverage or Quorum.
+	Policy MetricAggregationPolicy `json:"policy" protobuf:"bytes,1,name=policy,casttype=MetricAggregationPolicy"`
+
+	// quorum is the number of metrics which must propose the replicas or more, used by Quorum policy.
+	// If not set, the majority of metrics with valid proposals is used. It is capped by the number of
+	// metrics with valid proposals.
+	// +optional
+	Quorum *int32 `json:"quorum,omitempty" protobuf:"varint,2,opt,name=quorum"`
+}
+
+// IdleScaling configures the scaling to zero and the activation from zero. Only the signals which do not
+// need running pods, i.e. Object and External metrics and webhook, can scale the target from or to zero.
+type IdleScaling struct {
+	// idlePeriodSeconds is how long the signals must recommend zero replicas before the target is scaled
+	// to zero. The target is kept at 1 replica at least during the period.
+	// +optional
+	IdlePeriodSeconds int32 `json:"idlePeriodSeconds,omitempty" protobuf:"varint,1,opt,name=idlePeriodSeconds"`
+
+	// activationReplicas is the number of replicas the target is scaled to at least when the signals
+	// return and it is activated from zero, capped by maxReplicas. Defaults to 1.
+	// +optional
+	ActivationReplicas *int32 `json:"activationReplicas,omitempty" protobuf:"varint,2,opt,name=activationReplicas"`
+}
+
+// ScaleOverride pauses the autoscaling or holds the target at a number of replicas
+type ScaleOverride struct {
+	// replicas is the number of replicas the target is held at. If not set, the autoscaling is paused
+	// and the target is left as it is.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty" protobuf:"varint,1,opt,name=replicas"`
+
+	// expirationTime is the time the override ends and the autoscaling takes back control.
+	// If not set, the override is in force until it is removed.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty" protobuf:"bytes,2,opt,name=expirationTime"`
+
+	// reason is why the autoscaling is overridden, it is shown in the condition and events.
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,3,opt,name=reason"`
 }
 
 // ExternalAutoScalingDrivenMode defines the mode to trigger auto scaling
@@ -102,6 +189,25 @@ type AutoScalingDrivenMode struct {
 	EventMode *EventMode `json:"event,omitempty" protobuf:"bytes,4,opt,name=event"`
 }
 
+// Names of the driven modes, used by ModeSelectPolicy and ModeProposal
+const (
+	MetricModeName     = "metric"
+	CronMetricModeName = "cronMetric"
+	WebhookModeName    = "webhook"
+	TimeModeName       = "time"
+	EventModeName      = "event"
+)
+
+// ModeSelectPolicy is used to specify how the replicas proposals of the driven modes are combined
+type ModeSelectPolicy string
+
+const (
+	// MaxModeSelect selects the highest proposal of all driven modes.
+	MaxModeSelect ModeSelectPolicy = "Max"
+	// MinModeSelect selects the lowest proposal of all driven modes.
+	MinModeSelect ModeSelectPolicy = "Min"
+)
+
 type MetricMode struct {
 	// metrics contains the specifications for which to use to calculate the
 	// desired replica count (the maximum replica count across all metrics will
@@ -137,21 +243,137 @@ type WebhookMode struct {
 	*admregv1b.WebhookClientConfig `json:",inline"`
 	// Parameters are the webhook parameters
 	Parameters map[string]string `json:"parameters,omitempty" protobuf:"bytes,1,opt,name=parameters"`
+	// Authentication is the credentials presented to the webhook
+	// +optional
+	Authentication *WebhookAuthentication `json:"authentication,omitempty" protobuf:"bytes,2,opt,name=authentication"`
+	// Timeout is the timeout of each call to the webhook including its retries, defaults to 15s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty" protobuf:"bytes,3,opt,name=timeout"`
+	// Retry is the retry policy of the failed calls, a call is not retried if not set.
+	// +optional
+	Retry *WebhookRetry `json:"retry,omitempty" protobuf:"bytes,4,opt,name=retry"`
+	// CircuitBreaker stops calling the webhook after consecutive failures, and recommends by the
+	// fallback policy until the webhook recovers. The failures mark the GPA unable to scale if not set.
+	// +optional
+	CircuitBreaker *WebhookCircuitBreaker `json:"circuitBreaker,omitempty" protobuf:"bytes,5,opt,name=circuitBreaker"`
+	// Protocol is the protocol of webhook, defaults to HTTP.
+	// +optional
+	Protocol WebhookProtocol `json:"protocol,omitempty" protobuf:"bytes,6,opt,name=protocol,casttype=WebhookProtocol"`
+	// Batch groups the calls of GPAs sharing the webhook, and sends them in one AutoscaleBatchReview.
+	// It is only supported by HTTP protocol.
+	// +optional
+	Batch *WebhookBatch `json:"batch,omitempty" protobuf:"bytes,7,opt,name=batch"`
+}
+
+// WebhookBatch is the batching of calls of GPAs sharing the webhook. The GPAs share the webhook if they
+// call the same url with the same CA bundle and credentials.
+type WebhookBatch struct {
+	// Window is how long a call waits for the calls of other GPAs before the batch is sent, defaults to 100ms.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty" protobuf:"bytes,1,opt,name=window"`
+	// MaxSize is the max number of requests in a batch, the batch is sent once it is full, defaults to 100.
+	// +optional
+	MaxSize *int32 `json:"maxSize,omitempty" protobuf:"varint,2,opt,name=maxSize"`
+}
+
+// WebhookProtocol is the protocol of webhook
+type WebhookProtocol string
+
+const (
+	// HTTPWebhookProtocol posts the AutoscaleReview in JSON to the url of webhook
+	HTTPWebhookProtocol WebhookProtocol = "HTTP"
+	// GRPCWebhookProtocol calls Autoscale of the gRPC service Autoscaler defined in
+	// pkg/requests/autoscalepb, the path of url is ignored
+	GRPCWebhookProtocol WebhookProtocol = "GRPC"
+)
+
+// WebhookRetry is the retry policy of webhook calls. A call is retried if it fails without answer, or the
+// server answers 429 or 5xx, the interval of retries is doubled from backoff until maxBackoff.
+type WebhookRetry struct {
+	// MaxRetries is the max number of retries of a failed call, defaults to 2.
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty" protobuf:"varint,1,opt,name=maxRetries"`
+	// Backoff is the interval before the first retry, defaults to 200ms.
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty" protobuf:"bytes,2,opt,name=backoff"`
+	// MaxBackoff is the max interval between retries, defaults to 2s.
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty" protobuf:"bytes,3,opt,name=maxBackoff"`
+}
+
+// WebhookFallbackPolicy is the replicas recommended while the circuit breaker of webhook is open
+type WebhookFallbackPolicy string
+
+const (
+	// KeepCurrentFallback recommends the current replicas
+	KeepCurrentFallback WebhookFallbackPolicy = "KeepCurrent"
+	// LastGoodFallback recommends the replicas of the last successful response, or the current
+	// replicas if there is none
+	LastGoodFallback WebhookFallbackPolicy = "LastGood"
+	// MinFallback recommends the min replicas
+	MinFallback WebhookFallbackPolicy = "Min"
+)
+
+// WebhookCircuitBreaker is the circuit breaker of webhook calls
+type WebhookCircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed calls which opens the breaker, defaults to 3.
+	// A call failed after retries is counted once, a rejection by the server is not a failure.
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty" protobuf:"varint,1,opt,name=failureThreshold"`
+	// OpenDuration is how long the breaker keeps open before the webhook is tried again, defaults to 1m.
+	// The breaker closes if the try succeeds, otherwise it keeps open for another duration.
+	// +optional
+	OpenDuration *metav1.Duration `json:"openDuration,omitempty" protobuf:"bytes,2,opt,name=openDuration"`
+	// Fallback is the policy recommending replicas while the breaker is open, defaults to KeepCurrent.
+	// +optional
+	Fallback WebhookFallbackPolicy `json:"fallback,omitempty" protobuf:"bytes,3,opt,name=fallback"`
+}
+
+// WebhookAuthentication references the credentials presented to the webhook
+type WebhookAuthentication struct {
+	// SecretName is the name of a Secret in the namespace of GPA. The client certificate of mutual TLS is
+	// taken from `tls.crt` and `tls.key` of the Secret, and the bearer token of `Authorization` header is
+	// taken from `token` of the Secret. At least one of them should be set.
+	SecretName string `json:"secretName" protobuf:"bytes,1,name=secretName"`
 }
 
 // TimeMode is a mode allows user to define a crontab regular
 type TimeMode struct {
 	// TimeRanges defines a array that for time driven mode
 	TimeRanges []TimeRange `json:"ranges,omitempty" protobuf:"bytes,1,opt,name=ranges"`
+
+	// TimeZone is the IANA time zone name, e.g. `Asia/Shanghai`, the schedules are evaluated in.
+	// Defaults to the local time zone of the controller.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,2,opt,name=timeZone"`
 }
 
 // TimeTimeRange is a mode allows user to define a crontab regular
 type TimeRange struct {
-	// Schedule should match crontab format
+	// Schedule should match crontab format, the range is active in about one minute after a fire time.
+	// Either Schedule or Start should be set.
+	// +optional
 	Schedule string `json:"schedule,omitempty" protobuf:"bytes,1,opt,name=schedule"`
 
+	// Start should match crontab format, the range is active from a fire time of Start until the
+	// next fire time of End, or until Duration passed.
+	// +optional
+	Start string `json:"start,omitempty" protobuf:"bytes,4,opt,name=start"`
+
+	// End should match crontab format, it closes the range opened by Start.
+	// +optional
+	End string `json:"end,omitempty" protobuf:"bytes,5,opt,name=end"`
+
+	// Duration is how long the range opened by Start is active, instead of End.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty" protobuf:"bytes,6,opt,name=duration"`
+
 	// DesiredReplicas is the desired replicas required by timemode,
 	DesiredReplicas int32 `json:"desiredReplicas,omitempty" protobuf:"varint,2,opt,name=desiredReplicas"`
+
+	// TimeZone is the IANA time zone name the schedule is evaluated in, overrides the time zone of TimeMode.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,3,opt,name=timeZone"`
 }
 
 // CrossVersionObjectReference contains enough information to let you identify the referred resource.
@@ -177,14 +399,34 @@ type CronMetricMode struct {
 	// +optional
 	CronMetrics []CronMetricSpec `json:"cronMetrics,omitempty" protobuf:"bytes,1,opt,name=cronMetrics"`
 
+	// TimeZone is the IANA time zone name, e.g. `Asia/Shanghai`, the schedules are evaluated in.
+	// Defaults to the local time zone of the controller.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,2,opt,name=timeZone"`
+
 	// miss Cron time, set default replicas.
 	//DefaultReplicas int32 `json:"defaultReplicas" protobuf:"varint,3,opt,name=maxReplicas"`
 }
 
 type CronMetricSpec struct {
-	// Schedule should match crontab format
+	// Schedule should match crontab format, the spec is active in about one minute after a fire time.
+	// `default` schedule is used if no other spec is active.
+	// +optional
 	Schedule string `json:"schedule,omitempty" protobuf:"bytes,1,opt,name=schedule"`
 
+	// Start should match crontab format, the spec is active from a fire time of Start until the
+	// next fire time of End, or until Duration passed. It should not be set with Schedule.
+	// +optional
+	Start string `json:"start,omitempty" protobuf:"bytes,6,opt,name=start"`
+
+	// End should match crontab format, it closes the window opened by Start.
+	// +optional
+	End string `json:"end,omitempty" protobuf:"bytes,7,opt,name=end"`
+
+	// Duration is how long the window opened by Start is active, instead of End.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty" protobuf:"bytes,8,opt,name=duration"`
+
 	// minReplicas is the lower limit for the number of replicas to which the autoscaler
 	// can scale down.  It defaults to 1 pod.  minReplicas is allowed to be 0 if the
 	// alpha feature gate GPAScaleToZero is enabled and at least one Object or External
@@ -200,6 +442,15 @@ type CronMetricSpec struct {
 	// Priority When there are two identical cron rules, select according to priority
 	Priority int `json:"priority" protobuf:"varint,3,opt,name=priority"`
 
+	// Calendar is the name of a HolidayCalendar, the spec is active on the holidays of the calendar.
+	// If Schedule or Start is set too, the spec is active when the schedule matches on the holidays.
+	// +optional
+	Calendar string `json:"calendar,omitempty" protobuf:"bytes,5,opt,name=calendar"`
+
+	// TimeZone is the IANA time zone name the schedule is evaluated in, overrides the time zone of CronMetricMode.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,4,opt,name=timeZone"`
+
 	// MetricSpec specifies how to scale based on a single metric
 	// (only `type` and one other matching field should be set at once).
 	MetricSpec
@@ -243,6 +494,11 @@ type MetricSpec struct {
 	// This is an alpha feature and can be enabled by the HPAContainerMetrics feature flag.
 	// +optional
 	ContainerResource *ContainerResourceMetricSource `json:"containerResource,omitempty" protobuf:"bytes,6,opt,name=containerResource"`
+
+	// weight is the weight of the proposal of metric used by WeightedAverage metric aggregation.
+	// Defaults to 1.
+	// +optional
+	Weight *int32 `json:"weight,omitempty" protobuf:"varint,7,opt,name=weight"`
 }
 
 // GeneralPodAutoscalerBehavior configures the scaling behavior of the target
@@ -487,6 +743,200 @@ type GeneralPodAutoscalerStatus struct {
 
 	// LastCronScheduleTime is the schedule time of time mode
 	LastCronScheduleTime *metav1.Time `json:"lastCronScheduleTime" protobuf:"bytes,7,rep,name=lastCronScheduleTime"`
+
+	// lastCronScheduleName is the schedule of time mode recommending the replicas in the last calculation,
+	// LastCronScheduleTime is only used by the same schedule.
+	// +optional
+	LastCronScheduleName string `json:"lastCronScheduleName,omitempty" protobuf:"bytes,10,opt,name=lastCronScheduleName"`
+
+	// modeProposals are the replicas proposed by each driven mode in the last calculation.
+	// +optional
+	ModeProposals []ModeProposal `json:"modeProposals,omitempty" protobuf:"bytes,8,rep,name=modeProposals"`
+
+	// recommendations are the replicas recommended by each evaluated source in the last calculation,
+	// sources are the metrics, cron metrics, time ranges, webhook and event triggers.
+	// +optional
+	Recommendations []Recommendation `json:"recommendations,omitempty" protobuf:"bytes,9,rep,name=recommendations"`
+
+	// cronSchedule is the schedule of cron metric mode in force and the next transition of schedule.
+	// +optional
+	CronSchedule *CronScheduleStatus `json:"cronSchedule,omitempty" protobuf:"bytes,11,opt,name=cronSchedule"`
+
+	// webhookBreaker is the state of circuit breaker of webhook, it is set if the circuit breaker is
+	// configured in webhook mode.
+	// +optional
+	WebhookBreaker *WebhookBreakerStatus `json:"webhookBreaker,omitempty" protobuf:"bytes,12,opt,name=webhookBreaker"`
+
+	// webhookCache is the answer of webhook reused until it expires, it is set if the webhook answers
+	// with ttlSeconds.
+	// +optional
+	WebhookCache *WebhookCachedResponse `json:"webhookCache,omitempty" protobuf:"bytes,13,opt,name=webhookCache"`
+
+	// scalingHistory is the recent recommendations and scale events used by the stabilization windows and
+	// the scaling policies, the controller restores them after restart or leader failover.
+	// +optional
+	ScalingHistory *ScalingHistory `json:"scalingHistory,omitempty" protobuf:"bytes,14,opt,name=scalingHistory"`
+
+	// dryRun is the last decision to rescale made in dry run, it is set if dryRun is enabled in spec.
+	// +optional
+	DryRun *DryRunDecision `json:"dryRun,omitempty" protobuf:"bytes,15,opt,name=dryRun"`
+
+	// idleSince is the time since which the signals recommend zero replicas, it is set only if minReplicas
+	// is 0.
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty" protobuf:"bytes,16,opt,name=idleSince"`
+}
+
+// DryRunDecision is a rescale decided but not applied in dry run
+type DryRunDecision struct {
+	// currentReplicas is the replicas of target when the decision is made
+	CurrentReplicas int32 `json:"currentReplicas" protobuf:"varint,1,name=currentReplicas"`
+	// desiredReplicas is the replicas the target would be scaled to
+	DesiredReplicas int32 `json:"desiredReplicas" protobuf:"varint,2,name=desiredReplicas"`
+	// reason is the reason of rescale
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,3,opt,name=reason"`
+	// decisionTime is the time the decision is first made, it is kept while the decision is unchanged
+	DecisionTime metav1.Time `json:"decisionTime" protobuf:"bytes,4,name=decisionTime"`
+}
+
+// ScalingHistory is the recent recommendations and scale events of GPA, the ones out of the longest
+// stabilization window and the longest policy period are not kept
+type ScalingHistory struct {
+	// recommendations are the replicas recommended before the stabilization, the timestamps of unchanged
+	// recommendations are refreshed at most once a minute, so they may be up to a minute older than the
+	// last recommendation in the controller
+	// +optional
+	Recommendations []TimestampedReplicas `json:"recommendations,omitempty" protobuf:"bytes,1,rep,name=recommendations"`
+	// scaleUpEvents are the replicas added by the scale up
+	// +optional
+	ScaleUpEvents []TimestampedReplicas `json:"scaleUpEvents,omitempty" protobuf:"bytes,2,rep,name=scaleUpEvents"`
+	// scaleDownEvents are the replicas removed by the scale down
+	// +optional
+	ScaleDownEvents []TimestampedReplicas `json:"scaleDownEvents,omitempty" protobuf:"bytes,3,rep,name=scaleDownEvents"`
+}
+
+// TimestampedReplicas is a number of replicas at a time
+type TimestampedReplicas struct {
+	Replicas  int32       `json:"replicas" protobuf:"varint,1,name=replicas"`
+	Timestamp metav1.Time `json:"timestamp" protobuf:"bytes,2,name=timestamp"`
+}
+
+// WebhookCachedResponse is the answer of webhook reused until it expires
+type WebhookCachedResponse struct {
+	// scale is false if the webhook answers not to scale, the current replicas is kept then
+	// +optional
+	Scale bool `json:"scale,omitempty" protobuf:"varint,1,opt,name=scale"`
+	// replicas is the replicas answered by the webhook
+	// +optional
+	Replicas int32 `json:"replicas,omitempty" protobuf:"varint,2,opt,name=replicas"`
+	// receivedTime is the time the answer was received
+	ReceivedTime metav1.Time `json:"receivedTime" protobuf:"bytes,3,name=receivedTime"`
+	// expirationTime is the time the answer expires, the webhook is called again after it
+	ExpirationTime metav1.Time `json:"expirationTime" protobuf:"bytes,4,name=expirationTime"`
+	// minReplicas is the lower limit of replicas answered by the webhook
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty" protobuf:"varint,5,opt,name=minReplicas"`
+	// maxReplicas is the upper limit of replicas answered by the webhook
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty" protobuf:"varint,6,opt,name=maxReplicas"`
+	// configHash is the hash of the webhook mode answering, the answer is dropped if the webhook mode is changed
+	// +optional
+	ConfigHash string `json:"configHash,omitempty" protobuf:"bytes,7,opt,name=configHash"`
+}
+
+// WebhookBreakerState is the state of circuit breaker of webhook
+type WebhookBreakerState string
+
+const (
+	// BreakerClosed means the webhook is called
+	BreakerClosed WebhookBreakerState = "Closed"
+	// BreakerOpen means the webhook is not called, the replicas is recommended by the fallback policy
+	BreakerOpen WebhookBreakerState = "Open"
+)
+
+// WebhookBreakerStatus is the state of circuit breaker of webhook
+type WebhookBreakerStatus struct {
+	// state is Closed or Open
+	State WebhookBreakerState `json:"state" protobuf:"bytes,1,name=state,casttype=WebhookBreakerState"`
+	// consecutiveFailures is the number of consecutive failed calls
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty" protobuf:"varint,2,opt,name=consecutiveFailures"`
+	// lastTransitionTime is the last time the state changed, or the breaker is opened again after a failed try
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,3,opt,name=lastTransitionTime"`
+	// lastError is the error of the last failed call
+	// +optional
+	LastError string `json:"lastError,omitempty" protobuf:"bytes,4,opt,name=lastError"`
+	// lastGoodReplicas is the replicas of the last successful response
+	// +optional
+	LastGoodReplicas *int32 `json:"lastGoodReplicas,omitempty" protobuf:"varint,5,opt,name=lastGoodReplicas"`
+	// lastGoodTime is the time of the last successful response
+	// +optional
+	LastGoodTime *metav1.Time `json:"lastGoodTime,omitempty" protobuf:"bytes,6,opt,name=lastGoodTime"`
+}
+
+// CronScheduleStatus is the schedule of cron metric mode in force
+type CronScheduleStatus struct {
+	// schedule is the name of the schedule in force, `default` if no schedule is active
+	Schedule string `json:"schedule" protobuf:"bytes,1,name=schedule"`
+	// priority is the priority of the schedule
+	// +optional
+	Priority int `json:"priority,omitempty" protobuf:"varint,2,opt,name=priority"`
+	// minReplicas is the lower limit of replicas in the schedule
+	MinReplicas int32 `json:"minReplicas" protobuf:"varint,3,name=minReplicas"`
+	// maxReplicas is the upper limit of replicas in the schedule
+	MaxReplicas int32 `json:"maxReplicas" protobuf:"varint,4,name=maxReplicas"`
+	// activeSince is the time the schedule became active
+	ActiveSince metav1.Time `json:"activeSince" protobuf:"bytes,5,name=activeSince"`
+	// next is the next transition of schedule in a week, nil if the schedule keeps in force
+	// +optional
+	Next *CronScheduleTransition `json:"next,omitempty" protobuf:"bytes,6,opt,name=next"`
+}
+
+// CronScheduleTransition is a change of the schedule in force
+type CronScheduleTransition struct {
+	// schedule is the name of the schedule in force after the transition
+	Schedule string `json:"schedule" protobuf:"bytes,1,name=schedule"`
+	// time is the time of the transition
+	Time metav1.Time `json:"time" protobuf:"bytes,2,name=time"`
+	// minReplicas is the lower limit of replicas after the transition
+	MinReplicas int32 `json:"minReplicas" protobuf:"varint,3,name=minReplicas"`
+	// maxReplicas is the upper limit of replicas after the transition
+	MaxReplicas int32 `json:"maxReplicas" protobuf:"varint,4,name=maxReplicas"`
+}
+
+// Recommendation is the replicas recommended by a source
+type Recommendation struct {
+	// name identifies the source, e.g. `metric(Resource cpu)`, `time(* 10-23 * * *)`, `webhook`
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+	// replicas is the number of replicas recommended by the source
+	Replicas int32 `json:"replicas" protobuf:"varint,2,name=replicas"`
+	// timestamp is the last time the source recommended successfully, it is refreshed at most once a
+	// minute while the replicas are unchanged
+	// +optional
+	Timestamp metav1.Time `json:"timestamp,omitempty" protobuf:"bytes,3,opt,name=timestamp"`
+	// lastError is the error of the last recommending, empty if it succeeded
+	// +optional
+	LastError string `json:"lastError,omitempty" protobuf:"bytes,4,opt,name=lastError"`
+	// decisive is true if the recommendation decided the proposal of its mode by the metric aggregation,
+	// the recommendations averaged are all decisive.
+	// +optional
+	Decisive bool `json:"decisive,omitempty" protobuf:"varint,5,opt,name=decisive"`
+}
+
+// ModeProposal is the replicas proposed by a driven mode
+type ModeProposal struct {
+	// mode is the name of the driven mode
+	Mode string `json:"mode" protobuf:"bytes,1,name=mode"`
+	// replicas is the number of replicas proposed by the mode
+	Replicas int32 `json:"replicas" protobuf:"varint,2,name=replicas"`
+	// metric describes what the proposal is calculated from
+	// +optional
+	Metric string `json:"metric,omitempty" protobuf:"bytes,3,opt,name=metric"`
+	// selected is true if the proposal is selected by the mode select policy
+	// +optional
+	Selected bool `json:"selected,omitempty" protobuf:"varint,4,opt,name=selected"`
 }
 
 // GeneralPodAutoscalerConditionType are the valid conditions of
@@ -503,6 +953,14 @@ const (
 	// ScalingLimited indicates that the calculated scale based on metrics would be above or
 	// below the range for the GPA, and has thus been capped.
 	ScalingLimited GeneralPodAutoscalerConditionType = "ScalingLimited"
+	// WebhookResponseValid indicates whether the last response of webhook is accepted, the reason
+	// is the one given by the server if it rejected the request.
+	WebhookResponseValid GeneralPodAutoscalerConditionType = "WebhookResponseValid"
+	// WebhookResponseStale indicates whether the cached answer of webhook is used after it expired,
+	// because it could not be refreshed.
+	WebhookResponseStale GeneralPodAutoscalerConditionType = "WebhookResponseStale"
+	// ScalingOverridden indicates whether the autoscaling is paused or held by the override in spec.
+	ScalingOverridden GeneralPodAutoscalerConditionType = "ScalingOverridden"
 )
 
 // GeneralPodAutoscalerCondition describes the state of
@@ -645,3 +1103,55 @@ type GeneralPodAutoscalerList struct {
 	// items is the list of general pod autoscaler objects.
 	Items []GeneralPodAutoscaler `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HolidayCalendar is a cluster scoped set of named date ranges, referenced by the
+// `calendar` of CronMetricSpec.
+type HolidayCalendar struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard object metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// spec is the holidays of the calendar.
+	// +optional
+	Spec HolidayCalendarSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// HolidayCalendarSpec describes the holidays of a HolidayCalendar.
+type HolidayCalendarSpec struct {
+	// Holidays are the named date ranges of the calendar.
+	Holidays []Holiday `json:"holidays,omitempty" protobuf:"bytes,1,rep,name=holidays"`
+}
+
+// Holiday is a named date range, the dates are in the time zone of the schedule referencing the calendar.
+type Holiday struct {
+	// Name of the holiday, e.g. `national-day`.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// Start is the first day of the holiday, in `2006-01-02` format.
+	Start string `json:"start" protobuf:"bytes,2,opt,name=start"`
+
+	// End is the last day of the holiday, in `2006-01-02` format. Defaults to Start.
+	// +optional
+	End string `json:"end,omitempty" protobuf:"bytes,3,opt,name=end"`
+}
+
+// HolidayDateFormat is the format of the start and end of Holiday.
+const HolidayDateFormat = "2006-01-02"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HolidayCalendarList is a list of holiday calendar objects.
+type HolidayCalendarList struct {
+	metav1.TypeMeta `json:",inline"`
+	// metadata is the standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// items is the list of holiday calendar objects.
+	Items []HolidayCalendar `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
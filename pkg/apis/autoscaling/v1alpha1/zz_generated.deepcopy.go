@@ -34,6 +34,11 @@ func (in *AutoScalingDrivenMode) DeepCopyInto(out *AutoScalingDrivenMode) {
 		*out = new(MetricMode)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CronMetricMode != nil {
+		in, out := &in.CronMetricMode, &out.CronMetricMode
+		*out = new(CronMetricMode)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.WebhookMode != nil {
 		in, out := &in.WebhookMode, &out.WebhookMode
 		*out = new(WebhookMode)
@@ -96,6 +101,95 @@ func (in *ContainerResourceMetricStatus) DeepCopy() *ContainerResourceMetricStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMetricMode) DeepCopyInto(out *CronMetricMode) {
+	*out = *in
+	if in.CronMetrics != nil {
+		in, out := &in.CronMetrics, &out.CronMetrics
+		*out = make([]CronMetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMetricMode.
+func (in *CronMetricMode) DeepCopy() *CronMetricMode {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMetricMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMetricSpec) DeepCopyInto(out *CronMetricSpec) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.MetricSpec.DeepCopyInto(&out.MetricSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMetricSpec.
+func (in *CronMetricSpec) DeepCopy() *CronMetricSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMetricSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronScheduleStatus) DeepCopyInto(out *CronScheduleStatus) {
+	*out = *in
+	in.ActiveSince.DeepCopyInto(&out.ActiveSince)
+	if in.Next != nil {
+		in, out := &in.Next, &out.Next
+		*out = new(CronScheduleTransition)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronScheduleStatus.
+func (in *CronScheduleStatus) DeepCopy() *CronScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CronScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronScheduleTransition) DeepCopyInto(out *CronScheduleTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronScheduleTransition.
+func (in *CronScheduleTransition) DeepCopy() *CronScheduleTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(CronScheduleTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossVersionObjectReference) DeepCopyInto(out *CrossVersionObjectReference) {
 	*out = *in
@@ -112,6 +206,23 @@ func (in *CrossVersionObjectReference) DeepCopy() *CrossVersionObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunDecision) DeepCopyInto(out *DryRunDecision) {
+	*out = *in
+	in.DecisionTime.DeepCopyInto(&out.DecisionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunDecision.
+func (in *DryRunDecision) DeepCopy() *DryRunDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EventMode) DeepCopyInto(out *EventMode) {
 	*out = *in
@@ -337,6 +448,26 @@ func (in *GeneralPodAutoscalerSpec) DeepCopyInto(out *GeneralPodAutoscalerSpec)
 		*out = new(GeneralPodAutoscalerBehavior)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ModeSelectPolicy != nil {
+		in, out := &in.ModeSelectPolicy, &out.ModeSelectPolicy
+		*out = new(ModeSelectPolicy)
+		**out = **in
+	}
+	if in.Override != nil {
+		in, out := &in.Override, &out.Override
+		*out = new(ScaleOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Idle != nil {
+		in, out := &in.Idle, &out.Idle
+		*out = new(IdleScaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricAggregation != nil {
+		in, out := &in.MetricAggregation, &out.MetricAggregation
+		*out = new(MetricAggregation)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -380,6 +511,47 @@ func (in *GeneralPodAutoscalerStatus) DeepCopyInto(out *GeneralPodAutoscalerStat
 		in, out := &in.LastCronScheduleTime, &out.LastCronScheduleTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ModeProposals != nil {
+		in, out := &in.ModeProposals, &out.ModeProposals
+		*out = make([]ModeProposal, len(*in))
+		copy(*out, *in)
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]Recommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CronSchedule != nil {
+		in, out := &in.CronSchedule, &out.CronSchedule
+		*out = new(CronScheduleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WebhookBreaker != nil {
+		in, out := &in.WebhookBreaker, &out.WebhookBreaker
+		*out = new(WebhookBreakerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WebhookCache != nil {
+		in, out := &in.WebhookCache, &out.WebhookCache
+		*out = new(WebhookCachedResponse)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScalingHistory != nil {
+		in, out := &in.ScalingHistory, &out.ScalingHistory
+		*out = new(ScalingHistory)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunDecision)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -393,6 +565,145 @@ func (in *GeneralPodAutoscalerStatus) DeepCopy() *GeneralPodAutoscalerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Holiday) DeepCopyInto(out *Holiday) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Holiday.
+func (in *Holiday) DeepCopy() *Holiday {
+	if in == nil {
+		return nil
+	}
+	out := new(Holiday)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HolidayCalendar) DeepCopyInto(out *HolidayCalendar) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HolidayCalendar.
+func (in *HolidayCalendar) DeepCopy() *HolidayCalendar {
+	if in == nil {
+		return nil
+	}
+	out := new(HolidayCalendar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HolidayCalendar) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HolidayCalendarList) DeepCopyInto(out *HolidayCalendarList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HolidayCalendar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HolidayCalendarList.
+func (in *HolidayCalendarList) DeepCopy() *HolidayCalendarList {
+	if in == nil {
+		return nil
+	}
+	out := new(HolidayCalendarList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HolidayCalendarList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HolidayCalendarSpec) DeepCopyInto(out *HolidayCalendarSpec) {
+	*out = *in
+	if in.Holidays != nil {
+		in, out := &in.Holidays, &out.Holidays
+		*out = make([]Holiday, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HolidayCalendarSpec.
+func (in *HolidayCalendarSpec) DeepCopy() *HolidayCalendarSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HolidayCalendarSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdleScaling) DeepCopyInto(out *IdleScaling) {
+	*out = *in
+	if in.ActivationReplicas != nil {
+		in, out := &in.ActivationReplicas, &out.ActivationReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdleScaling.
+func (in *IdleScaling) DeepCopy() *IdleScaling {
+	if in == nil {
+		return nil
+	}
+	out := new(IdleScaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricAggregation) DeepCopyInto(out *MetricAggregation) {
+	*out = *in
+	if in.Quorum != nil {
+		in, out := &in.Quorum, &out.Quorum
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricAggregation.
+func (in *MetricAggregation) DeepCopy() *MetricAggregation {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricAggregation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricIdentifier) DeepCopyInto(out *MetricIdentifier) {
 	*out = *in
@@ -465,6 +776,11 @@ func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
 		*out = new(ContainerResourceMetricSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -581,6 +897,22 @@ func (in *MetricValueStatus) DeepCopy() *MetricValueStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModeProposal) DeepCopyInto(out *ModeProposal) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModeProposal.
+func (in *ModeProposal) DeepCopy() *ModeProposal {
+	if in == nil {
+		return nil
+	}
+	out := new(ModeProposal)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectMetricSource) DeepCopyInto(out *ObjectMetricSource) {
 	*out = *in
@@ -655,6 +987,23 @@ func (in *PodsMetricStatus) DeepCopy() *PodsMetricStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Recommendation) DeepCopyInto(out *Recommendation) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Recommendation.
+func (in *Recommendation) DeepCopy() *Recommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(Recommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceMetricSource) DeepCopyInto(out *ResourceMetricSource) {
 	*out = *in
@@ -689,6 +1038,31 @@ func (in *ResourceMetricStatus) DeepCopy() *ResourceMetricStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleOverride) DeepCopyInto(out *ScaleOverride) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleOverride.
+func (in *ScaleOverride) DeepCopy() *ScaleOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaleTriggers) DeepCopyInto(out *ScaleTriggers) {
 	*out = *in
@@ -712,13 +1086,52 @@ func (in *ScaleTriggers) DeepCopy() *ScaleTriggers {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingHistory) DeepCopyInto(out *ScalingHistory) {
+	*out = *in
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]TimestampedReplicas, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScaleUpEvents != nil {
+		in, out := &in.ScaleUpEvents, &out.ScaleUpEvents
+		*out = make([]TimestampedReplicas, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScaleDownEvents != nil {
+		in, out := &in.ScaleDownEvents, &out.ScaleDownEvents
+		*out = make([]TimestampedReplicas, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingHistory.
+func (in *ScalingHistory) DeepCopy() *ScalingHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimeMode) DeepCopyInto(out *TimeMode) {
 	*out = *in
 	if in.TimeRanges != nil {
 		in, out := &in.TimeRanges, &out.TimeRanges
 		*out = make([]TimeRange, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -736,6 +1149,11 @@ func (in *TimeMode) DeepCopy() *TimeMode {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimeRange) DeepCopyInto(out *TimeRange) {
 	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -749,6 +1167,145 @@ func (in *TimeRange) DeepCopy() *TimeRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimestampedReplicas) DeepCopyInto(out *TimestampedReplicas) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimestampedReplicas.
+func (in *TimestampedReplicas) DeepCopy() *TimestampedReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(TimestampedReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookAuthentication) DeepCopyInto(out *WebhookAuthentication) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookAuthentication.
+func (in *WebhookAuthentication) DeepCopy() *WebhookAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookAuthentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookBatch) DeepCopyInto(out *WebhookBatch) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookBatch.
+func (in *WebhookBatch) DeepCopy() *WebhookBatch {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookBatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookBreakerStatus) DeepCopyInto(out *WebhookBreakerStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.LastGoodReplicas != nil {
+		in, out := &in.LastGoodReplicas, &out.LastGoodReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastGoodTime != nil {
+		in, out := &in.LastGoodTime, &out.LastGoodTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookBreakerStatus.
+func (in *WebhookBreakerStatus) DeepCopy() *WebhookBreakerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookBreakerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookCachedResponse) DeepCopyInto(out *WebhookCachedResponse) {
+	*out = *in
+	in.ReceivedTime.DeepCopyInto(&out.ReceivedTime)
+	in.ExpirationTime.DeepCopyInto(&out.ExpirationTime)
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookCachedResponse.
+func (in *WebhookCachedResponse) DeepCopy() *WebhookCachedResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookCachedResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookCircuitBreaker) DeepCopyInto(out *WebhookCircuitBreaker) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OpenDuration != nil {
+		in, out := &in.OpenDuration, &out.OpenDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookCircuitBreaker.
+func (in *WebhookCircuitBreaker) DeepCopy() *WebhookCircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookCircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookMode) DeepCopyInto(out *WebhookMode) {
 	*out = *in
@@ -764,6 +1321,31 @@ func (in *WebhookMode) DeepCopyInto(out *WebhookMode) {
 			(*out)[key] = val
 		}
 	}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(WebhookAuthentication)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(WebhookRetry)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(WebhookCircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Batch != nil {
+		in, out := &in.Batch, &out.Batch
+		*out = new(WebhookBatch)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -776,3 +1358,34 @@ func (in *WebhookMode) DeepCopy() *WebhookMode {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookRetry) DeepCopyInto(out *WebhookRetry) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookRetry.
+func (in *WebhookRetry) DeepCopy() *WebhookRetry {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookRetry)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -27,6 +27,7 @@ import (
 type AutoscalingV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	GeneralPodAutoscalersGetter
+	HolidayCalendarsGetter
 }
 
 // AutoscalingV1alpha1Client is used to interact with features provided by the autoscaling.ocgi.dev group.
@@ -38,6 +39,10 @@ func (c *AutoscalingV1alpha1Client) GeneralPodAutoscalers(namespace string) Gene
 	return newGeneralPodAutoscalers(c, namespace)
 }
 
+func (c *AutoscalingV1alpha1Client) HolidayCalendars() HolidayCalendarInterface {
+	return newHolidayCalendars(c)
+}
+
 // NewForConfig creates a new AutoscalingV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*AutoscalingV1alpha1Client, error) {
 	config := *c
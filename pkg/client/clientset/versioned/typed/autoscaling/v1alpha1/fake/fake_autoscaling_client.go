@@ -32,6 +32,10 @@ func (c *FakeAutoscalingV1alpha1) GeneralPodAutoscalers(namespace string) v1alph
 	return &FakeGeneralPodAutoscalers{c, namespace}
 }
 
+func (c *FakeAutoscalingV1alpha1) HolidayCalendars() v1alpha1.HolidayCalendarInterface {
+	return &FakeHolidayCalendars{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeAutoscalingV1alpha1) RESTClient() rest.Interface {
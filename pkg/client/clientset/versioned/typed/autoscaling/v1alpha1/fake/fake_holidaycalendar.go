@@ -0,0 +1,120 @@
+/*
+Copyright 2019 THL A29 Limited, a Tencent company.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeHolidayCalendars implements HolidayCalendarInterface
+type FakeHolidayCalendars struct {
+	Fake *FakeAutoscalingV1alpha1
+}
+
+var holidaycalendarsResource = schema.GroupVersionResource{Group: "autoscaling.ocgi.dev", Version: "v1alpha1", Resource: "holidaycalendars"}
+
+var holidaycalendarsKind = schema.GroupVersionKind{Group: "autoscaling.ocgi.dev", Version: "v1alpha1", Kind: "HolidayCalendar"}
+
+// Get takes name of the holidayCalendar, and returns the corresponding holidayCalendar object, and an error if there is any.
+func (c *FakeHolidayCalendars) Get(name string, options v1.GetOptions) (result *v1alpha1.HolidayCalendar, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(holidaycalendarsResource, name), &v1alpha1.HolidayCalendar{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HolidayCalendar), err
+}
+
+// List takes label and field selectors, and returns the list of HolidayCalendars that match those selectors.
+func (c *FakeHolidayCalendars) List(opts v1.ListOptions) (result *v1alpha1.HolidayCalendarList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(holidaycalendarsResource, holidaycalendarsKind, opts), &v1alpha1.HolidayCalendarList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.HolidayCalendarList{ListMeta: obj.(*v1alpha1.HolidayCalendarList).ListMeta}
+	for _, item := range obj.(*v1alpha1.HolidayCalendarList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested holidayCalendars.
+func (c *FakeHolidayCalendars) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(holidaycalendarsResource, opts))
+}
+
+// Create takes the representation of a holidayCalendar and creates it.  Returns the server's representation of the holidayCalendar, and an error, if there is any.
+func (c *FakeHolidayCalendars) Create(holidayCalendar *v1alpha1.HolidayCalendar) (result *v1alpha1.HolidayCalendar, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(holidaycalendarsResource, holidayCalendar), &v1alpha1.HolidayCalendar{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HolidayCalendar), err
+}
+
+// Update takes the representation of a holidayCalendar and updates it. Returns the server's representation of the holidayCalendar, and an error, if there is any.
+func (c *FakeHolidayCalendars) Update(holidayCalendar *v1alpha1.HolidayCalendar) (result *v1alpha1.HolidayCalendar, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(holidaycalendarsResource, holidayCalendar), &v1alpha1.HolidayCalendar{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HolidayCalendar), err
+}
+
+// Delete takes name of the holidayCalendar and deletes it. Returns an error if one occurs.
+func (c *FakeHolidayCalendars) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(holidaycalendarsResource, name), &v1alpha1.HolidayCalendar{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeHolidayCalendars) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(holidaycalendarsResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.HolidayCalendarList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched holidayCalendar.
+func (c *FakeHolidayCalendars) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.HolidayCalendar, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(holidaycalendarsResource, name, pt, data, subresources...), &v1alpha1.HolidayCalendar{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HolidayCalendar), err
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2019 THL A29 Limited, a Tencent company.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	scheme "github.com/ocgi/general-pod-autoscaler/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// HolidayCalendarsGetter has a method to return a HolidayCalendarInterface.
+// A group's client should implement this interface.
+type HolidayCalendarsGetter interface {
+	HolidayCalendars() HolidayCalendarInterface
+}
+
+// HolidayCalendarInterface has methods to work with HolidayCalendar resources.
+type HolidayCalendarInterface interface {
+	Create(*v1alpha1.HolidayCalendar) (*v1alpha1.HolidayCalendar, error)
+	Update(*v1alpha1.HolidayCalendar) (*v1alpha1.HolidayCalendar, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.HolidayCalendar, error)
+	List(opts v1.ListOptions) (*v1alpha1.HolidayCalendarList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.HolidayCalendar, err error)
+	HolidayCalendarExpansion
+}
+
+// holidayCalendars implements HolidayCalendarInterface
+type holidayCalendars struct {
+	client rest.Interface
+}
+
+// newHolidayCalendars returns a HolidayCalendars
+func newHolidayCalendars(c *AutoscalingV1alpha1Client) *holidayCalendars {
+	return &holidayCalendars{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the holidayCalendar, and returns the corresponding holidayCalendar object, and an error if there is any.
+func (c *holidayCalendars) Get(name string, options v1.GetOptions) (result *v1alpha1.HolidayCalendar, err error) {
+	result = &v1alpha1.HolidayCalendar{}
+	err = c.client.Get().
+		Resource("holidaycalendars").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of HolidayCalendars that match those selectors.
+func (c *holidayCalendars) List(opts v1.ListOptions) (result *v1alpha1.HolidayCalendarList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.HolidayCalendarList{}
+	err = c.client.Get().
+		Resource("holidaycalendars").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested holidayCalendars.
+func (c *holidayCalendars) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("holidaycalendars").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a holidayCalendar and creates it.  Returns the server's representation of the holidayCalendar, and an error, if there is any.
+func (c *holidayCalendars) Create(holidayCalendar *v1alpha1.HolidayCalendar) (result *v1alpha1.HolidayCalendar, err error) {
+	result = &v1alpha1.HolidayCalendar{}
+	err = c.client.Post().
+		Resource("holidaycalendars").
+		Body(holidayCalendar).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a holidayCalendar and updates it. Returns the server's representation of the holidayCalendar, and an error, if there is any.
+func (c *holidayCalendars) Update(holidayCalendar *v1alpha1.HolidayCalendar) (result *v1alpha1.HolidayCalendar, err error) {
+	result = &v1alpha1.HolidayCalendar{}
+	err = c.client.Put().
+		Resource("holidaycalendars").
+		Name(holidayCalendar.Name).
+		Body(holidayCalendar).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the holidayCalendar and deletes it. Returns an error if one occurs.
+func (c *holidayCalendars) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("holidaycalendars").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *holidayCalendars) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("holidaycalendars").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched holidayCalendar.
+func (c *holidayCalendars) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.HolidayCalendar, err error) {
+	result = &v1alpha1.HolidayCalendar{}
+	err = c.client.Patch(pt).
+		Resource("holidaycalendars").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 THL A29 Limited, a Tencent company.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	time "time"
+
+	autoscalingv1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	versioned "github.com/ocgi/general-pod-autoscaler/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/ocgi/general-pod-autoscaler/pkg/client/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/client/listers/autoscaling/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// HolidayCalendarInformer provides access to a shared informer and lister for
+// HolidayCalendars.
+type HolidayCalendarInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.HolidayCalendarLister
+}
+
+type holidayCalendarInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewHolidayCalendarInformer constructs a new informer for HolidayCalendar type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewHolidayCalendarInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredHolidayCalendarInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredHolidayCalendarInformer constructs a new informer for HolidayCalendar type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredHolidayCalendarInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AutoscalingV1alpha1().HolidayCalendars().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AutoscalingV1alpha1().HolidayCalendars().Watch(options)
+			},
+		},
+		&autoscalingv1alpha1.HolidayCalendar{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *holidayCalendarInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredHolidayCalendarInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *holidayCalendarInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&autoscalingv1alpha1.HolidayCalendar{}, f.defaultInformer)
+}
+
+func (f *holidayCalendarInformer) Lister() v1alpha1.HolidayCalendarLister {
+	return v1alpha1.NewHolidayCalendarLister(f.Informer().GetIndexer())
+}
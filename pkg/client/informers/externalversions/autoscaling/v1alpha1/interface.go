@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// GeneralPodAutoscalers returns a GeneralPodAutoscalerInformer.
 	GeneralPodAutoscalers() GeneralPodAutoscalerInformer
+	// HolidayCalendars returns a HolidayCalendarInformer.
+	HolidayCalendars() HolidayCalendarInformer
 }
 
 type version struct {
@@ -43,3 +45,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) GeneralPodAutoscalers() GeneralPodAutoscalerInformer {
 	return &generalPodAutoscalerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// HolidayCalendars returns a HolidayCalendarInformer.
+func (v *version) HolidayCalendars() HolidayCalendarInformer {
+	return &holidayCalendarInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -55,6 +55,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=autoscaling.ocgi.dev, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("generalpodautoscalers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Autoscaling().V1alpha1().GeneralPodAutoscalers().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("holidaycalendars"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Autoscaling().V1alpha1().HolidayCalendars().Informer()}, nil
 
 	}
 
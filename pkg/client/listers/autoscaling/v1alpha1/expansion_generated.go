@@ -25,3 +25,7 @@ type GeneralPodAutoscalerListerExpansion interface{}
 // GeneralPodAutoscalerNamespaceListerExpansion allows custom methods to be added to
 // GeneralPodAutoscalerNamespaceLister.
 type GeneralPodAutoscalerNamespaceListerExpansion interface{}
+
+// HolidayCalendarListerExpansion allows custom methods to be added to
+// HolidayCalendarLister.
+type HolidayCalendarListerExpansion interface{}
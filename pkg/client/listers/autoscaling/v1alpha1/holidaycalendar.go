@@ -0,0 +1,65 @@
+/*
+Copyright 2019 THL A29 Limited, a Tencent company.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// HolidayCalendarLister helps list HolidayCalendars.
+type HolidayCalendarLister interface {
+	// List lists all HolidayCalendars in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.HolidayCalendar, err error)
+	// Get retrieves the HolidayCalendar from the index for a given name.
+	Get(name string) (*v1alpha1.HolidayCalendar, error)
+	HolidayCalendarListerExpansion
+}
+
+// holidayCalendarLister implements the HolidayCalendarLister interface.
+type holidayCalendarLister struct {
+	indexer cache.Indexer
+}
+
+// NewHolidayCalendarLister returns a new HolidayCalendarLister.
+func NewHolidayCalendarLister(indexer cache.Indexer) HolidayCalendarLister {
+	return &holidayCalendarLister{indexer: indexer}
+}
+
+// List lists all HolidayCalendars in the indexer.
+func (s *holidayCalendarLister) List(selector labels.Selector) (ret []*v1alpha1.HolidayCalendar, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.HolidayCalendar))
+	})
+	return ret, err
+}
+
+// Get retrieves the HolidayCalendar from the index for a given name.
+func (s *holidayCalendarLister) Get(name string) (*v1alpha1.HolidayCalendar, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("holidaycalendar"), name)
+	}
+	return obj.(*v1alpha1.HolidayCalendar), nil
+}
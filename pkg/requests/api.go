@@ -14,7 +14,22 @@
 
 package requests
 
-import "k8s.io/apimachinery/pkg/types"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+const (
+	// APIVersion is the version of AutoscaleReview sent by GPA, a server replies with the version it
+	// understands. A reply without version is treated as the same version.
+	APIVersion = "autoscaling.ocgi.dev/v1alpha1"
+	// Kind is the kind of AutoscaleReview
+	Kind = "AutoscaleReview"
+	// BatchKind is the kind of AutoscaleBatchReview
+	BatchKind = "AutoscaleBatchReview"
+)
 
 // AutoscaleRequest defines the request to webhook autoscaler endpoint
 type AutoscaleRequest struct {
@@ -28,6 +43,35 @@ type AutoscaleRequest struct {
 	Parameters map[string]string `json:"parameters"`
 	// CurrentReplicas is the current replicas
 	CurrentReplicas int32 `json:"currentReplicas"`
+	// MinReplicas is the lower limit of replicas, adjusted by the cron schedule in force
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit of replicas, adjusted by the cron schedule in force
+	// +optional
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// Target is the kind and apiVersion of the workload
+	// +optional
+	Target *TargetReference `json:"target,omitempty"`
+	// Selector is the label selector of pods of the workload
+	// +optional
+	Selector string `json:"selector,omitempty"`
+	// ReadyPods is the number of ready pods of the workload
+	// +optional
+	ReadyPods *int32 `json:"readyPods,omitempty"`
+	// TotalPods is the number of pods of the workload
+	// +optional
+	TotalPods *int32 `json:"totalPods,omitempty"`
+	// CurrentMetrics are the metrics last computed by the GPA
+	// +optional
+	CurrentMetrics []autoscalingv1.MetricStatus `json:"currentMetrics,omitempty"`
+}
+
+// TargetReference is the kind and apiVersion of the workload being scaled
+type TargetReference struct {
+	// Kind is the kind of the workload, e.g. Squad, StatefulSet
+	Kind string `json:"kind"`
+	// APIVersion is the apiVersion of the workload
+	APIVersion string `json:"apiVersion,omitempty"`
 }
 
 // AutoscaleResponse defines the response of webhook server
@@ -37,13 +81,39 @@ type AutoscaleResponse struct {
 	UID types.UID `json:"uid"`
 	// Set to false if should not do scaling
 	Scale bool `json:"scale"`
-	// Replicas is targeted replica count from the webhookServer
+	// Replicas is targeted replica count from the webhookServer, it should not be negative
 	Replicas int32 `json:"replicas"`
+	// Result is set by the server when the request is rejected, its code, reason and message are
+	// surfaced in the condition and event of GPA. A non-200 answer should carry it in the body.
+	// +optional
+	Result *metav1.Status `json:"result,omitempty"`
+	// TTLSeconds is how long the answer is reused before the webhook is called again, the webhook is
+	// called in every calculation if it is not set.
+	// +optional
+	TTLSeconds *int32 `json:"ttlSeconds,omitempty"`
+	// MinReplicas overrides the lower limit of replicas of GPA in the calculation, it is applied as the
+	// limits of cron metric mode. It must be positive unless minReplicas of GPA is 0.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas overrides the upper limit of replicas of GPA in the calculation.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
 }
 
 // AutoscaleReview is passed to the webhook with a populated Request value,
 // and then returned with a populated Response.
 type AutoscaleReview struct {
-	Request  *AutoscaleRequest  `json:"request"`
-	Response *AutoscaleResponse `json:"response"`
+	// TypeMeta is the version of review, see APIVersion and Kind
+	metav1.TypeMeta `json:",inline"`
+	Request         *AutoscaleRequest  `json:"request"`
+	Response        *AutoscaleResponse `json:"response"`
+}
+
+// AutoscaleBatchReview is passed to the webhook with the requests of GPAs sharing the webhook in batch mode,
+// and then returned with a response for each request, the responses are matched to requests by uid.
+type AutoscaleBatchReview struct {
+	// TypeMeta is the version of review, see APIVersion and BatchKind
+	metav1.TypeMeta `json:",inline"`
+	Requests        []AutoscaleRequest  `json:"requests"`
+	Responses       []AutoscaleResponse `json:"responses"`
 }
@@ -0,0 +1,587 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: autoscale.proto
+
+package autoscalepb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// AutoscaleReview is passed to the webhook with a populated request, and then returned with a
+// populated response.
+type AutoscaleReview struct {
+	// api_version is the version of review, autoscaling.ocgi.dev/v1alpha1
+	ApiVersion string `protobuf:"bytes,1,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	// kind is AutoscaleReview
+	Kind                 string             `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Request              *AutoscaleRequest  `protobuf:"bytes,3,opt,name=request,proto3" json:"request,omitempty"`
+	Response             *AutoscaleResponse `protobuf:"bytes,4,opt,name=response,proto3" json:"response,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *AutoscaleReview) Reset()         { *m = AutoscaleReview{} }
+func (m *AutoscaleReview) String() string { return proto.CompactTextString(m) }
+func (*AutoscaleReview) ProtoMessage()    {}
+func (*AutoscaleReview) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dc6a430c3808dc1b, []int{0}
+}
+
+func (m *AutoscaleReview) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutoscaleReview.Unmarshal(m, b)
+}
+func (m *AutoscaleReview) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutoscaleReview.Marshal(b, m, deterministic)
+}
+func (m *AutoscaleReview) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutoscaleReview.Merge(m, src)
+}
+func (m *AutoscaleReview) XXX_Size() int {
+	return xxx_messageInfo_AutoscaleReview.Size(m)
+}
+func (m *AutoscaleReview) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutoscaleReview.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutoscaleReview proto.InternalMessageInfo
+
+func (m *AutoscaleReview) GetApiVersion() string {
+	if m != nil {
+		return m.ApiVersion
+	}
+	return ""
+}
+
+func (m *AutoscaleReview) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *AutoscaleReview) GetRequest() *AutoscaleRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *AutoscaleReview) GetResponse() *AutoscaleResponse {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+// AutoscaleRequest defines the request to webhook autoscaler endpoint
+type AutoscaleRequest struct {
+	// uid is used for tracing the request and response.
+	Uid string `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	// name is the name of the workload(Squad, Statefulset...) being scaled
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// namespace is the workload namespace
+	Namespace string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// parameters are the parameter that required by webhook
+	Parameters map[string]string `protobuf:"bytes,4,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// current_replicas is the current replicas
+	CurrentReplicas int32 `protobuf:"varint,5,opt,name=current_replicas,json=currentReplicas,proto3" json:"current_replicas,omitempty"`
+	// min_replicas is the lower limit of replicas, adjusted by the cron schedule in force
+	MinReplicas *wrappers.Int32Value `protobuf:"bytes,6,opt,name=min_replicas,json=minReplicas,proto3" json:"min_replicas,omitempty"`
+	// max_replicas is the upper limit of replicas, adjusted by the cron schedule in force
+	MaxReplicas int32 `protobuf:"varint,7,opt,name=max_replicas,json=maxReplicas,proto3" json:"max_replicas,omitempty"`
+	// target is the kind and apiVersion of the workload
+	Target *TargetReference `protobuf:"bytes,8,opt,name=target,proto3" json:"target,omitempty"`
+	// selector is the label selector of pods of the workload
+	Selector string `protobuf:"bytes,9,opt,name=selector,proto3" json:"selector,omitempty"`
+	// ready_pods is the number of ready pods of the workload
+	ReadyPods *wrappers.Int32Value `protobuf:"bytes,10,opt,name=ready_pods,json=readyPods,proto3" json:"ready_pods,omitempty"`
+	// total_pods is the number of pods of the workload
+	TotalPods *wrappers.Int32Value `protobuf:"bytes,11,opt,name=total_pods,json=totalPods,proto3" json:"total_pods,omitempty"`
+	// current_metrics_json is the JSON encoded currentMetrics of the JSON protocol, the metrics last
+	// computed by the GPA
+	CurrentMetricsJson   []byte   `protobuf:"bytes,12,opt,name=current_metrics_json,json=currentMetricsJson,proto3" json:"current_metrics_json,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AutoscaleRequest) Reset()         { *m = AutoscaleRequest{} }
+func (m *AutoscaleRequest) String() string { return proto.CompactTextString(m) }
+func (*AutoscaleRequest) ProtoMessage()    {}
+func (*AutoscaleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dc6a430c3808dc1b, []int{1}
+}
+
+func (m *AutoscaleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutoscaleRequest.Unmarshal(m, b)
+}
+func (m *AutoscaleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutoscaleRequest.Marshal(b, m, deterministic)
+}
+func (m *AutoscaleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutoscaleRequest.Merge(m, src)
+}
+func (m *AutoscaleRequest) XXX_Size() int {
+	return xxx_messageInfo_AutoscaleRequest.Size(m)
+}
+func (m *AutoscaleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutoscaleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutoscaleRequest proto.InternalMessageInfo
+
+func (m *AutoscaleRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *AutoscaleRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *AutoscaleRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *AutoscaleRequest) GetParameters() map[string]string {
+	if m != nil {
+		return m.Parameters
+	}
+	return nil
+}
+
+func (m *AutoscaleRequest) GetCurrentReplicas() int32 {
+	if m != nil {
+		return m.CurrentReplicas
+	}
+	return 0
+}
+
+func (m *AutoscaleRequest) GetMinReplicas() *wrappers.Int32Value {
+	if m != nil {
+		return m.MinReplicas
+	}
+	return nil
+}
+
+func (m *AutoscaleRequest) GetMaxReplicas() int32 {
+	if m != nil {
+		return m.MaxReplicas
+	}
+	return 0
+}
+
+func (m *AutoscaleRequest) GetTarget() *TargetReference {
+	if m != nil {
+		return m.Target
+	}
+	return nil
+}
+
+func (m *AutoscaleRequest) GetSelector() string {
+	if m != nil {
+		return m.Selector
+	}
+	return ""
+}
+
+func (m *AutoscaleRequest) GetReadyPods() *wrappers.Int32Value {
+	if m != nil {
+		return m.ReadyPods
+	}
+	return nil
+}
+
+func (m *AutoscaleRequest) GetTotalPods() *wrappers.Int32Value {
+	if m != nil {
+		return m.TotalPods
+	}
+	return nil
+}
+
+func (m *AutoscaleRequest) GetCurrentMetricsJson() []byte {
+	if m != nil {
+		return m.CurrentMetricsJson
+	}
+	return nil
+}
+
+// TargetReference is the kind and apiVersion of the workload being scaled
+type TargetReference struct {
+	// kind is the kind of the workload, e.g. Squad, StatefulSet
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// api_version is the apiVersion of the workload
+	ApiVersion           string   `protobuf:"bytes,2,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TargetReference) Reset()         { *m = TargetReference{} }
+func (m *TargetReference) String() string { return proto.CompactTextString(m) }
+func (*TargetReference) ProtoMessage()    {}
+func (*TargetReference) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dc6a430c3808dc1b, []int{2}
+}
+
+func (m *TargetReference) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TargetReference.Unmarshal(m, b)
+}
+func (m *TargetReference) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TargetReference.Marshal(b, m, deterministic)
+}
+func (m *TargetReference) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TargetReference.Merge(m, src)
+}
+func (m *TargetReference) XXX_Size() int {
+	return xxx_messageInfo_TargetReference.Size(m)
+}
+func (m *TargetReference) XXX_DiscardUnknown() {
+	xxx_messageInfo_TargetReference.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TargetReference proto.InternalMessageInfo
+
+func (m *TargetReference) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *TargetReference) GetApiVersion() string {
+	if m != nil {
+		return m.ApiVersion
+	}
+	return ""
+}
+
+// AutoscaleResponse defines the response of webhook server
+type AutoscaleResponse struct {
+	// uid is used for tracing the request and response, it should be same as it in the request.
+	Uid string `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	// scale is set to false if should not do scaling
+	Scale bool `protobuf:"varint,2,opt,name=scale,proto3" json:"scale,omitempty"`
+	// replicas is targeted replica count from the webhook server, it should not be negative
+	Replicas int32 `protobuf:"varint,3,opt,name=replicas,proto3" json:"replicas,omitempty"`
+	// result is set by the server when the request is rejected
+	Result *Status `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	// ttl_seconds is how long the answer is reused before the webhook is called again
+	TtlSeconds *wrappers.Int32Value `protobuf:"bytes,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// min_replicas overrides the lower limit of replicas of GPA in the calculation
+	MinReplicas *wrappers.Int32Value `protobuf:"bytes,6,opt,name=min_replicas,json=minReplicas,proto3" json:"min_replicas,omitempty"`
+	// max_replicas overrides the upper limit of replicas of GPA in the calculation
+	MaxReplicas          *wrappers.Int32Value `protobuf:"bytes,7,opt,name=max_replicas,json=maxReplicas,proto3" json:"max_replicas,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AutoscaleResponse) Reset()         { *m = AutoscaleResponse{} }
+func (m *AutoscaleResponse) String() string { return proto.CompactTextString(m) }
+func (*AutoscaleResponse) ProtoMessage()    {}
+func (*AutoscaleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dc6a430c3808dc1b, []int{3}
+}
+
+func (m *AutoscaleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AutoscaleResponse.Unmarshal(m, b)
+}
+func (m *AutoscaleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AutoscaleResponse.Marshal(b, m, deterministic)
+}
+func (m *AutoscaleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutoscaleResponse.Merge(m, src)
+}
+func (m *AutoscaleResponse) XXX_Size() int {
+	return xxx_messageInfo_AutoscaleResponse.Size(m)
+}
+func (m *AutoscaleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutoscaleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutoscaleResponse proto.InternalMessageInfo
+
+func (m *AutoscaleResponse) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *AutoscaleResponse) GetScale() bool {
+	if m != nil {
+		return m.Scale
+	}
+	return false
+}
+
+func (m *AutoscaleResponse) GetReplicas() int32 {
+	if m != nil {
+		return m.Replicas
+	}
+	return 0
+}
+
+func (m *AutoscaleResponse) GetResult() *Status {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *AutoscaleResponse) GetTtlSeconds() *wrappers.Int32Value {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return nil
+}
+
+func (m *AutoscaleResponse) GetMinReplicas() *wrappers.Int32Value {
+	if m != nil {
+		return m.MinReplicas
+	}
+	return nil
+}
+
+func (m *AutoscaleResponse) GetMaxReplicas() *wrappers.Int32Value {
+	if m != nil {
+		return m.MaxReplicas
+	}
+	return nil
+}
+
+// Status is the result of a rejected request, the same as the Status of kubernetes api
+type Status struct {
+	// status is Success or Failure
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// message is the human readable description of rejection
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// reason is the machine readable reason of rejection, in CamelCase
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// code is the suggested HTTP status code of rejection
+	Code                 int32    `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Status) Reset()         { *m = Status{} }
+func (m *Status) String() string { return proto.CompactTextString(m) }
+func (*Status) ProtoMessage()    {}
+func (*Status) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dc6a430c3808dc1b, []int{4}
+}
+
+func (m *Status) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Status.Unmarshal(m, b)
+}
+func (m *Status) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Status.Marshal(b, m, deterministic)
+}
+func (m *Status) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Status.Merge(m, src)
+}
+func (m *Status) XXX_Size() int {
+	return xxx_messageInfo_Status.Size(m)
+}
+func (m *Status) XXX_DiscardUnknown() {
+	xxx_messageInfo_Status.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Status proto.InternalMessageInfo
+
+func (m *Status) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Status) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Status) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Status) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*AutoscaleReview)(nil), "ocgi.autoscaling.v1alpha1.AutoscaleReview")
+	proto.RegisterType((*AutoscaleRequest)(nil), "ocgi.autoscaling.v1alpha1.AutoscaleRequest")
+	proto.RegisterMapType((map[string]string)(nil), "ocgi.autoscaling.v1alpha1.AutoscaleRequest.ParametersEntry")
+	proto.RegisterType((*TargetReference)(nil), "ocgi.autoscaling.v1alpha1.TargetReference")
+	proto.RegisterType((*AutoscaleResponse)(nil), "ocgi.autoscaling.v1alpha1.AutoscaleResponse")
+	proto.RegisterType((*Status)(nil), "ocgi.autoscaling.v1alpha1.Status")
+}
+
+func init() { proto.RegisterFile("autoscale.proto", fileDescriptor_dc6a430c3808dc1b) }
+
+var fileDescriptor_dc6a430c3808dc1b = []byte{
+	// 686 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0x4d, 0x6f, 0xd3, 0x4c,
+	0x10, 0x96, 0x93, 0x26, 0x4d, 0x26, 0x91, 0xd2, 0x77, 0x55, 0xbd, 0x32, 0x01, 0x41, 0x9a, 0x53,
+	0x28, 0xd4, 0xa1, 0xe9, 0x05, 0x0a, 0x14, 0xb5, 0x52, 0x11, 0x20, 0x21, 0x55, 0x5b, 0xd4, 0x03,
+	0x1c, 0xa2, 0x8d, 0x3d, 0x75, 0x4d, 0xec, 0x5d, 0x77, 0x77, 0x9d, 0x36, 0x7f, 0x81, 0x5f, 0xc7,
+	0x8d, 0xbf, 0x83, 0xbc, 0xfe, 0x48, 0x15, 0x4a, 0x3f, 0xc4, 0xc9, 0x3b, 0xb3, 0xf3, 0xcc, 0x7a,
+	0xe6, 0x79, 0x66, 0xa0, 0xc3, 0x12, 0x2d, 0x94, 0xcb, 0x42, 0x74, 0x62, 0x29, 0xb4, 0x20, 0x0f,
+	0x84, 0xeb, 0x07, 0x4e, 0xe1, 0x0d, 0xb8, 0xef, 0xcc, 0xb6, 0x59, 0x18, 0x9f, 0xb1, 0xed, 0xee,
+	0x63, 0x5f, 0x08, 0x3f, 0xc4, 0xa1, 0x09, 0x9c, 0x24, 0xa7, 0xc3, 0x0b, 0xc9, 0xe2, 0x18, 0xa5,
+	0xca, 0xa0, 0xfd, 0x5f, 0x16, 0x74, 0xf6, 0x8b, 0x74, 0x14, 0x67, 0x01, 0x5e, 0x90, 0x27, 0xd0,
+	0x62, 0x71, 0x30, 0x9e, 0xa1, 0x54, 0x81, 0xe0, 0xb6, 0xd5, 0xb3, 0x06, 0x4d, 0x0a, 0x2c, 0x0e,
+	0x4e, 0x32, 0x0f, 0x21, 0xb0, 0x32, 0x0d, 0xb8, 0x67, 0x57, 0xcc, 0x8d, 0x39, 0x93, 0x43, 0x58,
+	0x95, 0x78, 0x9e, 0xa0, 0xd2, 0x76, 0xb5, 0x67, 0x0d, 0x5a, 0xa3, 0x67, 0xce, 0x5f, 0xff, 0xca,
+	0xb9, 0xf2, 0xa2, 0x81, 0xd0, 0x02, 0x4b, 0x3e, 0x40, 0x43, 0xa2, 0x8a, 0x05, 0x57, 0x68, 0xaf,
+	0x98, 0x3c, 0xcf, 0xef, 0x96, 0x27, 0xc3, 0xd0, 0x12, 0xdd, 0xff, 0x51, 0x83, 0xb5, 0xe5, 0x77,
+	0xc8, 0x1a, 0x54, 0x93, 0xc0, 0xcb, 0x4b, 0x4a, 0x8f, 0x69, 0x2d, 0x9c, 0x45, 0x58, 0xd4, 0x92,
+	0x9e, 0xc9, 0x23, 0x68, 0xa6, 0x5f, 0x15, 0x33, 0x17, 0x4d, 0x35, 0x4d, 0xba, 0x70, 0x90, 0x6f,
+	0x00, 0x31, 0x93, 0x2c, 0x42, 0x8d, 0x52, 0xd9, 0x2b, 0xbd, 0xea, 0xa0, 0x35, 0x7a, 0x7d, 0x8f,
+	0x62, 0x9d, 0xa3, 0x12, 0x7d, 0xc8, 0xb5, 0x9c, 0xd3, 0x2b, 0xe9, 0xc8, 0x53, 0x58, 0x73, 0x13,
+	0x29, 0x91, 0xeb, 0xb1, 0xc4, 0x38, 0x0c, 0x5c, 0xa6, 0xec, 0x5a, 0xcf, 0x1a, 0xd4, 0x68, 0x27,
+	0xf7, 0xd3, 0xdc, 0x4d, 0xf6, 0xa0, 0x1d, 0x05, 0x7c, 0x11, 0x56, 0x37, 0xed, 0x7a, 0xe8, 0x64,
+	0x8c, 0x3b, 0x05, 0xe3, 0xce, 0x47, 0xae, 0x77, 0x46, 0x27, 0x2c, 0x4c, 0x90, 0xb6, 0xa2, 0x80,
+	0x97, 0xf8, 0x0d, 0x68, 0x47, 0xec, 0x72, 0x81, 0x5f, 0x35, 0xcf, 0xb4, 0x22, 0x76, 0x59, 0x86,
+	0x1c, 0x40, 0x5d, 0x33, 0xe9, 0xa3, 0xb6, 0x1b, 0x26, 0xf9, 0xe6, 0x0d, 0x65, 0x7e, 0x31, 0x81,
+	0x14, 0x4f, 0x51, 0x22, 0x77, 0x91, 0xe6, 0x48, 0xd2, 0x85, 0x86, 0xc2, 0x10, 0x5d, 0x2d, 0xa4,
+	0xdd, 0x34, 0xbd, 0x2c, 0x6d, 0xb2, 0x0b, 0x20, 0x91, 0x79, 0xf3, 0x71, 0x2c, 0x3c, 0x65, 0xc3,
+	0xed, 0x05, 0x34, 0x4d, 0xf8, 0x91, 0xf0, 0x54, 0x8a, 0xd5, 0x42, 0xb3, 0x30, 0xc3, 0xb6, 0xee,
+	0x80, 0x35, 0xe1, 0x06, 0xfb, 0x02, 0xd6, 0x8b, 0x2e, 0x47, 0xa8, 0x65, 0xe0, 0xaa, 0xf1, 0x77,
+	0x25, 0xb8, 0xdd, 0xee, 0x59, 0x83, 0x36, 0x25, 0xf9, 0xdd, 0xe7, 0xec, 0xea, 0x93, 0x12, 0xbc,
+	0xfb, 0x16, 0x3a, 0x4b, 0xb4, 0xa5, 0x5a, 0x9a, 0xe2, 0xbc, 0xd0, 0xd2, 0x14, 0xe7, 0x64, 0x1d,
+	0x6a, 0xb3, 0xf4, 0xa9, 0x5c, 0x4c, 0x99, 0xb1, 0x5b, 0x79, 0x69, 0xf5, 0xdf, 0x43, 0x67, 0xa9,
+	0x3f, 0xe5, 0x10, 0x59, 0x57, 0x86, 0x68, 0x69, 0xf2, 0x2a, 0xcb, 0x93, 0xd7, 0xff, 0x59, 0x81,
+	0xff, 0xfe, 0x10, 0xfd, 0x35, 0xaa, 0x5e, 0x87, 0x9a, 0x09, 0x31, 0x29, 0x1a, 0x34, 0x33, 0x52,
+	0x2a, 0x4a, 0xb6, 0xab, 0x86, 0xed, 0xd2, 0x26, 0xaf, 0xa0, 0x2e, 0x51, 0x25, 0xa1, 0xce, 0xc7,
+	0x6e, 0xe3, 0x06, 0xaa, 0x8f, 0x35, 0xd3, 0x89, 0xa2, 0x39, 0x80, 0xbc, 0x81, 0x96, 0xd6, 0xe1,
+	0x58, 0xa1, 0x2b, 0xb8, 0x97, 0xc9, 0xf5, 0x16, 0x2a, 0x40, 0xeb, 0xf0, 0x38, 0x0b, 0xff, 0x67,
+	0x19, 0xef, 0x5d, 0x23, 0xe3, 0x5b, 0xf1, 0x0b, 0x8d, 0xf7, 0x4f, 0xa1, 0x9e, 0xd5, 0x43, 0xfe,
+	0x87, 0xba, 0x32, 0xa7, 0xbc, 0x93, 0xb9, 0x45, 0x6c, 0x58, 0x8d, 0x50, 0x29, 0xe6, 0x17, 0xc4,
+	0x16, 0x66, 0x8a, 0x90, 0xc8, 0x52, 0xe5, 0x64, 0x5b, 0x22, 0xb7, 0x52, 0x6e, 0x5d, 0xe1, 0x65,
+	0x1b, 0xac, 0x46, 0xcd, 0x79, 0x74, 0x0e, 0x50, 0x32, 0x27, 0x89, 0x0b, 0xcd, 0xd2, 0x22, 0x9b,
+	0x77, 0xdb, 0x1e, 0xe9, 0x72, 0xee, 0xde, 0x23, 0xf6, 0x60, 0xff, 0xeb, 0x3b, 0x3f, 0xd0, 0x67,
+	0xc9, 0xc4, 0x71, 0x45, 0x34, 0x4c, 0x71, 0x43, 0x1f, 0x39, 0x4a, 0x16, 0x6e, 0xc5, 0xc2, 0xdb,
+	0x62, 0xe5, 0xef, 0x0c, 0xe3, 0xa9, 0x3f, 0xcc, 0x57, 0xb0, 0x1a, 0x96, 0xfe, 0x78, 0x32, 0xa9,
+	0x9b, 0xfe, 0xed, 0xfc, 0x1e, 0x00, 0xc7, 0xe8, 0x5e, 0xb4, 0x74, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AutoscalerClient is the client API for Autoscaler service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AutoscalerClient interface {
+	// Autoscale is passed a review with a populated request, and returns it with a populated response.
+	Autoscale(ctx context.Context, in *AutoscaleReview, opts ...grpc.CallOption) (*AutoscaleReview, error)
+}
+
+type autoscalerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAutoscalerClient(cc *grpc.ClientConn) AutoscalerClient {
+	return &autoscalerClient{cc}
+}
+
+func (c *autoscalerClient) Autoscale(ctx context.Context, in *AutoscaleReview, opts ...grpc.CallOption) (*AutoscaleReview, error) {
+	out := new(AutoscaleReview)
+	err := c.cc.Invoke(ctx, "/ocgi.autoscaling.v1alpha1.Autoscaler/Autoscale", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AutoscalerServer is the server API for Autoscaler service.
+type AutoscalerServer interface {
+	// Autoscale is passed a review with a populated request, and returns it with a populated response.
+	Autoscale(context.Context, *AutoscaleReview) (*AutoscaleReview, error)
+}
+
+// UnimplementedAutoscalerServer can be embedded to have forward compatible implementations.
+type UnimplementedAutoscalerServer struct {
+}
+
+func (*UnimplementedAutoscalerServer) Autoscale(ctx context.Context, req *AutoscaleReview) (*AutoscaleReview, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Autoscale not implemented")
+}
+
+func RegisterAutoscalerServer(s *grpc.Server, srv AutoscalerServer) {
+	s.RegisterService(&_Autoscaler_serviceDesc, srv)
+}
+
+func _Autoscaler_Autoscale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AutoscaleReview)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutoscalerServer).Autoscale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ocgi.autoscaling.v1alpha1.Autoscaler/Autoscale",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutoscalerServer).Autoscale(ctx, req.(*AutoscaleReview))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Autoscaler_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "ocgi.autoscaling.v1alpha1.Autoscaler",
+	HandlerType: (*AutoscalerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Autoscale",
+			Handler:    _Autoscaler_Autoscale_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "autoscale.proto",
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package autoscalepb is the gRPC protocol of webhook mode, its messages are converted from and to
+// the ones of JSON protocol in pkg/requests.
+package autoscalepb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. autoscale.proto
+
+import (
+	"encoding/json"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+)
+
+// FromReview converts the review of JSON protocol to the one of gRPC protocol
+func FromReview(review *requests.AutoscaleReview) (*AutoscaleReview, error) {
+	out := &AutoscaleReview{ApiVersion: review.APIVersion, Kind: review.Kind}
+	if req := review.Request; req != nil {
+		out.Request = &AutoscaleRequest{
+			Uid:             string(req.UID),
+			Name:            req.Name,
+			Namespace:       req.Namespace,
+			Parameters:      req.Parameters,
+			CurrentReplicas: req.CurrentReplicas,
+			MinReplicas:     fromInt32(req.MinReplicas),
+			MaxReplicas:     req.MaxReplicas,
+			Selector:        req.Selector,
+			ReadyPods:       fromInt32(req.ReadyPods),
+			TotalPods:       fromInt32(req.TotalPods),
+		}
+		if req.Target != nil {
+			out.Request.Target = &TargetReference{Kind: req.Target.Kind, ApiVersion: req.Target.APIVersion}
+		}
+		if len(req.CurrentMetrics) != 0 {
+			metrics, err := json.Marshal(req.CurrentMetrics)
+			if err != nil {
+				return nil, err
+			}
+			out.Request.CurrentMetricsJson = metrics
+		}
+	}
+	if resp := review.Response; resp != nil {
+		out.Response = &AutoscaleResponse{Uid: string(resp.UID), Scale: resp.Scale, Replicas: resp.Replicas,
+			TtlSeconds: fromInt32(resp.TTLSeconds), MinReplicas: fromInt32(resp.MinReplicas),
+			MaxReplicas: fromInt32(resp.MaxReplicas)}
+		if resp.Result != nil {
+			out.Response.Result = &Status{Status: resp.Result.Status, Message: resp.Result.Message,
+				Reason: string(resp.Result.Reason), Code: resp.Result.Code}
+		}
+	}
+	return out, nil
+}
+
+// ToReview converts the review of gRPC protocol to the one of JSON protocol
+func ToReview(review *AutoscaleReview) (*requests.AutoscaleReview, error) {
+	out := &requests.AutoscaleReview{TypeMeta: metav1.TypeMeta{APIVersion: review.ApiVersion, Kind: review.Kind}}
+	if req := review.Request; req != nil {
+		out.Request = &requests.AutoscaleRequest{
+			UID:             types.UID(req.Uid),
+			Name:            req.Name,
+			Namespace:       req.Namespace,
+			Parameters:      req.Parameters,
+			CurrentReplicas: req.CurrentReplicas,
+			MinReplicas:     toInt32(req.MinReplicas),
+			MaxReplicas:     req.MaxReplicas,
+			Selector:        req.Selector,
+			ReadyPods:       toInt32(req.ReadyPods),
+			TotalPods:       toInt32(req.TotalPods),
+		}
+		if req.Target != nil {
+			out.Request.Target = &requests.TargetReference{Kind: req.Target.Kind, APIVersion: req.Target.ApiVersion}
+		}
+		if len(req.CurrentMetricsJson) != 0 {
+			if err := json.Unmarshal(req.CurrentMetricsJson, &out.Request.CurrentMetrics); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if resp := review.Response; resp != nil {
+		out.Response = &requests.AutoscaleResponse{UID: types.UID(resp.Uid), Scale: resp.Scale, Replicas: resp.Replicas,
+			TTLSeconds: toInt32(resp.TtlSeconds), MinReplicas: toInt32(resp.MinReplicas),
+			MaxReplicas: toInt32(resp.MaxReplicas)}
+		if resp.Result != nil {
+			out.Response.Result = &metav1.Status{Status: resp.Result.Status, Message: resp.Result.Message,
+				Reason: metav1.StatusReason(resp.Result.Reason), Code: resp.Result.Code}
+		}
+	}
+	return out, nil
+}
+
+func fromInt32(v *int32) *wrappers.Int32Value {
+	if v == nil {
+		return nil
+	}
+	return &wrappers.Int32Value{Value: *v}
+}
+
+func toInt32(v *wrappers.Int32Value) *int32 {
+	if v == nil {
+		return nil
+	}
+	value := v.Value
+	return &value
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscalepb
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+)
+
+func TestReviewRoundTrip(t *testing.T) {
+	min, ready, ttl := int32(2), int32(3), int32(30)
+	review := &requests.AutoscaleReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: requests.APIVersion, Kind: requests.Kind},
+		Request: &requests.AutoscaleRequest{
+			UID:             "uid",
+			Name:            "squad-example",
+			Namespace:       "default",
+			Parameters:      map[string]string{"buffer": "2"},
+			CurrentReplicas: 3,
+			MinReplicas:     &min,
+			MaxReplicas:     10,
+			Target:          &requests.TargetReference{Kind: "Squad", APIVersion: "carrier.ocgi.dev/v1alpha1"},
+			Selector:        "app=squad-example",
+			ReadyPods:       &ready,
+			CurrentMetrics: []autoscalingv1.MetricStatus{
+				{
+					Type: autoscalingv1.ResourceMetricSourceType,
+					Resource: &autoscalingv1.ResourceMetricStatus{
+						Name:    corev1.ResourceCPU,
+						Current: autoscalingv1.MetricValueStatus{AverageValue: resource.NewMilliQuantity(500, resource.DecimalSI)},
+					},
+				},
+			},
+		},
+		Response: &requests.AutoscaleResponse{
+			UID:         "uid",
+			Result:      &metav1.Status{Status: metav1.StatusFailure, Code: 409, Reason: "Draining", Message: "draining"},
+			TTLSeconds:  &ttl,
+			MinReplicas: &min,
+		},
+	}
+	in, err := FromReview(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ToReview(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !apiequality.Semantic.DeepEqual(review, out) {
+		t.Errorf("desired review: %+v, actual: %+v", review, out)
+	}
+}
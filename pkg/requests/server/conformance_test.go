@@ -0,0 +1,310 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+	"github.com/ocgi/general-pod-autoscaler/pkg/scalercore"
+)
+
+// testDecider answers by the parameter answer of request
+var testDecider = DeciderFunc(func(ctx context.Context, req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+	if len(req.UID) == 0 || req.Namespace != "test-namespace" {
+		return nil, Reject(http.StatusBadRequest, "InvalidRequest", fmt.Sprintf("unexpected request %+v", req))
+	}
+	switch req.Parameters["answer"] {
+	case "scale":
+		return &requests.AutoscaleResponse{Scale: true, Replicas: req.CurrentReplicas + 2}, nil
+	case "keep":
+		return &requests.AutoscaleResponse{}, nil
+	case "bounds":
+		min := int32(4)
+		return &requests.AutoscaleResponse{MinReplicas: &min}, nil
+	case "reject":
+		return nil, Reject(http.StatusConflict, "Draining", "workload is draining")
+	case "fail":
+		return nil, errors.New("no metrics of workload")
+	case "negative":
+		return &requests.AutoscaleResponse{Scale: true, Replicas: -1}, nil
+	}
+	return nil, nil
+})
+
+// testCredentials are the certificates of server and client, and the files of server ones
+type testCredentials struct {
+	caBundle []byte
+	dir      string
+	secrets  corev1client.SecretsGetter
+}
+
+// newTestCredentials writes the serving certificate and the client CA to dir, and keeps the client
+// certificate and token in the secret webhook-auth
+func newTestCredentials(t *testing.T, dir string) *testCredentials {
+	ca, caKey, caBundle := newTestCA(t)
+	serverCert, serverKey := newTestCert(t, ca, caKey, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := newTestCert(t, ca, caKey, x509.ExtKeyUsageClientAuth)
+	for name, data := range map[string][]byte{"ca.crt": caBundle, "tls.crt": serverCert, "tls.key": serverKey} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secrets := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-auth", Namespace: "test-namespace"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:             clientCert,
+			corev1.TLSPrivateKeyKey:       clientKey,
+			corev1.ServiceAccountTokenKey: []byte("test-token"),
+		},
+	}).CoreV1()
+	return &testCredentials{caBundle: caBundle, dir: dir, secrets: secrets}
+}
+
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "webhook-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// newTestCert returns the PEM encoded certificate and key signed by ca for usage, the server
+// certificate is for 127.0.0.1
+func newTestCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "webhook"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// startServer serves the test decider with options, and returns the url of server and the func stopping it
+func startServer(t *testing.T, options Options, token string) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := NewServer(testDecider, token).Serve(listener, options, stopCh); err != nil {
+			t.Error(err)
+		}
+	}()
+	scheme := "http"
+	if len(options.TLSCert) != 0 {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%v://%v/scale", scheme, listener.Addr()), func() {
+		close(stopCh)
+		<-done
+	}
+}
+
+// TestConformance runs WebhookScaler against the server in each protocol end to end
+func TestConformance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	creds := newTestCredentials(t, dir)
+	tlsOptions := Options{TLSCert: filepath.Join(dir, "tls.crt"), TLSKey: filepath.Join(dir, "tls.key"),
+		TLSCA: filepath.Join(dir, "ca.crt")}
+
+	gpa := &autoscalingv1.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"},
+		Spec: autoscalingv1.GeneralPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Name: "squad-example"},
+		},
+	}
+	for _, p := range []struct {
+		name     string
+		protocol autoscalingv1.WebhookProtocol
+		tls      bool
+	}{
+		{name: "HTTP", protocol: autoscalingv1.HTTPWebhookProtocol},
+		{name: "HTTPS", protocol: autoscalingv1.HTTPWebhookProtocol, tls: true},
+		{name: "gRPC", protocol: autoscalingv1.GRPCWebhookProtocol},
+		{name: "gRPC with TLS", protocol: autoscalingv1.GRPCWebhookProtocol, tls: true},
+	} {
+		t.Run(p.name, func(t *testing.T) {
+			options := Options{GRPC: p.protocol == autoscalingv1.GRPCWebhookProtocol}
+			if p.tls {
+				options.TLSCert, options.TLSKey, options.TLSCA = tlsOptions.TLSCert, tlsOptions.TLSKey, tlsOptions.TLSCA
+			}
+			url, stop := startServer(t, options, "test-token")
+			defer stop()
+			transports := scalercore.NewTransportCache()
+			defer transports.Release("test-namespace/test-gpa")
+
+			for _, c := range []struct {
+				answer string
+				// secret is the authentication secret, the token is sent without client certificate if it is
+				// not webhook-auth
+				secret   string
+				replicas int32
+				min      *int32
+				rejected *scalercore.WebhookRejectedError
+			}{
+				{answer: "scale", replicas: 5},
+				{answer: "keep", replicas: 3},
+				{answer: "bounds", replicas: 3, min: &[]int32{4}[0]},
+				{answer: "reject", rejected: &scalercore.WebhookRejectedError{Code: http.StatusConflict, Reason: "Draining",
+					Message: "workload is draining"}},
+				{answer: "fail", rejected: &scalercore.WebhookRejectedError{Code: http.StatusInternalServerError,
+					Reason: string(metav1.StatusReasonInternalError), Message: "no metrics of workload"}},
+				{answer: "negative", rejected: &scalercore.WebhookRejectedError{Code: http.StatusInternalServerError,
+					Reason: ReasonInvalidDecision}},
+				{answer: "none", rejected: &scalercore.WebhookRejectedError{Code: http.StatusInternalServerError,
+					Reason: ReasonInvalidDecision}},
+				{answer: "scale", secret: "webhook-no-token", rejected: &scalercore.WebhookRejectedError{
+					Code: http.StatusUnauthorized}},
+			} {
+				mode := &autoscalingv1.WebhookMode{
+					WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url},
+					Parameters:          map[string]string{"answer": c.answer},
+					Protocol:            p.protocol,
+				}
+				if c.secret != "webhook-no-token" {
+					mode.Authentication = &autoscalingv1.WebhookAuthentication{SecretName: "webhook-auth"}
+				} else if p.tls {
+					// the client certificate is required by the server
+					continue
+				}
+				if p.tls {
+					mode.CABundle = creds.caBundle
+				}
+				s := scalercore.NewWebhookScaler(mode, transports, nil, creds.secrets, "test-namespace/test-gpa",
+					clock.RealClock{}).(*scalercore.WebhookScaler)
+				replicas, err := s.GetReplicas(gpa, 3)
+				if c.rejected != nil {
+					rejected, ok := err.(*scalercore.WebhookRejectedError)
+					if !ok || rejected.Code != c.rejected.Code || (len(c.rejected.Reason) != 0 && rejected.Reason != c.rejected.Reason) ||
+						(len(c.rejected.Message) != 0 && rejected.Message != c.rejected.Message) {
+						t.Errorf("%v: desired rejection: %+v, actual: %v", c.answer, c.rejected, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("%v: %v", c.answer, err)
+					continue
+				}
+				if replicas != c.replicas {
+					t.Errorf("%v: desired replicas: %v, actual: %v", c.answer, c.replicas, replicas)
+				}
+				if bounds, _ := s.Bounds(); (bounds != nil) != (c.min != nil) ||
+					(bounds != nil && *bounds.MinReplicas != *c.min) {
+					t.Errorf("%v: desired min replicas: %v, actual: %+v", c.answer, c.min, bounds)
+				}
+			}
+		})
+	}
+}
+
+// TestConformanceBatch runs WebhookScalers sharing the server in batch mode
+func TestConformanceBatch(t *testing.T) {
+	url, stop := startServer(t, Options{}, "")
+	defer stop()
+	transports, batcher := scalercore.NewTransportCache(), scalercore.NewWebhookBatcher()
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url},
+		Parameters:          map[string]string{"answer": "scale"},
+		Batch:               &autoscalingv1.WebhookBatch{Window: &metav1.Duration{Duration: 100 * time.Millisecond}},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "squad-" + strconv.Itoa(i)
+			gpa := &autoscalingv1.GeneralPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-namespace"},
+				Spec:       autoscalingv1.GeneralPodAutoscalerSpec{ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Name: name}},
+			}
+			s := scalercore.NewWebhookScaler(mode, transports, batcher, nil, "test-namespace/"+name, clock.RealClock{})
+			replicas, err := s.GetReplicas(gpa, int32(i))
+			if err != nil {
+				t.Errorf("gpa %v: %v", i, err)
+			} else if replicas != int32(i+2) {
+				t.Errorf("gpa %v: desired replicas: %v, actual: %v", i, i+2, replicas)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,355 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package server is the webhook side of webhook mode. It decodes the reviews sent by GPA in the JSON and
+// gRPC protocols, checks the bearer token, asks a Decider for the answer of each request, and answers with
+// the uid of request. A webhook only implements the Decider.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests/autoscalepb"
+)
+
+const (
+	// ReasonInvalidDecision is the reason of answer if the Decider gives no answer or negative replicas
+	ReasonInvalidDecision = "InvalidDecision"
+	// maxBodyBytes is the max size of review accepted
+	maxBodyBytes = 10 << 20
+)
+
+// Decider decides the answer of request. The uid of answer is set by the server. An error returned is
+// answered as the rejection of request, the code and reason of RejectedError are answered as they are,
+// other errors are answered as 500.
+type Decider interface {
+	Decide(ctx context.Context, request *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error)
+}
+
+// DeciderFunc is a function implementing Decider
+type DeciderFunc func(ctx context.Context, request *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error)
+
+// Decide calls f
+func (f DeciderFunc) Decide(ctx context.Context, request *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+	return f(ctx, request)
+}
+
+// RejectedError is returned by the Decider to reject the request
+type RejectedError struct {
+	// Code is the status code answered, e.g. 409, the 429 and 5xx rejections are retried by GPA
+	Code int32
+	// Reason is the machine readable reason, e.g. Draining
+	Reason string
+	// Message is the human readable description of rejection
+	Message string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("rejected with code %d, reason %v: %v", e.Code, e.Reason, e.Message)
+}
+
+// Reject returns the rejection of request with code, reason and message
+func Reject(code int32, reason, message string) error {
+	return &RejectedError{Code: code, Reason: reason, Message: message}
+}
+
+// Options is the options of listening
+type Options struct {
+	// Address is the address listened, e.g. 0.0.0.0:8000
+	Address string
+	// TLSCert and TLSKey are the paths of serving certificate, the server serves without TLS if they are empty
+	TLSCert string
+	TLSKey  string
+	// TLSCA is the path of CA verifying the client certificates, client certificates are not required if
+	// it is empty
+	TLSCA string
+	// GRPC serves the gRPC protocol instead of the JSON protocol
+	GRPC bool
+}
+
+// Server answers the reviews of GPA by the Decider, it serves the JSON protocol as an http.Handler and the
+// gRPC protocol as an autoscalepb.AutoscalerServer
+type Server struct {
+	decider Decider
+	// token is the bearer token required, no token required if it is empty
+	token string
+}
+
+var _ http.Handler = &Server{}
+var _ autoscalepb.AutoscalerServer = &Server{}
+
+// NewServer returns the server answering by decider, the requests without the bearer token are
+// rejected if token is not empty
+func NewServer(decider Decider, token string) *Server {
+	return &Server{decider: decider, token: token}
+}
+
+// ServeHTTP answers the AutoscaleReview and AutoscaleBatchReview posted in JSON
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed,
+			fmt.Sprintf("method %v is not allowed", r.Method))
+		return
+	}
+	if len(s.token) != 0 && r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeStatus(w, http.StatusUnauthorized, metav1.StatusReasonUnauthorized, "invalid bearer token")
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(body, &typeMeta); err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+	if err := checkVersion(typeMeta.APIVersion); err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+
+	switch typeMeta.Kind {
+	case requests.Kind:
+		var review requests.AutoscaleReview
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, "review without request")
+			return
+		}
+		resp := s.decide(r.Context(), review.Request)
+		code := http.StatusOK
+		if resp.Result != nil && resp.Result.Code != 0 {
+			code = int(resp.Result.Code)
+		}
+		writeJSON(w, code, &requests.AutoscaleReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: requests.APIVersion, Kind: requests.Kind},
+			Response: resp,
+		})
+	case requests.BatchKind:
+		var batch requests.AutoscaleBatchReview
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, &requests.AutoscaleBatchReview{
+			TypeMeta:  metav1.TypeMeta{APIVersion: requests.APIVersion, Kind: requests.BatchKind},
+			Responses: s.decideBatch(r.Context(), batch.Requests),
+		})
+	default:
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest,
+			fmt.Sprintf("unsupported kind %q of review", typeMeta.Kind))
+	}
+}
+
+// Autoscale answers the AutoscaleReview of gRPC protocol
+func (s *Server) Autoscale(ctx context.Context, in *autoscalepb.AutoscaleReview) (*autoscalepb.AutoscaleReview, error) {
+	if len(s.token) != 0 {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if auth := md.Get("authorization"); len(auth) == 0 || auth[0] != "Bearer "+s.token {
+			return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+		}
+	}
+	if err := checkVersion(in.ApiVersion); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	review, err := autoscalepb.ToReview(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if review.Request == nil {
+		return nil, status.Error(codes.InvalidArgument, "review without request")
+	}
+	return autoscalepb.FromReview(&requests.AutoscaleReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: requests.APIVersion, Kind: requests.Kind},
+		Response: s.decide(ctx, review.Request),
+	})
+}
+
+// decide returns the answer of request with the uid of request, the rejection is answered with result
+func (s *Server) decide(ctx context.Context, request *requests.AutoscaleRequest) *requests.AutoscaleResponse {
+	resp, err := s.decider.Decide(ctx, request)
+	switch {
+	case err != nil:
+		resp = &requests.AutoscaleResponse{Result: resultOf(err)}
+	case resp == nil:
+		resp = &requests.AutoscaleResponse{Result: resultOf(Reject(http.StatusInternalServerError,
+			ReasonInvalidDecision, "no answer of request"))}
+	case resp.Scale && resp.Replicas < 0:
+		resp = &requests.AutoscaleResponse{Result: resultOf(Reject(http.StatusInternalServerError,
+			ReasonInvalidDecision, fmt.Sprintf("replicas %d should not be negative", resp.Replicas)))}
+	}
+	if resp.Result != nil {
+		klog.V(4).Infof("Request %v of %v/%v rejected: %v", request.UID, request.Namespace, request.Name,
+			resp.Result.Message)
+	}
+	resp.UID = request.UID
+	return resp
+}
+
+// decideBatch returns the answers of requests in batch, the requests are decided concurrently
+func (s *Server) decideBatch(ctx context.Context, batch []requests.AutoscaleRequest) []requests.AutoscaleResponse {
+	responses := make([]requests.AutoscaleResponse, len(batch))
+	var wg sync.WaitGroup
+	for i := range batch {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = *s.decide(ctx, &batch[i])
+		}(i)
+	}
+	wg.Wait()
+	return responses
+}
+
+// Run listens on the address of options, and serves until stopCh is closed
+func (s *Server) Run(options Options, stopCh <-chan struct{}) error {
+	listener, err := net.Listen("tcp", options.Address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener, options, stopCh)
+}
+
+// Serve serves on listener until stopCh is closed, the address of options is ignored
+func (s *Server) Serve(listener net.Listener, options Options, stopCh <-chan struct{}) error {
+	var tlsConfig *tls.Config
+	if len(options.TLSCert) != 0 && len(options.TLSKey) != 0 {
+		var err error
+		if tlsConfig, err = getTLSConfig(options); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	if options.GRPC {
+		var serverOptions []grpc.ServerOption
+		if tlsConfig != nil {
+			serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		grpcServer := grpc.NewServer(serverOptions...)
+		autoscalepb.RegisterAutoscalerServer(grpcServer, s)
+		go func() {
+			<-stopCh
+			grpcServer.GracefulStop()
+		}()
+		klog.V(1).Infof("gRPC webhook listening on %v, TLS: %v", listener.Addr(), tlsConfig != nil)
+		return grpcServer.Serve(listener)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", s)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s", "ok")
+	})
+	server := &http.Server{Handler: mux, TLSConfig: tlsConfig}
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			klog.Error(err)
+		}
+	}()
+	klog.V(1).Infof("webhook listening on %v, TLS: %v", listener.Addr(), tlsConfig != nil)
+	var err error
+	if tlsConfig != nil {
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// getTLSConfig returns the TLS config of serving certificate, and verifies the client certificates by
+// the CA if it is set
+func getTLSConfig(options Options) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(options.TLSCert, options.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not load serving certificate: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(options.TLSCA) != 0 {
+		file, err := ioutil.ReadFile(options.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificate: %v", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(file) {
+			return nil, fmt.Errorf("no CA certificate in %v", options.TLSCA)
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.ClientCAs = certPool
+	}
+	return tlsConfig, nil
+}
+
+// checkVersion checks the version of review is supported, the review without version is accepted
+func checkVersion(apiVersion string) error {
+	if len(apiVersion) != 0 && apiVersion != requests.APIVersion {
+		return fmt.Errorf("unsupported apiVersion %v of review", apiVersion)
+	}
+	return nil
+}
+
+// resultOf returns the result answering the error of Decider
+func resultOf(err error) *metav1.Status {
+	if rejected, ok := err.(*RejectedError); ok {
+		return &metav1.Status{Status: metav1.StatusFailure, Code: rejected.Code,
+			Reason: metav1.StatusReason(rejected.Reason), Message: rejected.Message}
+	}
+	return &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusInternalServerError,
+		Reason: metav1.StatusReasonInternalError, Message: err.Error()}
+}
+
+// writeStatus answers the review failed before it is decided
+func writeStatus(w http.ResponseWriter, code int, reason metav1.StatusReason, message string) {
+	writeJSON(w, code, &requests.AutoscaleReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: requests.APIVersion, Kind: requests.Kind},
+		Response: &requests.AutoscaleResponse{Result: &metav1.Status{Status: metav1.StatusFailure,
+			Code: int32(code), Reason: reason, Message: message}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.Errorf("Write answer failed: %v", err)
+	}
+}
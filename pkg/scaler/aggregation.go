@@ -0,0 +1,118 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	autoscaling "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// metricProposal is the replicas proposed by a metric, recommendation is the index of its recommendation
+// in the status of GPA
+type metricProposal struct {
+	replicas       int32
+	metric         string
+	weight         int32
+	timestamp      time.Time
+	recommendation int
+}
+
+// newMetricProposal returns the proposal of metric spec, recorded at the last recommendation of gpa
+func newMetricProposal(gpa *autoscaling.GeneralPodAutoscaler, spec autoscaling.MetricSpec, replicas int32,
+	metric string, timestamp time.Time) metricProposal {
+	weight := int32(1)
+	if spec.Weight != nil {
+		weight = *spec.Weight
+	}
+	return metricProposal{replicas, metric, weight, timestamp, len(gpa.Status.Recommendations) - 1}
+}
+
+// aggregateMetricProposals combines the proposals of metrics by the metric aggregation of gpa, the
+// recommendations of the decisive proposals are marked. It returns the replicas, a description of the
+// decisive metrics and the latest timestamp of them. proposals must not be empty.
+func aggregateMetricProposals(gpa *autoscaling.GeneralPodAutoscaler,
+	proposals []metricProposal) (replicas int32, metric string, timestamp time.Time) {
+	policy := autoscaling.MaxMetricAggregation
+	var quorum *int32
+	if gpa.Spec.MetricAggregation != nil {
+		policy = gpa.Spec.MetricAggregation.Policy
+		quorum = gpa.Spec.MetricAggregation.Quorum
+	}
+
+	var decisive []int
+	switch policy {
+	case autoscaling.AverageMetricAggregation, autoscaling.WeightedAverageMetricAggregation:
+		var sum, weights float64
+		for i, p := range proposals {
+			weight := float64(1)
+			if policy == autoscaling.WeightedAverageMetricAggregation {
+				weight = float64(p.weight)
+			}
+			sum += float64(p.replicas) * weight
+			weights += weight
+			decisive = append(decisive, i)
+		}
+		replicas = int32(math.Ceil(sum / weights))
+	case autoscaling.QuorumMetricAggregation:
+		// the quorum-th highest proposal is proposed or exceeded by the quorum of metrics
+		count := len(proposals)/2 + 1
+		if quorum != nil {
+			count = int(*quorum)
+		}
+		if count > len(proposals) {
+			count = len(proposals)
+		}
+		if count < 1 {
+			count = 1
+		}
+		sorted := make([]int, len(proposals))
+		for i := range sorted {
+			sorted[i] = i
+		}
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return proposals[sorted[i]].replicas > proposals[sorted[j]].replicas
+		})
+		selected := sorted[count-1]
+		replicas, decisive = proposals[selected].replicas, []int{selected}
+	default:
+		selected := 0
+		for i, p := range proposals {
+			if (policy == autoscaling.MinMetricAggregation && p.replicas < proposals[selected].replicas) ||
+				(policy != autoscaling.MinMetricAggregation && p.replicas > proposals[selected].replicas) {
+				selected = i
+			}
+		}
+		replicas, decisive = proposals[selected].replicas, []int{selected}
+	}
+
+	names := make([]string, 0, len(decisive))
+	for _, i := range decisive {
+		gpa.Status.Recommendations[proposals[i].recommendation].Decisive = true
+		names = append(names, proposals[i].metric)
+		if proposals[i].timestamp.After(timestamp) {
+			timestamp = proposals[i].timestamp
+		}
+	}
+	metric = names[0]
+	if len(names) > 1 {
+		metric = fmt.Sprintf("%s(%s)", policy, strings.Join(names, ", "))
+	}
+	return replicas, metric, timestamp
+}
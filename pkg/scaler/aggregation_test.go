@@ -0,0 +1,80 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	autoscalingv1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+func TestAggregateMetricProposals(t *testing.T) {
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	// cpu is the primary metric, memory and qps are the secondary ones
+	specs := []autoscalingv1alpha1.MetricSpec{
+		{Type: autoscalingv1alpha1.ResourceMetricSourceType, Weight: int32Ptr(3)},
+		{Type: autoscalingv1alpha1.ResourceMetricSourceType},
+		{Type: autoscalingv1alpha1.ExternalMetricSourceType},
+	}
+	metrics := []string{"cpu", "memory", "qps"}
+	replicas := []int32{4, 10, 6}
+	for _, c := range []struct {
+		name        string
+		aggregation *autoscalingv1alpha1.MetricAggregation
+		replicas    int32
+		metric      string
+		decisive    []bool
+	}{
+		{name: "max by default", replicas: 10, metric: "memory", decisive: []bool{false, true, false}},
+		{name: "min", aggregation: &autoscalingv1alpha1.MetricAggregation{Policy: autoscalingv1alpha1.MinMetricAggregation},
+			replicas: 4, metric: "cpu", decisive: []bool{true, false, false}},
+		{name: "average", aggregation: &autoscalingv1alpha1.MetricAggregation{
+			Policy: autoscalingv1alpha1.AverageMetricAggregation}, replicas: 7, metric: "Average(cpu, memory, qps)",
+			decisive: []bool{true, true, true}},
+		{name: "weighted average", aggregation: &autoscalingv1alpha1.MetricAggregation{
+			Policy: autoscalingv1alpha1.WeightedAverageMetricAggregation}, replicas: 6,
+			metric: "WeightedAverage(cpu, memory, qps)", decisive: []bool{true, true, true}},
+		{name: "majority quorum", aggregation: &autoscalingv1alpha1.MetricAggregation{
+			Policy: autoscalingv1alpha1.QuorumMetricAggregation}, replicas: 6, metric: "qps",
+			decisive: []bool{false, false, true}},
+		{name: "quorum of all", aggregation: &autoscalingv1alpha1.MetricAggregation{
+			Policy: autoscalingv1alpha1.QuorumMetricAggregation, Quorum: int32Ptr(3)}, replicas: 4, metric: "cpu",
+			decisive: []bool{true, false, false}},
+		{name: "quorum above proposals", aggregation: &autoscalingv1alpha1.MetricAggregation{
+			Policy: autoscalingv1alpha1.QuorumMetricAggregation, Quorum: int32Ptr(5)}, replicas: 4, metric: "cpu",
+			decisive: []bool{true, false, false}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{
+				Spec: autoscalingv1alpha1.GeneralPodAutoscalerSpec{MetricAggregation: c.aggregation},
+			}
+			var proposals []metricProposal
+			for i, spec := range specs {
+				timestamp := start.Add(time.Duration(i) * time.Second)
+				setRecommendation(gpa, metrics[i], replicas[i], timestamp, nil)
+				proposals = append(proposals, newMetricProposal(gpa, spec, replicas[i], metrics[i], timestamp))
+			}
+			replicas, metric, _ := aggregateMetricProposals(gpa, proposals)
+			assert.Equal(t, c.replicas, replicas)
+			assert.Equal(t, c.metric, metric)
+			for i, rec := range gpa.Status.Recommendations {
+				assert.Equal(t, c.decisive[i], rec.Decisive, "recommendation of %v", rec.Name)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -78,6 +80,21 @@ type timestampedRecommendation struct {
 	timestamp      time.Time
 }
 
+// modeProposal is the replicas proposed by a driven mode
+type modeProposal struct {
+	mode      string
+	replicas  int32
+	metric    string
+	timestamp time.Time
+}
+
+// scalerModeNames maps the scaler name to the driven mode it belongs to
+var scalerModeNames = map[string]string{
+	scalercore.Webhook: autoscaling.WebhookModeName,
+	scalercore.Cron:    autoscaling.TimeModeName,
+	scalercore.Event:   autoscaling.EventModeName,
+}
+
 type timestampedScaleEvent struct {
 	replicaChange int32 // positive for scaleUp, negative for scaleDown
 	timestamp     time.Time
@@ -107,6 +124,15 @@ type GeneralController struct {
 	podLister       corelisters.PodLister
 	podListerSynced cache.InformerSynced
 
+	// calendarLister is able to list/get HolidayCalendars from the shared cache from the informer passed in to
+	// NewGeneralController.
+	calendarLister       autoscalinglisters.HolidayCalendarLister
+	calendarListerSynced cache.InformerSynced
+
+	// secretNamespacer gets the webhook authentication secrets referenced by GPAs, they are not cached so
+	// that only the referenced secrets are read.
+	secretNamespacer v1core.SecretsGetter
+
 	// Controllers that need to be synced
 	queue workqueue.RateLimitingInterface
 
@@ -126,6 +152,26 @@ type GeneralController struct {
 
 	doingCron sync.Map
 
+	// Long run scalers of each autoscaler, they keep running until the spec changed or the autoscaler deleted.
+	longRunScalers map[string]*longRunScalers
+
+	// Multi goroutine read and write long run scalers may unsafe.
+	longRunScalersLock sync.Mutex
+
+	// stopCh is the stop channel of controller, long run scalers are stopped with it.
+	stopCh <-chan struct{}
+
+	// clock is the time source of scaling, schedules and stabilization are evaluated with it.
+	clock clock.Clock
+
+	// transports are the webhook clients of autoscalers, released when the autoscaler deleted or its webhook
+	// mode removed.
+	transports *scalercore.TransportCache
+	// scheduleTransitions are the next transitions of cron schedule of autoscalers.
+	scheduleTransitions *scalercore.ScheduleTransitionCache
+	// batcher groups the webhook calls of autoscalers sharing a webhook in batch mode.
+	batcher *scalercore.WebhookBatcher
+
 	workers int
 }
 
@@ -138,6 +184,8 @@ func NewGeneralController(
 	metricsClient metricsclient.MetricsClient,
 	gpaInformer autoscalinginformers.GeneralPodAutoscalerInformer,
 	podInformer coreinformers.PodInformer,
+	calendarInformer autoscalinginformers.HolidayCalendarInformer,
+	secretNamespacer v1core.SecretsGetter,
 	resyncPeriod time.Duration,
 	downscaleStabilisationWindow time.Duration,
 	tolerance float64,
@@ -155,15 +203,21 @@ func NewGeneralController(
 	gpaController := &GeneralController{
 		eventRecorder:                recorder,
 		scaleNamespacer:              scaleNamespacer,
+		secretNamespacer:             secretNamespacer,
 		gpaNamespacer:                gpaNamespacer,
 		downscaleStabilisationWindow: downscaleStabilisationWindow,
 		queue: workqueue.NewNamedRateLimitingQueue(
 			NewDefaultGPARateLimiter(resyncPeriod), "podautoscaler"),
-		mapper:          mapper,
-		recommendations: map[string][]timestampedRecommendation{},
-		scaleUpEvents:   map[string][]timestampedScaleEvent{},
-		scaleDownEvents: map[string][]timestampedScaleEvent{},
-		workers:         workers,
+		mapper:              mapper,
+		recommendations:     map[string][]timestampedRecommendation{},
+		scaleUpEvents:       map[string][]timestampedScaleEvent{},
+		scaleDownEvents:     map[string][]timestampedScaleEvent{},
+		longRunScalers:      map[string]*longRunScalers{},
+		clock:               clock.RealClock{},
+		transports:          scalercore.NewTransportCache(),
+		scheduleTransitions: scalercore.NewScheduleTransitionCache(),
+		batcher:             scalercore.NewWebhookBatcher(),
+		workers:             workers,
 	}
 
 	gpaInformer.Informer().AddEventHandlerWithResyncPeriod(
@@ -180,6 +234,9 @@ func NewGeneralController(
 	gpaController.podLister = podInformer.Lister()
 	gpaController.podListerSynced = podInformer.Informer().HasSynced
 
+	gpaController.calendarLister = calendarInformer.Lister()
+	gpaController.calendarListerSynced = calendarInformer.Informer().HasSynced
+
 	replicaCalc := NewReplicaCalculator(
 		metricsClient,
 		gpaController.podLister,
@@ -200,9 +257,10 @@ func (a *GeneralController) Run(stopCh <-chan struct{}) {
 	klog.Infof("Starting GPA controller, workers is %v", a.workers)
 	defer klog.Infof("Shutting down GPA controller")
 
-	if !cache.WaitForNamedCacheSync("GPA", stopCh, a.gpaListerSynced, a.podListerSynced) {
+	if !cache.WaitForNamedCacheSync("GPA", stopCh, a.gpaListerSynced, a.podListerSynced, a.calendarListerSynced) {
 		return
 	}
+	a.stopCh = stopCh
 	// start some workers
 	for i := 0; i < a.workers; i++ {
 		go wait.Until(a.worker, time.Second, stopCh)
@@ -213,6 +271,15 @@ func (a *GeneralController) Run(stopCh <-chan struct{}) {
 
 // obj could be an *v1.GeneralPodAutoscaler, or a DeletionFinalStateUnknown marker item.
 func (a *GeneralController) updateGPA(old, cur interface{}) {
+	oldGPA, oldOK := old.(*autoscaling.GeneralPodAutoscaler)
+	curGPA, curOK := cur.(*autoscaling.GeneralPodAutoscaler)
+	if oldOK && curOK && !apiequality.Semantic.DeepEqual(oldGPA.Spec.AutoScalingDrivenMode,
+		curGPA.Spec.AutoScalingDrivenMode) {
+		// long run scalers would be rebuilt with the new spec when reconciling
+		if key, err := cache.MetaNamespaceKeyFunc(curGPA); err == nil {
+			a.stopLongRunScalers(key)
+		}
+	}
 	a.enqueueGPA(cur)
 }
 
@@ -239,6 +306,9 @@ func (a *GeneralController) deleteGPA(obj interface{}) {
 
 	// TODO: could we leak if we fail to get the key?
 	a.queue.Forget(key)
+	a.stopLongRunScalers(key)
+	a.transports.Release(key)
+	a.scheduleTransitions.Release(key)
 }
 
 func (a *GeneralController) worker() {
@@ -274,8 +344,8 @@ func (a *GeneralController) processNextWorkItem() bool {
 }
 
 // computeReplicasForMetrics computes the desired number of replicas for the metric specifications listed in the GPA,
-// returning the maximum  of the computed replica counts, a description of the associated metric, and the statuses of
-// all metrics computed.
+// returning the computed replica counts combined by the metric aggregation, a description of the associated metric,
+// and the statuses of all metrics computed.
 func (a *GeneralController) computeReplicasForMetrics(gpa *autoscaling.GeneralPodAutoscaler,
 	scale *autoscalinginternal.Scale, metricSpecs []autoscaling.MetricSpec) (replicas int32, metric string,
 	statuses []autoscaling.MetricStatus, timestamp time.Time, err error) {
@@ -304,20 +374,21 @@ func (a *GeneralController) computeReplicasForMetrics(gpa *autoscaling.GeneralPo
 	var invalidMetricError error
 	var invalidMetricCondition autoscaling.GeneralPodAutoscalerCondition
 
+	var proposals []metricProposal
 	for i, metricSpec := range metricSpecs {
 		replicaCountProposal, metricNameProposal, timestampProposal, condition, err := a.computeReplicasForMetric(gpa,
 			metricSpec, specReplicas, statusReplicas, selector, &statuses[i])
+		setRecommendation(gpa, recommendationName(autoscaling.MetricModeName, metricSourceName(metricSpec)),
+			replicaCountProposal, timestampProposal, err)
 		if err != nil {
 			if invalidMetricsCount <= 0 {
 				invalidMetricCondition = condition
 				invalidMetricError = err
 			}
 			invalidMetricsCount++
-		}
-		if err == nil && (replicas == 0 || replicaCountProposal > replicas) {
-			timestamp = timestampProposal
-			replicas = replicaCountProposal
-			metric = metricNameProposal
+		} else {
+			proposals = append(proposals, newMetricProposal(gpa, metricSpec, replicaCountProposal, metricNameProposal,
+				timestampProposal))
 		}
 	}
 
@@ -328,14 +399,15 @@ func (a *GeneralController) computeReplicasForMetrics(gpa *autoscaling.GeneralPo
 		return 0, "", statuses, time.Time{}, fmt.Errorf("invalid metrics (%v invalid out of %v), "+
 			"first error is: %v", invalidMetricsCount, len(metricSpecs), invalidMetricError)
 	}
+	replicas, metric, timestamp = aggregateMetricProposals(gpa, proposals)
 	setCondition(gpa, autoscaling.ScalingActive, v1.ConditionTrue, "ValidMetricFound",
 		"the GPA was able to successfully calculate a replica count from %s", metric)
 	return replicas, metric, statuses, timestamp, nil
 }
 
 // computeReplicasForCronMetrics computes the desired number of replicas for the metric specifications listed in the GPA,
-// returning the maximum  of the computed replica counts, a description of the associated metric, and the statuses of
-// all metrics computed.
+// returning the computed replica counts combined by the metric aggregation, a description of the associated metric,
+// and the statuses of all metrics computed.
 func (a *GeneralController) computeReplicasForCronMetrics(gpa *autoscaling.GeneralPodAutoscaler, scale *autoscalinginternal.Scale,
 	metricSpecs []autoscaling.CronMetricSpec, scheduleName string) (replicas int32, metric string, statuses []autoscaling.MetricStatus, timestamp time.Time, err error) {
 	if scale.Status.Selector == "" {
@@ -361,20 +433,22 @@ func (a *GeneralController) computeReplicasForCronMetrics(gpa *autoscaling.Gener
 	var invalidMetricError error
 	var invalidMetricCondition autoscaling.GeneralPodAutoscalerCondition
 
+	var proposals []metricProposal
 	for i, metricSpec := range metricSpecs {
 		replicaCountProposal, metricNameProposal, timestampProposal, condition, err := a.computeReplicasForCronMetric(gpa,
 			metricSpec, specReplicas, statusReplicas, selector, &statuses[i])
+		setRecommendation(gpa, recommendationName(autoscaling.CronMetricModeName,
+			fmt.Sprintf("%s %s", scheduleName, metricSourceName(metricSpec.MetricSpec))),
+			replicaCountProposal, timestampProposal, err)
 		if err != nil {
 			if invalidMetricsCount <= 0 {
 				invalidMetricCondition = condition
 				invalidMetricError = err
 			}
 			invalidMetricsCount++
-		}
-		if err == nil && (replicas == 0 || replicaCountProposal > replicas) {
-			timestamp = timestampProposal
-			replicas = replicaCountProposal
-			metric = fmt.Sprintf("cron %s %s", scheduleName, metricNameProposal)
+		} else {
+			proposals = append(proposals, newMetricProposal(gpa, metricSpec.MetricSpec, replicaCountProposal,
+				fmt.Sprintf("cron %s %s", scheduleName, metricNameProposal), timestampProposal))
 		}
 	}
 
@@ -385,58 +459,311 @@ func (a *GeneralController) computeReplicasForCronMetrics(gpa *autoscaling.Gener
 		return 0, "", statuses, time.Time{}, fmt.Errorf("invalid metrics (%v invalid out of %v), "+
 			"first error is: %v", invalidMetricsCount, len(metricSpecs), invalidMetricError)
 	}
+	replicas, metric, timestamp = aggregateMetricProposals(gpa, proposals)
 	setCondition(gpa, autoscaling.ScalingActive, v1.ConditionTrue, "ValidMetricFound",
 		"the GPA was able to successfully calculate a replica count from %s", metric)
 	return replicas, metric, statuses, timestamp, nil
 }
 
-// computeReplicasForSimple computes the desired number of replicas for the metric specifications listed in the GPA,
-// returning the maximum  of the computed replica counts, a description of the associated metric, and the statuses of
-// all metrics computed.
-func (a *GeneralController) computeReplicasForSimple(gpa *autoscaling.GeneralPodAutoscaler,
-	scale *autoscalinginternal.Scale) (replicas int32, metric string, statuses []autoscaling.MetricStatus,
+// computeReplicasForModes computes the desired number of replicas for all the driven modes configured in the GPA,
+// the proposals of modes are combined by the mode select policy and recorded in the status of GPA.
+func (a *GeneralController) computeReplicasForModes(gpa *autoscaling.GeneralPodAutoscaler,
+	scale *autoscalinginternal.Scale, key string, cronMetricsScale *scalercore.CronMetricsScaler,
+	scheduleName string) (replicas int32, metric string, statuses []autoscaling.MetricStatus,
 	timestamp time.Time, err error) {
+	var (
+		proposals []modeProposal
+		failures  []string
+	)
+	// failMode records the failure of mode, a recommendation is added for the mode if none of its sources
+	// is recorded
+	failMode := func(mode string, recorded int, err error) {
+		failures = append(failures, fmt.Sprintf("%s: %v", mode, err))
+		if len(gpa.Status.Recommendations) == recorded {
+			setRecommendation(gpa, mode, 0, time.Time{}, err)
+		}
+	}
+	previousRecommendations := gpa.Status.Recommendations
+	gpa.Status.Recommendations = nil
+	if gpa.Spec.MetricMode != nil {
+		recorded := len(gpa.Status.Recommendations)
+		replicaCountProposal, metricNameProposal, metricStatuses, timestampProposal, err := a.computeReplicasForMetrics(gpa,
+			scale, gpa.Spec.MetricMode.Metrics)
+		statuses = append(statuses, metricStatuses...)
+		if err != nil {
+			failMode(autoscaling.MetricModeName, recorded, err)
+		} else {
+			proposals = append(proposals, modeProposal{autoscaling.MetricModeName, replicaCountProposal,
+				metricNameProposal, timestampProposal})
+		}
+	}
+	if gpa.Spec.CronMetricMode != nil {
+		recorded := len(gpa.Status.Recommendations)
+		cronMetrics := cronMetricsScale.GetCurrentCronMetricSpecs(gpa, scheduleName)
+		replicaCountProposal, metricNameProposal, metricStatuses, timestampProposal, err := a.computeReplicasForCronMetrics(gpa,
+			scale, cronMetrics, scheduleName)
+		statuses = append(statuses, metricStatuses...)
+		if err != nil {
+			failMode(autoscaling.CronMetricModeName, recorded, err)
+		} else {
+			proposals = append(proposals, modeProposal{autoscaling.CronMetricModeName, replicaCountProposal,
+				metricNameProposal, timestampProposal})
+		}
+	}
+	if gpa.Spec.WebhookMode != nil || gpa.Spec.TimeMode != nil || gpa.Spec.EventMode != nil {
+		simpleProposals, err := a.computeReplicasForSimple(gpa, scale, key)
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+		proposals = append(proposals, simpleProposals...)
+	}
+	mergeRecommendations(previousRecommendations, gpa.Status.Recommendations, a.clock.Now())
+
+	selected := selectModeProposal(gpa.Spec.ModeSelectPolicy, proposals)
+	gpa.Status.ModeProposals = make([]autoscaling.ModeProposal, 0, len(proposals))
+	for i, p := range proposals {
+		gpa.Status.ModeProposals = append(gpa.Status.ModeProposals, autoscaling.ModeProposal{
+			Mode:     p.mode,
+			Replicas: p.replicas,
+			Metric:   p.metric,
+			Selected: i == selected,
+		})
+		klog.V(4).Infof("GPA: %v mode: %v, suggested replicas: %v", gpa.Name, p.mode, p.replicas)
+	}
+	if selected < 0 {
+		if len(failures) != 0 {
+			return 0, "", statuses, time.Time{}, fmt.Errorf("no replicas proposed by driven modes, failed modes: %s",
+				strings.Join(failures, "; "))
+		}
+		return 0, "", statuses, time.Time{}, fmt.Errorf("no replicas proposed by driven modes")
+	}
+	// the failed modes are left out, the proposals of the other modes are still combined
+	reason := "ValidMetricFound"
+	message := fmt.Sprintf("the GPA was able to successfully calculate a replica count from %s",
+		proposals[selected].metric)
+	if policy := gpa.Spec.ModeSelectPolicy; policy != nil && *policy != autoscaling.MaxModeSelect &&
+		*policy != autoscaling.MinModeSelect && proposals[selected].mode != string(*policy) {
+		reason = "SelectedModeUnavailable"
+		message = fmt.Sprintf("%s, mode %s proposed no replicas, the highest proposal is selected", message, *policy)
+	}
+	if len(failures) != 0 {
+		if reason == "ValidMetricFound" {
+			reason = "PartialModeFailure"
+		}
+		message = fmt.Sprintf("%s, failed modes: %s", message, strings.Join(failures, "; "))
+	}
+	setCondition(gpa, autoscaling.ScalingActive, v1.ConditionTrue, reason, "%s", message)
+	return proposals[selected].replicas, proposals[selected].metric, statuses, proposals[selected].timestamp, nil
+}
+
+// selectModeProposal returns the index of proposal selected by the mode select policy, -1 if no proposals.
+// If the policy names a mode without proposal, the highest proposal is selected.
+func selectModeProposal(policy *autoscaling.ModeSelectPolicy, proposals []modeProposal) int {
+	selectPolicy := autoscaling.MaxModeSelect
+	if policy != nil {
+		selectPolicy = *policy
+	}
+	selected := -1
+	for i, p := range proposals {
+		if selectPolicy != autoscaling.MaxModeSelect && selectPolicy != autoscaling.MinModeSelect &&
+			p.mode == string(selectPolicy) {
+			return i
+		}
+		if selected < 0 ||
+			(selectPolicy == autoscaling.MinModeSelect && p.replicas < proposals[selected].replicas) ||
+			(selectPolicy != autoscaling.MinModeSelect && p.replicas > proposals[selected].replicas) {
+			selected = i
+		}
+	}
+	return selected
+}
+
+// computeReplicasForSimple computes the desired number of replicas for the webhook, time and event modes
+// listed in the GPA, returning the proposals of modes.
+func (a *GeneralController) computeReplicasForSimple(gpa *autoscaling.GeneralPodAutoscaler,
+	scale *autoscalinginternal.Scale, key string) (proposals []modeProposal, err error) {
 	if scale.Status.Selector == "" {
 		errMsg := "selector is required"
 		a.eventRecorder.Event(gpa, v1.EventTypeWarning, "SelectorRequired", errMsg)
 		setCondition(gpa, autoscaling.ScalingActive, v1.ConditionFalse, "InvalidSelector",
 			"the GPA target's scale is missing a selector")
-		return 0, "", nil, time.Time{}, fmt.Errorf(errMsg)
+		return nil, fmt.Errorf(errMsg)
 	}
 
-	_, err = labels.Parse(scale.Status.Selector)
+	selector, err := labels.Parse(scale.Status.Selector)
 	if err != nil {
 		errMsg := fmt.Sprintf("couldn't convert selector into a corresponding internal selector object: %v", err)
 		a.eventRecorder.Event(gpa, v1.EventTypeWarning, "InvalidSelector", errMsg)
 		setCondition(gpa, autoscaling.ScalingActive, v1.ConditionFalse, "InvalidSelector", errMsg)
-		return 0, "", nil, time.Time{}, fmt.Errorf(errMsg)
+		return nil, fmt.Errorf(errMsg)
 	}
 
 	statusReplicas := scale.Status.Replicas
 
-	replicaCountProposal, modeNameProposal, err := computeDesiredSize(gpa, a.buildScalerChain(gpa), statusReplicas)
+	var errs error
+	scalers := a.buildScalerChain(gpa, key)
+	klog.V(4).Infof("Scaler number of %v: %v", gpa.Name, len(scalers))
+	for _, s := range scalers {
+		if o, ok := s.(scalercore.TargetObserver); ok {
+			o.SetTargetStatus(a.getTargetStatus(gpa.Namespace, scale.Status.Selector, selector))
+		}
+		replicaCountProposal, err := s.GetReplicas(gpa, statusReplicas)
+		mode := scalerModeNames[s.ScalerName()]
+		if ws, ok := s.(*scalercore.WebhookScaler); ok {
+			a.recordWebhookBreaker(gpa, ws.BreakerStatus())
+			if ws.CacheDropped() {
+				// the response is of the webhook mode before it changed
+				removeCondition(gpa, autoscaling.WebhookResponseValid)
+			}
+			cache, used, stale := ws.CachedResponse()
+			a.recordWebhookCache(gpa, cache, used, stale)
+			if called, callErr := ws.LastCall(); called {
+				a.recordWebhookResponse(gpa, callErr)
+			}
+			if bounds, boundsOnly := ws.Bounds(); err == nil && bounds != nil {
+				applyReplicaBounds(gpa, bounds)
+				if boundsOnly {
+					klog.V(4).Infof("GPA: %v webhook overrides replicas limits only", gpa.Name)
+					continue
+				}
+			}
+		}
+		if ms, ok := s.(scalercore.MultiSourceScaler); ok {
+			for _, rec := range ms.Recommendations() {
+				setRecommendation(gpa, recommendationName(mode, rec.Name), rec.Replicas, a.clock.Now(), rec.Err)
+			}
+		} else {
+			setRecommendation(gpa, mode, replicaCountProposal, a.clock.Now(), err)
+		}
+		// the schedule is kept in status of each gpa, the schedule time is only used by the same schedule
+		if cs, ok := s.(*scalercore.CronScaler); ok && len(cs.ScheduleName()) != 0 {
+			gpa.Status.LastCronScheduleName = cs.ScheduleName()
+		}
+		if err != nil {
+			klog.Error(err)
+			setCondition(gpa, autoscaling.ScalingActive, v1.ConditionFalse, fmt.Sprintf("%v failed", s.ScalerName()),
+				fmt.Sprintf("%v failed: %v", s.ScalerName(), err))
+			errs = pkgerrors.Wrap(err, fmt.Sprintf("GPA: %v get replicas error when call %v", gpa.Name, s.ScalerName()))
+			continue
+		}
+		klog.V(4).Infof("GPA: %v scaler: %v, suggested replicas: %v", gpa.Name, s.ScalerName(), replicaCountProposal)
+		proposals = append(proposals, modeProposal{mode, replicaCountProposal, s.ScalerName(), a.clock.Now()})
+	}
+	if errs != nil {
+		return proposals, fmt.Errorf("invalid mode, last error is: %v", errs)
+	}
+	return proposals, nil
+}
+
+// applyReplicaBounds overrides the replicas limits of gpa in the calculation by the limits answered by the
+// webhook, the lower limit is capped by the upper limit. A lower limit of 0 is ignored unless the gpa itself
+// scales to zero, e.g. it is answered before minReplicas changed. gpa must be a copy of the one in the shared
+// cache.
+func applyReplicaBounds(gpa *autoscaling.GeneralPodAutoscaler, bounds *scalercore.ReplicaBounds) {
+	if bounds.MaxReplicas != nil {
+		gpa.Spec.MaxReplicas = *bounds.MaxReplicas
+	}
+	scaledToZero := gpa.Spec.MinReplicas != nil && *gpa.Spec.MinReplicas == 0
+	if bounds.MinReplicas != nil && (*bounds.MinReplicas > 0 || scaledToZero) {
+		min := *bounds.MinReplicas
+		gpa.Spec.MinReplicas = &min
+	}
+	if gpa.Spec.MinReplicas != nil && *gpa.Spec.MinReplicas > gpa.Spec.MaxReplicas {
+		min := gpa.Spec.MaxReplicas
+		gpa.Spec.MinReplicas = &min
+	}
+	klog.V(4).Infof("GPA: %v replicas limits overridden by webhook, max: %v", gpa.Name, gpa.Spec.MaxReplicas)
+}
+
+// recordWebhookResponse sets the condition of webhook response by the error of webhook scaler, a rejection
+// is also recorded as an event with the reason given by the server
+func (a *GeneralController) recordWebhookResponse(gpa *autoscaling.GeneralPodAutoscaler, err error) {
+	var rejected *scalercore.WebhookRejectedError
+	switch {
+	case err == nil:
+		setCondition(gpa, autoscaling.WebhookResponseValid, v1.ConditionTrue, "ValidResponse",
+			"the webhook returned a valid response")
+	case pkgerrors.As(err, &rejected):
+		setCondition(gpa, autoscaling.WebhookResponseValid, v1.ConditionFalse, rejected.Reason, "%v",
+			rejected.Message)
+		a.eventRecorder.Eventf(gpa, v1.EventTypeWarning, "WebhookRejected", "%v: %v", rejected.Reason,
+			rejected.Message)
+	}
+}
+
+// recordWebhookBreaker sets the state of circuit breaker of webhook in status, the breaker opening and
+// closing are recorded as events
+func (a *GeneralController) recordWebhookBreaker(gpa *autoscaling.GeneralPodAutoscaler,
+	breaker *autoscaling.WebhookBreakerStatus) {
+	wasOpen := gpa.Status.WebhookBreaker != nil && gpa.Status.WebhookBreaker.State == autoscaling.BreakerOpen
+	gpa.Status.WebhookBreaker = breaker
+	if breaker == nil {
+		return
+	}
+	switch {
+	case breaker.State == autoscaling.BreakerOpen && !wasOpen:
+		a.eventRecorder.Eventf(gpa, v1.EventTypeWarning, "WebhookCircuitOpen",
+			"circuit breaker of webhook opened after %v consecutive failures: %v", breaker.ConsecutiveFailures,
+			breaker.LastError)
+	case breaker.State == autoscaling.BreakerClosed && wasOpen:
+		a.eventRecorder.Event(gpa, v1.EventTypeNormal, "WebhookCircuitClosed", "circuit breaker of webhook closed")
+	}
+}
+
+// recordWebhookCache sets the cached answer of webhook in status, and the condition of whether the answer
+// is used after it expired. The condition is removed if the replicas is not answered by the cache.
+func (a *GeneralController) recordWebhookCache(gpa *autoscaling.GeneralPodAutoscaler,
+	cache *autoscaling.WebhookCachedResponse, used, stale bool) {
+	gpa.Status.WebhookCache = cache
+	if !used {
+		removeCondition(gpa, autoscaling.WebhookResponseStale)
+		return
+	}
+	if stale {
+		setCondition(gpa, autoscaling.WebhookResponseStale, v1.ConditionTrue, "RefreshFailed",
+			"the webhook answer expired at %v could not be refreshed", cache.ExpirationTime)
+		return
+	}
+	setCondition(gpa, autoscaling.WebhookResponseStale, v1.ConditionFalse, "FreshResponse",
+		"the webhook answer in use is not expired")
+}
+
+// getTargetStatus returns the selector and the pod counts of scale target, the pod counts are nil if
+// pods can not be listed
+func (a *GeneralController) getTargetStatus(namespace, selectorStr string, selector labels.Selector) scalercore.TargetStatus {
+	status := scalercore.TargetStatus{Selector: selectorStr}
+	pods, err := a.podLister.Pods(namespace).List(selector)
 	if err != nil {
-		setCondition(gpa, autoscaling.ScalingActive, v1.ConditionFalse, fmt.Sprintf("%v failed", modeNameProposal),
-			fmt.Sprintf("%v failed: %v",
-				modeNameProposal, err))
-		return 0, "", statuses, time.Time{}, fmt.Errorf("invalid mode %v, first error is: %v", modeNameProposal, err)
+		klog.Errorf("List pods of %v in %v failed: %v", selectorStr, namespace, err)
+		return status
 	}
-	replicas = replicaCountProposal
-	setCondition(gpa, autoscaling.ScalingActive, v1.ConditionTrue, "ValidMetricFound", "the GPA was able to successfully calculate a replica count from %s", metric)
-	timestamp = time.Now()
-	return replicas, modeNameProposal, statuses, timestamp, nil
+	var ready int32
+	for _, pod := range pods {
+		if IsPodReady(pod) {
+			ready++
+		}
+	}
+	total := int32(len(pods))
+	status.ReadyPods = &ready
+	status.TotalPods = &total
+	return status
 }
 
-// buildScalerChain build scaler chain for gpa scaler
-func (a *GeneralController) buildScalerChain(gpa *autoscaling.GeneralPodAutoscaler) []scalercore.Scaler {
+// buildScalerChain build scaler chain for gpa scaler, the long run scalers in chain are
+// replaced by the running ones of the gpa.
+func (a *GeneralController) buildScalerChain(gpa *autoscaling.GeneralPodAutoscaler, key string) []scalercore.Scaler {
 	var scalerChain []scalercore.Scaler
 	if gpa.Spec.WebhookMode != nil {
-		scalerChain = append(scalerChain, scalercore.NewWebhookScaler(gpa.Spec.WebhookMode))
+		scalerChain = append(scalerChain, scalercore.NewWebhookScaler(gpa.Spec.WebhookMode, a.transports, a.batcher,
+			a.secretNamespacer, key, a.clock))
 	}
 	if gpa.Spec.TimeMode != nil {
-		scalerChain = append(scalerChain, scalercore.NewCronScaler(gpa.Spec.TimeMode.TimeRanges))
+		scalerChain = append(scalerChain, scalercore.NewCronScaler(gpa.Spec.TimeMode.TimeRanges, gpa.Spec.TimeMode.TimeZone, a.clock))
+	}
+	if gpa.Spec.EventMode != nil {
+		scalerChain = append(scalerChain, scalercore.NewEventScaler(gpa.Spec.EventMode.Triggers))
 	}
-	return scalerChain
+	return a.runLongRunScalers(gpa, key, scalerChain)
 }
 
 // Computes the desired number of replicas for a specific gpa and metric specification,
@@ -563,12 +890,18 @@ func (a *GeneralController) reconcileKey(key string) (deleted bool, err error) {
 		delete(a.recommendations, key)
 		delete(a.scaleUpEvents, key)
 		delete(a.scaleDownEvents, key)
+		a.forgetDryRunScaleEvents(key)
+		a.stopLongRunScalers(key)
+		a.transports.Release(key)
+		a.scheduleTransitions.Release(key)
 		return true, nil
 	}
 	if err != nil {
 		return false, err
 	}
-	return false, a.reconcileAutoscaler(gpa, key)
+	// the limits in spec are overridden in the calculation by cron metric and webhook, work on a copy so
+	// that the shared informer cache is never mutated
+	return false, a.reconcileAutoscaler(gpa.DeepCopy(), key)
 }
 
 // computeStatusForObjectMetric computes the desired number of replicas for the specified metric of type ObjectMetricSourceType.
@@ -778,7 +1111,7 @@ func (a *GeneralController) recordInitialRecommendation(currentReplicas int32, k
 	defer a.recommendationsLock.Unlock()
 
 	if a.recommendations[key] == nil {
-		a.recommendations[key] = []timestampedRecommendation{{currentReplicas, time.Now()}}
+		a.recommendations[key] = []timestampedRecommendation{{currentReplicas, a.clock.Now()}}
 	}
 }
 
@@ -841,7 +1174,22 @@ func (a *GeneralController) reconcileAutoscaler(gpa *autoscaling.GeneralPodAutos
 	setCondition(gpa, autoscaling.AbleToScale, v1.ConditionTrue, "SucceededGetScale",
 		"the GPA controller was able to get the target's current scale")
 	currentReplicas := scale.Spec.Replicas
+	a.restoreScalingHistory(gpa, key)
 	a.recordInitialRecommendation(currentReplicas, key)
+	if !gpa.Spec.DryRun {
+		gpa.Status.DryRun = nil
+		a.forgetDryRunScaleEvents(key)
+	}
+	if gpa.Spec.WebhookMode == nil {
+		a.transports.Release(key)
+		gpa.Status.WebhookBreaker = nil
+		gpa.Status.WebhookCache = nil
+		removeCondition(gpa, autoscaling.WebhookResponseValid)
+		removeCondition(gpa, autoscaling.WebhookResponseStale)
+	}
+	if gpa.Spec.MinReplicas == nil || *gpa.Spec.MinReplicas != 0 {
+		gpa.Status.IdleSince = nil
+	}
 
 	var (
 		metricStatuses        []autoscaling.MetricStatus
@@ -857,11 +1205,17 @@ func (a *GeneralController) reconcileAutoscaler(gpa *autoscaling.GeneralPodAutos
 	var scheduleName string
 	var cronMetricsScale *scalercore.CronMetricsScaler
 	if gpa.Spec.CronMetricMode != nil {
-		cronMetricsScale = scalercore.NewCronMetricsScaler(gpa.Spec.CronMetricMode.CronMetrics)
-		max, min, scheduleName = cronMetricsScale.GetCurrentMaxAndMinReplicas(gpa)
+		cronMetricsScale = scalercore.NewCronMetricsScaler(gpa.Spec.CronMetricMode.CronMetrics, gpa.Spec.CronMetricMode.TimeZone,
+			a.calendarLister, a.clock)
+		gpa.Status.CronSchedule = cronMetricsScale.GetCronSchedule(gpa, a.scheduleTransitions, key)
+		max, min, scheduleName = gpa.Status.CronSchedule.MaxReplicas, gpa.Status.CronSchedule.MinReplicas,
+			gpa.Status.CronSchedule.Schedule
 		klog.Infof("current cron schedule: %s, max: %v, min: %v", scheduleName, max, min)
 		gpa.Spec.MinReplicas = &min
 		gpa.Spec.MaxReplicas = max
+	} else {
+		gpa.Status.CronSchedule = nil
+		a.scheduleTransitions.Release(key)
 	}
 	if gpa.Spec.MinReplicas != nil {
 		minReplicas = *gpa.Spec.MinReplicas
@@ -888,17 +1242,11 @@ func (a *GeneralController) reconcileAutoscaler(gpa *autoscaling.GeneralPodAutos
 		if isEmpty(gpa.Spec.AutoScalingDrivenMode) {
 			return nil
 		}
-		switch {
-		case gpa.Spec.MetricMode != nil:
-			metricDesiredReplicas, metricName, metricStatuses, metricTimestamp, err = a.computeReplicasForMetrics(gpa,
-				scale, gpa.Spec.MetricMode.Metrics)
-		case gpa.Spec.CronMetricMode != nil:
-			CronMetrics := cronMetricsScale.GetCurrentCronMetricSpecs(gpa, scheduleName)
-			metricDesiredReplicas, metricName, metricStatuses, metricTimestamp, err = a.computeReplicasForCronMetrics(gpa,
-				scale, CronMetrics, scheduleName)
-		default:
-			metricDesiredReplicas, metricName, metricStatuses, metricTimestamp, err = a.computeReplicasForSimple(gpa,
-				scale)
+		metricDesiredReplicas, metricName, metricStatuses, metricTimestamp, err = a.computeReplicasForModes(gpa,
+			scale, key, cronMetricsScale, scheduleName)
+		// the limits may be overridden by the webhook
+		if gpa.Spec.MinReplicas != nil {
+			minReplicas = *gpa.Spec.MinReplicas
 		}
 		if err != nil {
 			a.setCurrentReplicasInStatus(gpa, currentReplicas)
@@ -908,6 +1256,9 @@ func (a *GeneralController) reconcileAutoscaler(gpa *autoscaling.GeneralPodAutos
 			a.eventRecorder.Event(gpa, v1.EventTypeWarning, "FailedComputeMetricsReplicas", err.Error())
 			return fmt.Errorf("failed to compute desired number of replicas based on listed metrics for %s: %v", reference, err)
 		}
+		if minReplicas == 0 {
+			minReplicas = a.idleMinReplicas(gpa, metricDesiredReplicas)
+		}
 		//Record event when the metricDesiredReplicas is greater than gpa.Spec.MaxReplicas
 		if metricDesiredReplicas > gpa.Spec.MaxReplicas {
 			a.eventRecorder.Eventf(gpa, v1.EventTypeWarning, "FailedRescale", "DesiredReplicas:%v cannot exceed the MaxReplicas: %v", metricDesiredReplicas, gpa.Spec.MaxReplicas)
@@ -931,18 +1282,32 @@ func (a *GeneralController) reconcileAutoscaler(gpa *autoscaling.GeneralPodAutos
 			klog.V(4).Infof("%s start behaviors", gpa.Name)
 			desiredReplicas = a.normalizeDesiredReplicasWithBehaviors(gpa, key, currentReplicas, desiredReplicas, minReplicas)
 		}
+		if currentReplicas == 0 && metricDesiredReplicas > 0 {
+			desiredReplicas = activationReplicas(gpa, desiredReplicas)
+			rescaleReason = fmt.Sprintf("%s, activated from zero", rescaleReason)
+		} else if desiredReplicas == 0 && gpa.Status.IdleSince != nil {
+			rescaleReason = fmt.Sprintf("Idle since %s", gpa.Status.IdleSince.UTC().Format(time.RFC3339))
+		}
 		klog.V(4).Infof("desire: %v, current: %v, min: %v, max: %v",
 			desiredReplicas, currentReplicas, minReplicas, gpa.Spec.MaxReplicas)
 		rescale = desiredReplicas != currentReplicas
 	}
+	desiredReplicas, rescale, rescaleReason = a.applyOverride(gpa, currentReplicas, desiredReplicas, rescale,
+		rescaleReason)
 
 	if rescale {
-		//if desiredReplicas is 0, skip to update replicas and send event
-		if desiredReplicas == 0 {
+		//if desiredReplicas is 0 and the target can not be scaled to zero, skip to update replicas and send event
+		if desiredReplicas == 0 && minReplicas != 0 {
 			a.eventRecorder.Eventf(gpa, v1.EventTypeWarning, "FailedRescale",
 				"desiredReplicas: %d; reason: %s; skip modify replicas", desiredReplicas, rescaleReason)
 			return fmt.Errorf("failed to rescale %s: desiredReplicas=0 skip modify replcias", reference)
 		}
+		if gpa.Spec.DryRun {
+			a.recordDryRunRescale(gpa, key, scale.Status.Selector, currentReplicas, desiredReplicas, rescaleReason)
+			a.recordScalingHistory(gpa, key)
+			a.setStatus(gpa, currentReplicas, desiredReplicas, metricStatuses, false)
+			return a.updateStatusIfNeeded(gpaStatusOriginal, gpa)
+		}
 		scale.Spec.Replicas = desiredReplicas
 		klog.Infof("rescale for %s, scale info: %v", reference, scale)
 		_, err = a.scaleNamespacer.Scales(gpa.Namespace).Update(targetGR, scale)
@@ -957,27 +1322,7 @@ func (a *GeneralController) reconcileAutoscaler(gpa *autoscaling.GeneralPodAutos
 			}
 			return fmt.Errorf("failed to rescale %s: %v", reference, err)
 		}
-		// calculatePodResources
-		var (
-			cpuRequests, cpuLimits, memRequests, memLimits float32
-			_err                                           error
-		)
-		cpuRequests, cpuLimits, memRequests, memLimits, _err = a.calculateOnePodResources(gpa.Namespace, scale.Status.Selector)
-		if _err != nil {
-			klog.Errorf("calculateOnePodResources error:%v", _err)
-		}
-		changeReplicas := float32(desiredReplicas - currentReplicas)
-		scaleEvt := ScaleEvent{
-			OldReplicas:          currentReplicas,
-			NewReplicas:          desiredReplicas,
-			MinReplicas:          *gpa.Spec.MinReplicas,
-			MaxReplicas:          gpa.Spec.MaxReplicas,
-			CpuRequestsOfChanges: changeReplicas * cpuRequests,
-			CpuLimitsOfChanges:   changeReplicas * cpuLimits,
-			MemRequestsOfChanges: changeReplicas * memRequests,
-			MemLimitsOfChanges:   changeReplicas * memLimits,
-			Reason:               rescaleReason,
-		}
+		scaleEvt := a.newScaleEvent(gpa, scale.Status.Selector, currentReplicas, desiredReplicas, rescaleReason)
 		setCondition(gpa, autoscaling.AbleToScale, v1.ConditionTrue,
 			"SucceededRescale", "the GPA controller was able to update the target scale to %d", desiredReplicas)
 
@@ -996,10 +1341,79 @@ func (a *GeneralController) reconcileAutoscaler(gpa *autoscaling.GeneralPodAutos
 			reference, desiredReplicas, gpa.Status.LastScaleTime)
 		desiredReplicas = currentReplicas
 	}
+	a.recordScalingHistory(gpa, key)
 	a.setStatus(gpa, currentReplicas, desiredReplicas, metricStatuses, rescale)
 	return a.updateStatusIfNeeded(gpaStatusOriginal, gpa)
 }
 
+// newScaleEvent returns the ScaleEvent of rescale from currentReplicas to desiredReplicas
+func (a *GeneralController) newScaleEvent(gpa *autoscaling.GeneralPodAutoscaler, selector string,
+	currentReplicas, desiredReplicas int32, reason string) ScaleEvent {
+	// calculatePodResources
+	cpuRequests, cpuLimits, memRequests, memLimits, err := a.calculateOnePodResources(gpa.Namespace, selector)
+	if err != nil {
+		klog.Errorf("calculateOnePodResources error:%v", err)
+	}
+	changeReplicas := float32(desiredReplicas - currentReplicas)
+	return ScaleEvent{
+		OldReplicas:          currentReplicas,
+		NewReplicas:          desiredReplicas,
+		MinReplicas:          *gpa.Spec.MinReplicas,
+		MaxReplicas:          gpa.Spec.MaxReplicas,
+		CpuRequestsOfChanges: changeReplicas * cpuRequests,
+		CpuLimitsOfChanges:   changeReplicas * cpuLimits,
+		MemRequestsOfChanges: changeReplicas * memRequests,
+		MemLimitsOfChanges:   changeReplicas * memLimits,
+		Reason:               reason,
+	}
+}
+
+// recordDryRunRescale records the rescale decided in dry run in status and events instead of updating
+// the scale of target. The scale event is simulated apart from the ones of target, so the rates of behaviors
+// are limited as if the target were scaled. The decision is only recorded again if it is changed.
+func (a *GeneralController) recordDryRunRescale(gpa *autoscaling.GeneralPodAutoscaler, key, selector string,
+	currentReplicas, desiredReplicas int32, reason string) {
+	a.storeScaleEvent(gpa.Spec.Behavior, dryRunEventsKey(key), currentReplicas, desiredReplicas)
+	setCondition(gpa, autoscaling.AbleToScale, v1.ConditionTrue, "DryRun",
+		"the GPA controller would update the target scale to %d, but dry run is enabled", desiredReplicas)
+	if previous := gpa.Status.DryRun; previous != nil && previous.CurrentReplicas == currentReplicas &&
+		previous.DesiredReplicas == desiredReplicas && previous.Reason == reason {
+		return
+	}
+	gpa.Status.DryRun = &autoscaling.DryRunDecision{
+		CurrentReplicas: currentReplicas,
+		DesiredReplicas: desiredReplicas,
+		Reason:          reason,
+		DecisionTime:    metav1.NewTime(a.clock.Now()),
+	}
+
+	scaleEvt := a.newScaleEvent(gpa, selector, currentReplicas, desiredReplicas, reason)
+	bytes, err := json.Marshal(scaleEvt)
+	if err != nil {
+		a.eventRecorder.Eventf(gpa, v1.EventTypeNormal, "DryRunRescale",
+			"old size: %d; new size: %d; min size: %d; max size: %d; reason: %s", currentReplicas, desiredReplicas, *gpa.Spec.MinReplicas, gpa.Spec.MaxReplicas, reason)
+	} else {
+		a.eventRecorder.Eventf(gpa, v1.EventTypeNormal, "DryRunRescale", string(bytes))
+	}
+	klog.Infof("Dry run rescale of %s, old size: %d, new size: %d, reason: %s",
+		gpa.Name, currentReplicas, desiredReplicas, reason)
+}
+
+// dryRunEventsKey returns the key of the scale events simulated in dry run for the gpa of key
+func dryRunEventsKey(key string) string {
+	return key + "#dryRun"
+}
+
+// forgetDryRunScaleEvents drops the scale events simulated in dry run for the gpa of key
+func (a *GeneralController) forgetDryRunScaleEvents(key string) {
+	a.scaleUpEventsLock.Lock()
+	delete(a.scaleUpEvents, dryRunEventsKey(key))
+	a.scaleUpEventsLock.Unlock()
+	a.scaleDownEventsLock.Lock()
+	delete(a.scaleDownEvents, dryRunEventsKey(key))
+	a.scaleDownEventsLock.Unlock()
+}
+
 //calculateOnePodResources
 func (a *GeneralController) calculateOnePodResources(namespace, selectorStr string) (float32, float32, float32, float32, error) {
 	selector, err := labels.Parse(selectorStr)
@@ -1073,7 +1487,7 @@ func (a *GeneralController) stabilizeRecommendation(key string, prenormalizedDes
 	maxRecommendation := prenormalizedDesiredReplicas
 	foundOldSample := false
 	oldSampleIndex := 0
-	cutoff := time.Now().Add(-a.downscaleStabilisationWindow)
+	cutoff := a.clock.Now().Add(-a.downscaleStabilisationWindow)
 	for i, rec := range a.recommendations[key] {
 		if rec.timestamp.Before(cutoff) {
 			foundOldSample = true
@@ -1084,10 +1498,10 @@ func (a *GeneralController) stabilizeRecommendation(key string, prenormalizedDes
 	}
 	if foundOldSample {
 		a.recommendations[key][oldSampleIndex] = timestampedRecommendation{
-			prenormalizedDesiredReplicas, time.Now()}
+			prenormalizedDesiredReplicas, a.clock.Now()}
 	} else {
 		a.recommendations[key] = append(a.recommendations[key], timestampedRecommendation{
-			prenormalizedDesiredReplicas, time.Now()})
+			prenormalizedDesiredReplicas, a.clock.Now()})
 	}
 	return maxRecommendation
 }
@@ -1154,6 +1568,10 @@ func (a *GeneralController) normalizeDesiredReplicasWithBehaviors(gpa *autoscali
 		setCondition(gpa, autoscaling.AbleToScale, v1.ConditionTrue, "ReadyForNewScale",
 			"recommended size matches current size")
 	}
+	if gpa.Spec.DryRun {
+		// the rates are limited by the scale events simulated in dry run
+		normalizationArg.Key = dryRunEventsKey(key)
+	}
 	desiredReplicas, reason, message := a.convertDesiredReplicasWithBehaviorRate(normalizationArg)
 	if desiredReplicas == stabilizedRecommendation {
 		setCondition(gpa, autoscaling.ScalingLimited, v1.ConditionFalse, reason, message)
@@ -1173,9 +1591,9 @@ func (a *GeneralController) maybeInitScaleDownStabilizationWindow(gpa *autoscali
 }
 
 // getReplicasChangePerPeriod function find all the replica changes per period
-func getReplicasChangePerPeriod(periodSeconds int32, scaleEvents []timestampedScaleEvent) int32 {
+func getReplicasChangePerPeriod(periodSeconds int32, scaleEvents []timestampedScaleEvent, now time.Time) int32 {
 	period := time.Second * time.Duration(periodSeconds)
-	cutoff := time.Now().Add(-period)
+	cutoff := now.Add(-period)
 	var replicas int32
 	for _, rec := range scaleEvents {
 		if rec.timestamp.After(cutoff) {
@@ -1212,7 +1630,7 @@ func (a *GeneralController) storeScaleEvent(behavior *autoscaling.GeneralPodAuto
 		defer a.scaleUpEventsLock.Unlock()
 
 		longestPolicyPeriod = getLongestPolicyPeriod(behavior.ScaleUp)
-		markScaleEventsOutdated(a.scaleUpEvents[key], longestPolicyPeriod)
+		markScaleEventsOutdated(a.scaleUpEvents[key], longestPolicyPeriod, a.clock.Now())
 		replicaChange := newReplicas - prevReplicas
 		for i, event := range a.scaleUpEvents[key] {
 			if event.outdated {
@@ -1220,7 +1638,7 @@ func (a *GeneralController) storeScaleEvent(behavior *autoscaling.GeneralPodAuto
 				oldSampleIndex = i
 			}
 		}
-		newEvent := timestampedScaleEvent{replicaChange, time.Now(), false}
+		newEvent := timestampedScaleEvent{replicaChange, a.clock.Now(), false}
 		if foundOldSample {
 			a.scaleUpEvents[key][oldSampleIndex] = newEvent
 		} else {
@@ -1231,7 +1649,7 @@ func (a *GeneralController) storeScaleEvent(behavior *autoscaling.GeneralPodAuto
 		defer a.scaleDownEventsLock.Unlock()
 
 		longestPolicyPeriod = getLongestPolicyPeriod(behavior.ScaleDown)
-		markScaleEventsOutdated(a.scaleDownEvents[key], longestPolicyPeriod)
+		markScaleEventsOutdated(a.scaleDownEvents[key], longestPolicyPeriod, a.clock.Now())
 		replicaChange := prevReplicas - newReplicas
 		for i, event := range a.scaleDownEvents[key] {
 			if event.outdated {
@@ -1239,7 +1657,7 @@ func (a *GeneralController) storeScaleEvent(behavior *autoscaling.GeneralPodAuto
 				oldSampleIndex = i
 			}
 		}
-		newEvent := timestampedScaleEvent{replicaChange, time.Now(), false}
+		newEvent := timestampedScaleEvent{replicaChange, a.clock.Now(), false}
 		if foundOldSample {
 			a.scaleDownEvents[key][oldSampleIndex] = newEvent
 		} else {
@@ -1273,9 +1691,9 @@ func (a *GeneralController) stabilizeRecommendationWithBehaviors(args Normalizat
 	}
 
 	maxDelaySeconds := max(*args.ScaleUpBehavior.StabilizationWindowSeconds, *args.ScaleDownBehavior.StabilizationWindowSeconds)
-	obsoleteCutoff := time.Now().Add(-time.Second * time.Duration(maxDelaySeconds))
+	obsoleteCutoff := a.clock.Now().Add(-time.Second * time.Duration(maxDelaySeconds))
 
-	cutoff := time.Now().Add(-time.Second * time.Duration(scaleDelaySeconds))
+	cutoff := a.clock.Now().Add(-time.Second * time.Duration(scaleDelaySeconds))
 	for i, rec := range a.recommendations[args.Key] {
 		if rec.timestamp.After(cutoff) {
 			recommendation = betterRecommendation(rec.recommendation, recommendation)
@@ -1286,9 +1704,9 @@ func (a *GeneralController) stabilizeRecommendationWithBehaviors(args Normalizat
 		}
 	}
 	if foundOldSample {
-		a.recommendations[args.Key][oldSampleIndex] = timestampedRecommendation{args.DesiredReplicas, time.Now()}
+		a.recommendations[args.Key][oldSampleIndex] = timestampedRecommendation{args.DesiredReplicas, a.clock.Now()}
 	} else {
-		a.recommendations[args.Key] = append(a.recommendations[args.Key], timestampedRecommendation{args.DesiredReplicas, time.Now()})
+		a.recommendations[args.Key] = append(a.recommendations[args.Key], timestampedRecommendation{args.DesiredReplicas, a.clock.Now()})
 	}
 	return recommendation, reason, message
 }
@@ -1302,7 +1720,7 @@ func (a *GeneralController) convertDesiredReplicasWithBehaviorRate(args Normaliz
 		defer a.scaleUpEventsLock.Unlock()
 
 		scaleUpLimit := calculateScaleUpLimitWithScalingRules(args.CurrentReplicas,
-			a.scaleUpEvents[args.Key], args.ScaleUpBehavior)
+			a.scaleUpEvents[args.Key], args.ScaleUpBehavior, a.clock.Now())
 		if scaleUpLimit < args.CurrentReplicas {
 			// We shouldn't scale up further until the scaleUpEvents will be cleaned up
 			scaleUpLimit = args.CurrentReplicas
@@ -1324,7 +1742,7 @@ func (a *GeneralController) convertDesiredReplicasWithBehaviorRate(args Normaliz
 		defer a.scaleDownEventsLock.Unlock()
 
 		scaleDownLimit := calculateScaleDownLimitWithBehaviors(args.CurrentReplicas,
-			a.scaleDownEvents[args.Key], args.ScaleDownBehavior)
+			a.scaleDownEvents[args.Key], args.ScaleDownBehavior, a.clock.Now())
 		if scaleDownLimit > args.CurrentReplicas {
 			// We shouldn't scale down further until the scaleDownEvents will be cleaned up
 			scaleDownLimit = args.CurrentReplicas
@@ -1345,32 +1763,6 @@ func (a *GeneralController) convertDesiredReplicasWithBehaviorRate(args Normaliz
 	return args.DesiredReplicas, "DesiredWithinRange", "the desired count is within the acceptable range"
 }
 
-// computeDesiredSize computes the new desired size of the given fleet
-func computeDesiredSize(gpa *autoscaling.GeneralPodAutoscaler,
-	scalers []scalercore.Scaler, currentReplicas int32) (int32, string, error) {
-	var (
-		replicas int32
-		errs     error
-		name     string
-	)
-	klog.V(4).Infof("Scaler number of %v: %v", gpa.Name, len(scalers))
-	for _, s := range scalers {
-		chainReplicas, err := s.GetReplicas(gpa, currentReplicas)
-		if err != nil {
-			klog.Error(err)
-			errs = pkgerrors.Wrap(err, fmt.Sprintf("GPA: %v get replicas error when call %v", gpa.Name, s.ScalerName()))
-			continue
-		}
-		klog.V(4).Infof("GPA: %v scaler: %v, suggested replicas: %v", gpa.Name, s.ScalerName(), chainReplicas)
-		if chainReplicas > replicas {
-			replicas = chainReplicas
-			name = s.ScalerName()
-		}
-	}
-
-	return replicas, name, errs
-}
-
 // convertDesiredReplicas performs the actual normalization,
 // without depending on `GeneralController` or `GeneralPodAutoscaler`
 func convertDesiredReplicasWithRules(currentReplicas, desiredReplicas,
@@ -1414,9 +1806,9 @@ func calculateScaleUpLimit(currentReplicas int32) int32 {
 }
 
 // markScaleEventsOutdated set 'outdated=true' flag for all scale events that are not used by any GPA object
-func markScaleEventsOutdated(scaleEvents []timestampedScaleEvent, longestPolicyPeriod int32) {
+func markScaleEventsOutdated(scaleEvents []timestampedScaleEvent, longestPolicyPeriod int32, now time.Time) {
 	period := time.Second * time.Duration(longestPolicyPeriod)
-	cutoff := time.Now().Add(-period)
+	cutoff := now.Add(-period)
 	for i, event := range scaleEvents {
 		if event.timestamp.Before(cutoff) {
 			// outdated scale event are marked for later reuse
@@ -1438,7 +1830,7 @@ func getLongestPolicyPeriod(scalingRules *autoscaling.GPAScalingRules) int32 {
 // calculateScaleUpLimitWithScalingRules returns the maximum number of pods
 // that could be added for the given GPAScalingRules
 func calculateScaleUpLimitWithScalingRules(currentReplicas int32, scaleEvents []timestampedScaleEvent,
-	scalingRules *autoscaling.GPAScalingRules) int32 {
+	scalingRules *autoscaling.GPAScalingRules, now time.Time) int32 {
 	var result int32
 	var proposed int32
 	var selectPolicyFn func(int32, int32) int32
@@ -1450,7 +1842,7 @@ func calculateScaleUpLimitWithScalingRules(currentReplicas int32, scaleEvents []
 		selectPolicyFn = max // Use the default policy otherwise to produce a highest possible change
 	}
 	for _, policy := range scalingRules.Policies {
-		replicasAddedInCurrentPeriod := getReplicasChangePerPeriod(policy.PeriodSeconds, scaleEvents)
+		replicasAddedInCurrentPeriod := getReplicasChangePerPeriod(policy.PeriodSeconds, scaleEvents, now)
 		periodStartReplicas := currentReplicas - replicasAddedInCurrentPeriod
 		if policy.Type == autoscaling.PodsScalingPolicy {
 			proposed = int32(periodStartReplicas + policy.Value)
@@ -1467,7 +1859,7 @@ func calculateScaleUpLimitWithScalingRules(currentReplicas int32, scaleEvents []
 // calculateScaleDownLimitWithBehavior returns the maximum number of pods
 // that could be deleted for the given GPAScalingRules
 func calculateScaleDownLimitWithBehaviors(currentReplicas int32, scaleEvents []timestampedScaleEvent,
-	scalingRules *autoscaling.GPAScalingRules) int32 {
+	scalingRules *autoscaling.GPAScalingRules, now time.Time) int32 {
 	var result int32 = math.MaxInt32
 	var proposed int32
 	var selectPolicyFn func(int32, int32) int32
@@ -1479,7 +1871,7 @@ func calculateScaleDownLimitWithBehaviors(currentReplicas int32, scaleEvents []t
 		selectPolicyFn = min // Use the default policy otherwise to produce a highest possible change
 	}
 	for _, policy := range scalingRules.Policies {
-		replicasDeletedInCurrentPeriod := getReplicasChangePerPeriod(policy.PeriodSeconds, scaleEvents)
+		replicasDeletedInCurrentPeriod := getReplicasChangePerPeriod(policy.PeriodSeconds, scaleEvents, now)
 		periodStartReplicas := currentReplicas + replicasDeletedInCurrentPeriod
 		if policy.Type == autoscaling.PodsScalingPolicy {
 			proposed = periodStartReplicas - policy.Value
@@ -1531,13 +1923,22 @@ func (a *GeneralController) setCurrentReplicasInStatus(gpa *autoscaling.GeneralP
 func (a *GeneralController) setStatus(gpa *autoscaling.GeneralPodAutoscaler, currentReplicas,
 	desiredReplicas int32, metricStatuses []autoscaling.MetricStatus, rescale bool) {
 	gpa.Status = autoscaling.GeneralPodAutoscalerStatus{
-		CurrentReplicas: currentReplicas,
-		DesiredReplicas: desiredReplicas,
-		LastScaleTime:   gpa.Status.LastScaleTime,
-		CurrentMetrics:  metricStatuses,
-		Conditions:      gpa.Status.Conditions,
-	}
-	now := metav1.NewTime(time.Now())
+		CurrentReplicas:      currentReplicas,
+		DesiredReplicas:      desiredReplicas,
+		LastScaleTime:        gpa.Status.LastScaleTime,
+		CurrentMetrics:       metricStatuses,
+		Conditions:           gpa.Status.Conditions,
+		LastCronScheduleName: gpa.Status.LastCronScheduleName,
+		ModeProposals:        gpa.Status.ModeProposals,
+		Recommendations:      gpa.Status.Recommendations,
+		CronSchedule:         gpa.Status.CronSchedule,
+		WebhookBreaker:       gpa.Status.WebhookBreaker,
+		WebhookCache:         gpa.Status.WebhookCache,
+		ScalingHistory:       gpa.Status.ScalingHistory,
+		DryRun:               gpa.Status.DryRun,
+		IdleSince:            gpa.Status.IdleSince,
+	}
+	now := metav1.NewTime(a.clock.Now())
 	if rescale {
 		if gpa.Spec.TimeMode != nil || gpa.Spec.CronMetricMode != nil {
 			gpa.Status.LastCronScheduleTime = &now
@@ -1557,6 +1958,12 @@ func (a *GeneralController) updateStatusIfNeeded(oldStatus *autoscaling.GeneralP
 	if err == nil {
 		return nil
 	}
+	// the limits overridden in the calculation are not written back to spec
+	if gpa, getErr := a.gpaLister.GeneralPodAutoscalers(newGPA.Namespace).Get(newGPA.Name); getErr == nil {
+		gpa = gpa.DeepCopy()
+		gpa.Status = newGPA.Status
+		newGPA = gpa
+	}
 	_, err = a.gpaNamespacer.GeneralPodAutoscalers(newGPA.Namespace).Update(newGPA)
 	return err
 }
@@ -1592,6 +1999,16 @@ func setCondition(gpa *autoscaling.GeneralPodAutoscaler, conditionType autoscali
 	gpa.Status.Conditions = setConditionInList(gpa.Status.Conditions, conditionType, status, reason, message, args...)
 }
 
+// removeCondition removes the specific condition type from the given GPA if it is present.
+func removeCondition(gpa *autoscaling.GeneralPodAutoscaler, conditionType autoscaling.GeneralPodAutoscalerConditionType) {
+	for i, condition := range gpa.Status.Conditions {
+		if condition.Type == conditionType {
+			gpa.Status.Conditions = append(gpa.Status.Conditions[:i], gpa.Status.Conditions[i+1:]...)
+			return
+		}
+	}
+}
+
 // setConditionInList sets the specific condition type on the given GPA to the specified value with the given
 // reason and message.  The message and args are treated like a format string.  The condition will be added if
 // it is not present.  The new list will be returned.
@@ -1626,6 +2043,65 @@ func setConditionInList(inputList []autoscaling.GeneralPodAutoscalerCondition,
 	return resList
 }
 
+// setRecommendation appends the recommendation of source to the status of gpa
+func setRecommendation(gpa *autoscaling.GeneralPodAutoscaler, name string, replicas int32,
+	timestamp time.Time, err error) {
+	recommendation := autoscaling.Recommendation{Name: name}
+	if err != nil {
+		recommendation.LastError = err.Error()
+	} else {
+		recommendation.Replicas = replicas
+		recommendation.Timestamp = metav1.NewTime(timestamp)
+	}
+	gpa.Status.Recommendations = append(gpa.Status.Recommendations, recommendation)
+}
+
+// mergeRecommendations keeps the last successful replicas and timestamp for the failed sources, and
+// the previous timestamp for the unchanged recommendations within statusRefreshInterval
+func mergeRecommendations(previous, current []autoscaling.Recommendation, now time.Time) {
+	for i := range current {
+		for _, p := range previous {
+			if p.Name != current[i].Name {
+				continue
+			}
+			if len(current[i].LastError) != 0 {
+				current[i].Replicas = p.Replicas
+				current[i].Timestamp = p.Timestamp
+			} else if len(p.LastError) == 0 && p.Replicas == current[i].Replicas &&
+				now.Sub(p.Timestamp.Time) < statusRefreshInterval {
+				current[i].Timestamp = p.Timestamp
+			}
+			break
+		}
+	}
+}
+
+// recommendationName returns the name of source in recommendations, e.g. metric(Resource cpu)
+func recommendationName(mode, source string) string {
+	if len(source) == 0 {
+		return mode
+	}
+	return fmt.Sprintf("%s(%s)", mode, source)
+}
+
+// metricSourceName describes the source of metric spec, e.g. Resource cpu
+func metricSourceName(spec autoscaling.MetricSpec) string {
+	switch {
+	case spec.Type == autoscaling.ResourceMetricSourceType && spec.Resource != nil:
+		return fmt.Sprintf("%s %s", spec.Type, spec.Resource.Name)
+	case spec.Type == autoscaling.ContainerResourceMetricSourceType && spec.ContainerResource != nil:
+		return fmt.Sprintf("%s %s/%s", spec.Type, spec.ContainerResource.Container, spec.ContainerResource.Name)
+	case spec.Type == autoscaling.PodsMetricSourceType && spec.Pods != nil:
+		return fmt.Sprintf("%s %s", spec.Type, spec.Pods.Metric.Name)
+	case spec.Type == autoscaling.ObjectMetricSourceType && spec.Object != nil:
+		return fmt.Sprintf("%s %s/%s %s", spec.Type, spec.Object.DescribedObject.Kind,
+			spec.Object.DescribedObject.Name, spec.Object.Metric.Name)
+	case spec.Type == autoscaling.ExternalMetricSourceType && spec.External != nil:
+		return fmt.Sprintf("%s %s", spec.Type, spec.External.Metric.Name)
+	}
+	return string(spec.Type)
+}
+
 func max(a, b int32) int32 {
 	if a >= b {
 		return a
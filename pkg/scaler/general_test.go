@@ -16,13 +16,18 @@
 package scaler
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"sync"
 	"testing"
 	"time"
 
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalinginternal "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
@@ -32,10 +37,14 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	scalefake "k8s.io/client-go/scale/fake"
 	cmapi "k8s.io/metrics/pkg/apis/custom_metrics/v1beta2"
@@ -45,6 +54,7 @@ import (
 	cmfake "k8s.io/metrics/pkg/client/custom_metrics/fake"
 	emfake "k8s.io/metrics/pkg/client/external_metrics/fake"
 
+	pkgerrors "github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling"
@@ -52,6 +62,8 @@ import (
 	autoscalingfake "github.com/ocgi/general-pod-autoscaler/pkg/client/clientset/versioned/fake"
 	autoscalinginformer "github.com/ocgi/general-pod-autoscaler/pkg/client/informers/externalversions"
 	metricsclient "github.com/ocgi/general-pod-autoscaler/pkg/metrics"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+	"github.com/ocgi/general-pod-autoscaler/pkg/scalercore"
 )
 
 var statusOk = []autoscalingv1alpha1.GeneralPodAutoscalerCondition{
@@ -114,8 +126,23 @@ type testCase struct {
 	useMetricsAPI                bool
 	computeByLimits              bool
 	metricsTarget                []autoscalingv1alpha1.MetricSpec
+	eventMode                    *autoscalingv1alpha1.EventMode
+	webhookMode                  *autoscalingv1alpha1.WebhookMode
+	modeSelectPolicy             *autoscalingv1alpha1.ModeSelectPolicy
+	dryRun                       bool
+	override                     *autoscalingv1alpha1.ScaleOverride
+	idle                         *autoscalingv1alpha1.IdleScaling
+	idleSince                    *metav1.Time
 	expectedDesiredReplicas      int32
+	expectedModeProposals        []autoscalingv1alpha1.ModeProposal
+	expectedRecommendations      []autoscalingv1alpha1.Recommendation
 	expectedConditions           []autoscalingv1alpha1.GeneralPodAutoscalerCondition
+	expectedActiveReason         string
+	expectedStatusUnchanged      bool
+	// status of the GPA listed, built from specReplicas, lastScaleTime and idleSince if nil
+	status *autoscalingv1alpha1.GeneralPodAutoscalerStatus
+	// status written by the last update
+	lastStatus *autoscalingv1alpha1.GeneralPodAutoscalerStatus
 	// Channel with names of GPA objects which we have reconciled.
 	processed chan string
 
@@ -230,7 +257,17 @@ func (tc *testCase) prepareTestClient(t *testing.T) (*fake.Clientset, *metricsfa
 		annotations[computeByLimitsKey] = strconv.FormatBool(tc.computeByLimits)
 		obj.Items[0].Annotations = annotations
 		obj.Items[0].Spec.AutoScalingDrivenMode = autoscalingv1alpha1.AutoScalingDrivenMode{
-			MetricMode: &autoscalingv1alpha1.MetricMode{},
+			MetricMode:  &autoscalingv1alpha1.MetricMode{},
+			EventMode:   tc.eventMode,
+			WebhookMode: tc.webhookMode,
+		}
+		obj.Items[0].Spec.ModeSelectPolicy = tc.modeSelectPolicy
+		obj.Items[0].Spec.DryRun = tc.dryRun
+		obj.Items[0].Spec.Override = tc.override
+		obj.Items[0].Spec.Idle = tc.idle
+		obj.Items[0].Status.IdleSince = tc.idleSince
+		if tc.status != nil {
+			obj.Items[0].Status = *tc.status.DeepCopy()
 		}
 
 		if tc.CPUTarget > 0 {
@@ -363,8 +400,40 @@ func (tc *testCase) prepareTestClient(t *testing.T) (*fake.Clientset, *metricsfa
 			assert.Equal(t, namespace, obj.Namespace, "the GPA namespace should be as expected")
 			assert.Equal(t, gpaName, obj.Name, "the GPA name should be as expected")
 			assert.Equal(t, tc.expectedDesiredReplicas, obj.Status.DesiredReplicas, "the desired replica count reported in the object status should be as expected")
+			if tc.expectedModeProposals != nil {
+				assert.Equal(t, tc.expectedModeProposals, obj.Status.ModeProposals, "the mode proposals reported in the object status should be as expected")
+			}
+			if tc.expectedRecommendations != nil {
+				recommendations := make([]autoscalingv1alpha1.Recommendation, len(obj.Status.Recommendations))
+				for i, rec := range obj.Status.Recommendations {
+					assert.False(t, rec.Timestamp.IsZero(), "the timestamp of recommendation %v should be set", rec.Name)
+					rec.Timestamp = metav1.Time{}
+					recommendations[i] = rec
+				}
+				assert.Equal(t, tc.expectedRecommendations, recommendations, "the recommendations reported in the object status should be as expected")
+			}
+			if len(tc.expectedActiveReason) != 0 {
+				for _, cond := range obj.Status.Conditions {
+					if cond.Type == autoscalingv1alpha1.ScalingActive {
+						assert.Equal(t, tc.expectedActiveReason, cond.Reason, "the reason of ScalingActive should be as expected")
+					}
+				}
+			}
+			if tc.dryRun && tc.specReplicas != tc.expectedDesiredReplicas {
+				if assert.NotNil(t, obj.Status.DryRun, "the dry run decision should be reported in the object status") {
+					assert.Equal(t, tc.specReplicas, obj.Status.DryRun.CurrentReplicas)
+					assert.Equal(t, tc.expectedDesiredReplicas, obj.Status.DryRun.DesiredReplicas)
+				}
+				assert.Nil(t, obj.Status.LastScaleTime, "the last scale time should not be set in dry run")
+				for _, cond := range obj.Status.Conditions {
+					if cond.Type == autoscalingv1alpha1.AbleToScale {
+						assert.Equal(t, "DryRun", cond.Reason)
+					}
+				}
+			}
 			// Every time we reconcile GPA object we are updating status.
 			tc.statusUpdated = true
+			tc.lastStatus = obj.Status.DeepCopy()
 			return true, obj, nil
 		}()
 		if obj != nil {
@@ -617,8 +686,8 @@ func (tc *testCase) prepareTestClient(t *testing.T) (*fake.Clientset, *metricsfa
 func (tc *testCase) verifyResults(t *testing.T) {
 	tc.Lock()
 	defer tc.Unlock()
-	assert.Equal(t, tc.specReplicas != tc.expectedDesiredReplicas, tc.scaleUpdated, "the scale should only be updated if we expected a change in replicas")
-	assert.True(t, tc.statusUpdated, "the status should have been updated")
+	assert.Equal(t, tc.specReplicas != tc.expectedDesiredReplicas && !tc.dryRun, tc.scaleUpdated, "the scale should only be updated if we expected a change in replicas")
+	assert.Equal(t, !tc.expectedStatusUnchanged, tc.statusUpdated, "the status should only be updated if it changed")
 	if tc.verifyEvents {
 		assert.Equal(t, tc.specReplicas != tc.expectedDesiredReplicas, tc.eventCreated, "an event should have been created only if we expected a change in replicas")
 	}
@@ -665,6 +734,9 @@ func (tc *testCase) setupController(t *testing.T) (*GeneralController, informers
 				//	computeResourceUtilizationRatioBy = "limit"
 				//}
 				assert.Contains(t, fmt.Sprintf("new size: %d", tc.expectedDesiredReplicas), obj.Message)
+			case "DryRunRescale":
+				assert.True(t, tc.dryRun, "the dry run event should only be created in dry run")
+				assert.Contains(t, obj.Message, fmt.Sprintf(`"new_replicas":%d`, tc.expectedDesiredReplicas))
 			case "DesiredReplicasComputed":
 				assert.Equal(t, fmt.Sprintf(
 					"Computed the desired num of replicas: %d (avgCPUutil: %d, current replicas: %d)",
@@ -690,6 +762,8 @@ func (tc *testCase) setupController(t *testing.T) (*GeneralController, informers
 		metricsClient,
 		scalerFactory.Autoscaling().V1alpha1().GeneralPodAutoscalers(),
 		informerFactory.Core().V1().Pods(),
+		scalerFactory.Autoscaling().V1alpha1().HolidayCalendars(),
+		testClient.CoreV1(),
 		0,
 		defaultDownscalestabilizationWindow,
 		defaultTestingTolerance,
@@ -713,7 +787,7 @@ func (tc *testCase) runTestWithController(t *testing.T, gpaController *GeneralCo
 	informerFactory.Start(stop)
 	go gpaController.Run(stop)
 	tc.Lock()
-	shouldWait := tc.verifyEvents
+	shouldWait := tc.verifyEvents || tc.expectedStatusUnchanged
 	tc.Unlock()
 	if shouldWait {
 		// We need to wait for events to be broadcasted (sleep for longer than record.sleepDuration).
@@ -755,6 +829,593 @@ func TestScaleUp(t *testing.T) {
 	tc.runTest(t)
 }
 
+func TestSteadyStateStatusUnchanged(t *testing.T) {
+	newTestCase := func() *testCase {
+		return &testCase{
+			minReplicas:             2,
+			maxReplicas:             6,
+			specReplicas:            3,
+			statusReplicas:          3,
+			expectedDesiredReplicas: 3,
+			CPUTarget:               50,
+			reportedLevels:          []uint64{400, 500, 600},
+			reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+			useMetricsAPI:           true,
+		}
+	}
+	tc := newTestCase()
+	tc.runTest(t)
+	tc.Lock()
+	status := tc.lastStatus
+	tc.Unlock()
+	if !assert.NotNil(t, status) {
+		return
+	}
+	assert.NotNil(t, status.ScalingHistory, "the recommendations should be kept in the scaling history")
+
+	// reconciling again with the written status finds nothing to update
+	tc = newTestCase()
+	tc.status = status
+	tc.expectedStatusUnchanged = true
+	tc.runTest(t)
+}
+
+func TestScaleUpDryRun(t *testing.T) {
+	tc := testCase{
+		minReplicas:             2,
+		maxReplicas:             6,
+		specReplicas:            3,
+		statusReplicas:          3,
+		expectedDesiredReplicas: 5,
+		CPUTarget:               30,
+		verifyCPUCurrent:        true,
+		verifyEvents:            true,
+		dryRun:                  true,
+		reportedLevels:          []uint64{300, 500, 700},
+		reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+		useMetricsAPI:           true,
+	}
+	tc.runTest(t)
+}
+
+func TestScaleUpDryRunStatusUnchanged(t *testing.T) {
+	newTestCase := func() *testCase {
+		return &testCase{
+			minReplicas:             2,
+			maxReplicas:             6,
+			specReplicas:            3,
+			statusReplicas:          3,
+			expectedDesiredReplicas: 5,
+			CPUTarget:               30,
+			dryRun:                  true,
+			reportedLevels:          []uint64{300, 500, 700},
+			reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+			useMetricsAPI:           true,
+		}
+	}
+	tc := newTestCase()
+	tc.runTest(t)
+	tc.Lock()
+	status := tc.lastStatus
+	tc.Unlock()
+	if !assert.NotNil(t, status) || !assert.NotNil(t, status.DryRun) {
+		return
+	}
+
+	// the same decision is not recorded again
+	tc = newTestCase()
+	tc.status = status
+	tc.expectedStatusUnchanged = true
+	tc.runTest(t)
+}
+
+func TestDryRunScaleEvents(t *testing.T) {
+	key := "test-namespace/test-gpa"
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+	recorder := record.NewFakeRecorder(10)
+	a := newHistoryController(fakeClock)
+	a.eventRecorder = recorder
+	a.podLister = corelisters.NewPodLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+	gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{
+		Spec: autoscalingv1alpha1.GeneralPodAutoscalerSpec{
+			MinReplicas: int32Ptr(1),
+			MaxReplicas: 20,
+			DryRun:      true,
+			Behavior: &autoscalingv1alpha1.GeneralPodAutoscalerBehavior{
+				ScaleUp: scalingRules(0, 2, 60), ScaleDown: scalingRules(0, 10, 60)},
+		},
+	}
+	// decide reconciles the target of 3 replicas recommended to be scaled to 10
+	decide := func() int32 {
+		desired := a.normalizeDesiredReplicasWithBehaviors(gpa, key, 3, 10, 1)
+		if desired != 3 {
+			a.recordDryRunRescale(gpa, key, "", 3, desired, "cpu above target")
+		}
+		return desired
+	}
+
+	assert.Equal(t, int32(5), decide())
+	assert.Len(t, recorder.Events, 1)
+	<-recorder.Events
+	decisionTime := gpa.Status.DryRun.DecisionTime
+
+	// the simulated scale up limits the rate in the period
+	fakeClock.Step(10 * time.Second)
+	assert.Equal(t, int32(3), decide())
+	assert.Empty(t, a.scaleUpEvents[key], "the simulated events should be kept apart from the ones of target")
+
+	// the same decision is not recorded again after the period
+	fakeClock.Step(time.Minute)
+	assert.Equal(t, int32(5), decide())
+	assert.Len(t, recorder.Events, 0)
+	assert.Equal(t, decisionTime, gpa.Status.DryRun.DecisionTime)
+
+	a.forgetDryRunScaleEvents(key)
+	assert.Empty(t, a.scaleUpEvents[dryRunEventsKey(key)])
+}
+
+func TestScaleUpOverride(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		override *autoscalingv1alpha1.ScaleOverride
+		desired  int32
+	}{
+		{name: "paused", override: &autoscalingv1alpha1.ScaleOverride{}, desired: 3},
+		{name: "held", override: &autoscalingv1alpha1.ScaleOverride{Replicas: int32Ptr(4)}, desired: 4},
+		{name: "expired", override: &autoscalingv1alpha1.ScaleOverride{Replicas: int32Ptr(4),
+			ExpirationTime: &metav1.Time{Time: time.Now().Add(-time.Minute)}}, desired: 5},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tc := testCase{
+				minReplicas:             2,
+				maxReplicas:             6,
+				specReplicas:            3,
+				statusReplicas:          3,
+				expectedDesiredReplicas: c.desired,
+				CPUTarget:               30,
+				verifyCPUCurrent:        true,
+				override:                c.override,
+				reportedLevels:          []uint64{300, 500, 700},
+				reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+				useMetricsAPI:           true,
+			}
+			tc.runTest(t)
+		})
+	}
+}
+
+const fixedTrigger = "test-fixed"
+
+func init() {
+	scalercore.RegisterTrigger(fixedTrigger, func(trigger autoscalingv1alpha1.ScaleTriggers) (scalercore.Scaler, error) {
+		replicas, err := strconv.Atoi(trigger.Metadata["replicas"])
+		if err != nil {
+			return nil, err
+		}
+		return &fixedScaler{replicas: int32(replicas)}, nil
+	})
+}
+
+// fixedScaler is a trigger which always proposes the replicas in metadata
+type fixedScaler struct {
+	replicas int32
+}
+
+func (s *fixedScaler) GetReplicas(gpa *autoscalingv1alpha1.GeneralPodAutoscaler, currentReplicas int32) (int32, error) {
+	return s.replicas, nil
+}
+
+func (s *fixedScaler) ScalerName() string {
+	return fixedTrigger
+}
+
+// newEventMode returns event mode with a trigger always proposing replicas
+func newEventMode(replicas int) *autoscalingv1alpha1.EventMode {
+	return &autoscalingv1alpha1.EventMode{
+		Triggers: []autoscalingv1alpha1.ScaleTriggers{
+			{
+				Type:     fixedTrigger,
+				Metadata: map[string]string{"replicas": strconv.Itoa(replicas)},
+			},
+		},
+	}
+}
+
+func modeSelectPolicy(policy autoscalingv1alpha1.ModeSelectPolicy) *autoscalingv1alpha1.ModeSelectPolicy {
+	return &policy
+}
+
+func TestScaleUpMetricAndEventModes(t *testing.T) {
+	cpuMetric := "cpu resource utilization (percentage of request)"
+	for _, c := range []struct {
+		name              string
+		eventReplicas     int
+		policy            *autoscalingv1alpha1.ModeSelectPolicy
+		expectedReplicas  int32
+		expectedProposals []autoscalingv1alpha1.ModeProposal
+	}{
+		{
+			name:             "max of modes by default",
+			eventReplicas:    4,
+			expectedReplicas: 5,
+			expectedProposals: []autoscalingv1alpha1.ModeProposal{
+				{Mode: autoscalingv1alpha1.MetricModeName, Replicas: 5, Metric: cpuMetric, Selected: true},
+				{Mode: autoscalingv1alpha1.EventModeName, Replicas: 4, Metric: scalercore.Event},
+			},
+		},
+		{
+			name:             "event above metric",
+			eventReplicas:    6,
+			policy:           modeSelectPolicy(autoscalingv1alpha1.MaxModeSelect),
+			expectedReplicas: 6,
+			expectedProposals: []autoscalingv1alpha1.ModeProposal{
+				{Mode: autoscalingv1alpha1.MetricModeName, Replicas: 5, Metric: cpuMetric},
+				{Mode: autoscalingv1alpha1.EventModeName, Replicas: 6, Metric: scalercore.Event, Selected: true},
+			},
+		},
+		{
+			name:             "min of modes",
+			eventReplicas:    4,
+			policy:           modeSelectPolicy(autoscalingv1alpha1.MinModeSelect),
+			expectedReplicas: 4,
+			expectedProposals: []autoscalingv1alpha1.ModeProposal{
+				{Mode: autoscalingv1alpha1.MetricModeName, Replicas: 5, Metric: cpuMetric},
+				{Mode: autoscalingv1alpha1.EventModeName, Replicas: 4, Metric: scalercore.Event, Selected: true},
+			},
+		},
+		{
+			name:             "named mode wins",
+			eventReplicas:    6,
+			policy:           modeSelectPolicy(autoscalingv1alpha1.MetricModeName),
+			expectedReplicas: 5,
+			expectedProposals: []autoscalingv1alpha1.ModeProposal{
+				{Mode: autoscalingv1alpha1.MetricModeName, Replicas: 5, Metric: cpuMetric, Selected: true},
+				{Mode: autoscalingv1alpha1.EventModeName, Replicas: 6, Metric: scalercore.Event},
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tc := testCase{
+				minReplicas:             2,
+				maxReplicas:             6,
+				specReplicas:            3,
+				statusReplicas:          3,
+				expectedDesiredReplicas: c.expectedReplicas,
+				CPUTarget:               30,
+				verifyCPUCurrent:        true,
+				reportedLevels:          []uint64{300, 500, 700},
+				reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+				useMetricsAPI:           true,
+				eventMode:               newEventMode(c.eventReplicas),
+				modeSelectPolicy:        c.policy,
+				expectedModeProposals:   c.expectedProposals,
+				expectedRecommendations: []autoscalingv1alpha1.Recommendation{
+					{Name: "metric(Resource cpu)", Replicas: 5, Decisive: true},
+					{Name: fmt.Sprintf("event(%s)", fixedTrigger), Replicas: int32(c.eventReplicas)},
+				},
+			}
+			tc.runTest(t)
+		})
+	}
+}
+
+func TestScaleUpMetricFailedEventMode(t *testing.T) {
+	for _, c := range []struct {
+		name           string
+		policy         *autoscalingv1alpha1.ModeSelectPolicy
+		expectedReason string
+	}{
+		{
+			name:           "failed mode left out",
+			expectedReason: "PartialModeFailure",
+		},
+		{
+			name:           "named mode failed",
+			policy:         modeSelectPolicy(autoscalingv1alpha1.MetricModeName),
+			expectedReason: "SelectedModeUnavailable",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tc := testCase{
+				minReplicas:             2,
+				maxReplicas:             6,
+				specReplicas:            3,
+				statusReplicas:          3,
+				expectedDesiredReplicas: 5,
+				CPUTarget:               30,
+				reportedLevels:          []uint64{},
+				reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+				useMetricsAPI:           true,
+				eventMode:               newEventMode(5),
+				modeSelectPolicy:        c.policy,
+				expectedModeProposals: []autoscalingv1alpha1.ModeProposal{
+					{Mode: autoscalingv1alpha1.EventModeName, Replicas: 5, Metric: scalercore.Event, Selected: true},
+				},
+				expectedActiveReason: c.expectedReason,
+			}
+			tc.runTest(t)
+		})
+	}
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+// newBoundsWebhook returns the server answering the replicas limits only
+func newBoundsWebhook(minReplicas, maxReplicas *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review requests.AutoscaleReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review.Response = &requests.AutoscaleResponse{UID: review.Request.UID, MinReplicas: minReplicas,
+			MaxReplicas: maxReplicas}
+		json.NewEncoder(w).Encode(review)
+	}))
+}
+
+func TestScaleWithWebhookBounds(t *testing.T) {
+	cpuMetric := "cpu resource utilization (percentage of request)"
+	for _, c := range []struct {
+		name             string
+		minReplicas      *int32
+		maxReplicas      *int32
+		expectedReplicas int32
+	}{
+		{name: "floor raised", minReplicas: int32Ptr(6), maxReplicas: int32Ptr(8), expectedReplicas: 6},
+		{name: "ceiling lowered", maxReplicas: int32Ptr(4), expectedReplicas: 4},
+		{name: "floor capped by ceiling", minReplicas: int32Ptr(7), expectedReplicas: 6},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			// the server is not closed, the controller may reconcile again before it stops
+			server := newBoundsWebhook(c.minReplicas, c.maxReplicas)
+			tc := testCase{
+				minReplicas:             2,
+				maxReplicas:             6,
+				specReplicas:            3,
+				statusReplicas:          3,
+				expectedDesiredReplicas: c.expectedReplicas,
+				CPUTarget:               30,
+				verifyCPUCurrent:        true,
+				reportedLevels:          []uint64{300, 500, 700},
+				reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+				useMetricsAPI:           true,
+				webhookMode: &autoscalingv1alpha1.WebhookMode{
+					WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+				},
+				// the webhook answering the limits only proposes no replicas
+				expectedModeProposals: []autoscalingv1alpha1.ModeProposal{
+					{Mode: autoscalingv1alpha1.MetricModeName, Replicas: 5, Metric: cpuMetric, Selected: true},
+				},
+			}
+			gpaController, informerFactory, scalerFactory := tc.setupController(t)
+			tc.runTestWithController(t, gpaController, informerFactory, scalerFactory)
+
+			// the limits are overridden in the calculation only, the shared cache is not mutated
+			gpa, err := gpaController.gpaLister.GeneralPodAutoscalers("test-namespace").Get("test-gpa")
+			if assert.NoError(t, err) {
+				assert.Equal(t, int32(6), gpa.Spec.MaxReplicas)
+				assert.Equal(t, int32(2), *gpa.Spec.MinReplicas)
+			}
+		})
+	}
+}
+
+func TestApplyReplicaBounds(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		minReplicas *int32
+		bounds      scalercore.ReplicaBounds
+		desiredMin  *int32
+		desiredMax  int32
+	}{
+		{
+			name:        "limits overridden",
+			minReplicas: int32Ptr(2),
+			bounds:      scalercore.ReplicaBounds{MinReplicas: int32Ptr(3), MaxReplicas: int32Ptr(8)},
+			desiredMin:  int32Ptr(3),
+			desiredMax:  8,
+		},
+		{
+			name:        "min capped by max",
+			minReplicas: int32Ptr(2),
+			bounds:      scalercore.ReplicaBounds{MinReplicas: int32Ptr(5), MaxReplicas: int32Ptr(4)},
+			desiredMin:  int32Ptr(4),
+			desiredMax:  4,
+		},
+		{
+			name:        "zero min ignored",
+			minReplicas: int32Ptr(2),
+			bounds:      scalercore.ReplicaBounds{MinReplicas: int32Ptr(0)},
+			desiredMin:  int32Ptr(2),
+			desiredMax:  6,
+		},
+		{
+			name:        "zero min of gpa scaled to zero",
+			minReplicas: int32Ptr(0),
+			bounds:      scalercore.ReplicaBounds{MinReplicas: int32Ptr(0)},
+			desiredMin:  int32Ptr(0),
+			desiredMax:  6,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{
+				Spec: autoscalingv1alpha1.GeneralPodAutoscalerSpec{MinReplicas: c.minReplicas, MaxReplicas: 6},
+			}
+			applyReplicaBounds(gpa, &c.bounds)
+			assert.Equal(t, c.desiredMin, gpa.Spec.MinReplicas)
+			assert.Equal(t, c.desiredMax, gpa.Spec.MaxReplicas)
+		})
+	}
+}
+
+func TestMergeRecommendations(t *testing.T) {
+	lastTime := metav1.NewTime(time.Now().Add(-time.Minute))
+	recentTime := metav1.NewTime(time.Now().Add(-10 * time.Second))
+	now := metav1.Now()
+	previous := []autoscalingv1alpha1.Recommendation{
+		{Name: "webhook", Replicas: 3, Timestamp: lastTime},
+		{Name: "metric(Resource cpu)", Replicas: 4, Timestamp: lastTime},
+		{Name: "metric(Resource memory)", Replicas: 4, Timestamp: recentTime},
+		{Name: "time(* 10-23 * * *)", Replicas: 6, Timestamp: lastTime},
+	}
+	current := []autoscalingv1alpha1.Recommendation{
+		{Name: "webhook", LastError: "connection refused"},
+		{Name: "metric(Resource cpu)", Replicas: 5, Timestamp: now},
+		{Name: "metric(Resource memory)", Replicas: 4, Timestamp: now},
+		{Name: "time(* 10-23 * * *)", Replicas: 6, Timestamp: now},
+		{Name: "event(queue)", LastError: "bad status code"},
+	}
+	mergeRecommendations(previous, current, now.Time)
+	assert.Equal(t, []autoscalingv1alpha1.Recommendation{
+		{Name: "webhook", Replicas: 3, Timestamp: lastTime, LastError: "connection refused"},
+		{Name: "metric(Resource cpu)", Replicas: 5, Timestamp: now},
+		{Name: "metric(Resource memory)", Replicas: 4, Timestamp: recentTime},
+		{Name: "time(* 10-23 * * *)", Replicas: 6, Timestamp: now},
+		{Name: "event(queue)", LastError: "bad status code"},
+	}, current)
+}
+
+func TestRecordWebhookResponse(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	a := &GeneralController{eventRecorder: recorder}
+	gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{}
+
+	a.recordWebhookResponse(gpa, pkgerrors.Wrap(&scalercore.WebhookRejectedError{Code: 409, Reason: "Draining",
+		Message: "workload is draining"}, "call webhook failed"))
+	assert.Equal(t, autoscalingv1alpha1.WebhookResponseValid, gpa.Status.Conditions[0].Type)
+	assert.Equal(t, v1.ConditionFalse, gpa.Status.Conditions[0].Status)
+	assert.Equal(t, "Draining", gpa.Status.Conditions[0].Reason)
+	assert.Equal(t, "workload is draining", gpa.Status.Conditions[0].Message)
+	assert.Equal(t, "Warning WebhookRejected Draining: workload is draining", <-recorder.Events)
+
+	// errors other than rejection leave the condition unchanged
+	a.recordWebhookResponse(gpa, fmt.Errorf("connection refused"))
+	assert.Equal(t, "Draining", gpa.Status.Conditions[0].Reason)
+
+	a.recordWebhookResponse(gpa, nil)
+	assert.Equal(t, v1.ConditionTrue, gpa.Status.Conditions[0].Status)
+	assert.Equal(t, "ValidResponse", gpa.Status.Conditions[0].Reason)
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestRecordWebhookBreaker(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	a := &GeneralController{eventRecorder: recorder}
+	gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{}
+
+	a.recordWebhookBreaker(gpa, &autoscalingv1alpha1.WebhookBreakerStatus{State: autoscalingv1alpha1.BreakerClosed})
+	a.recordWebhookBreaker(gpa, &autoscalingv1alpha1.WebhookBreakerStatus{State: autoscalingv1alpha1.BreakerOpen,
+		ConsecutiveFailures: 3, LastError: "connection refused"})
+	assert.Equal(t, "Warning WebhookCircuitOpen circuit breaker of webhook opened after 3 consecutive failures: "+
+		"connection refused", <-recorder.Events)
+	// no event if the breaker keeps open
+	a.recordWebhookBreaker(gpa, &autoscalingv1alpha1.WebhookBreakerStatus{State: autoscalingv1alpha1.BreakerOpen,
+		ConsecutiveFailures: 4})
+	a.recordWebhookBreaker(gpa, &autoscalingv1alpha1.WebhookBreakerStatus{State: autoscalingv1alpha1.BreakerClosed})
+	assert.Equal(t, "Normal WebhookCircuitClosed circuit breaker of webhook closed", <-recorder.Events)
+	assert.Len(t, recorder.Events, 0)
+
+	a.recordWebhookBreaker(gpa, nil)
+	assert.Nil(t, gpa.Status.WebhookBreaker)
+}
+
+func TestRecordWebhookCache(t *testing.T) {
+	a := &GeneralController{eventRecorder: record.NewFakeRecorder(10)}
+	gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{}
+	cache := &autoscalingv1alpha1.WebhookCachedResponse{Scale: true, Replicas: 5}
+
+	a.recordWebhookCache(gpa, cache, true, true)
+	assert.Equal(t, cache, gpa.Status.WebhookCache)
+	assert.Equal(t, v1.ConditionTrue, gpa.Status.Conditions[0].Status)
+	assert.Equal(t, "RefreshFailed", gpa.Status.Conditions[0].Reason)
+
+	a.recordWebhookCache(gpa, cache, true, false)
+	assert.Equal(t, autoscalingv1alpha1.WebhookResponseStale, gpa.Status.Conditions[0].Type)
+	assert.Equal(t, v1.ConditionFalse, gpa.Status.Conditions[0].Status)
+	assert.Equal(t, "FreshResponse", gpa.Status.Conditions[0].Reason)
+
+	// the expired cache is kept but not used, e.g. the circuit breaker is open
+	a.recordWebhookCache(gpa, cache, false, false)
+	assert.Equal(t, cache, gpa.Status.WebhookCache)
+	assert.Empty(t, gpa.Status.Conditions)
+
+	a.recordWebhookCache(gpa, nil, false, false)
+	assert.Nil(t, gpa.Status.WebhookCache)
+	assert.Empty(t, gpa.Status.Conditions)
+}
+
+func TestReconcileDeletedReleasesTransports(t *testing.T) {
+	key := "test-namespace/test-gpa"
+	gpaInformer := autoscalinginformer.NewSharedInformerFactory(autoscalingfake.NewSimpleClientset(), 0).
+		Autoscaling().V1alpha1().GeneralPodAutoscalers()
+	a := &GeneralController{
+		gpaLister:           gpaInformer.Lister(),
+		recommendations:     map[string][]timestampedRecommendation{},
+		scaleUpEvents:       map[string][]timestampedScaleEvent{},
+		scaleDownEvents:     map[string][]timestampedScaleEvent{},
+		longRunScalers:      map[string]*longRunScalers{},
+		transports:          scalercore.NewTransportCache(),
+		scheduleTransitions: scalercore.NewScheduleTransitionCache(),
+	}
+	u, _ := url.Parse("http://webhook.example.com/scale")
+	if _, err := a.transports.Client(key, u, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := a.reconcileKey(key)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, 0, a.transports.Len(), "the transport of deleted gpa should be released")
+}
+
+func TestWebhookModeRemovedReleasesTransports(t *testing.T) {
+	tc := testCase{
+		minReplicas:             2,
+		maxReplicas:             6,
+		specReplicas:            3,
+		statusReplicas:          3,
+		expectedDesiredReplicas: 3,
+		CPUTarget:               50,
+		reportedLevels:          []uint64{400, 500, 600},
+		reportedCPURequests:     []resource.Quantity{resource.MustParse("1.0"), resource.MustParse("1.0"), resource.MustParse("1.0")},
+		useMetricsAPI:           true,
+	}
+	gpaController, informerFactory, scalerFactory := tc.setupController(t)
+	u, _ := url.Parse("http://webhook.example.com/scale")
+	if _, err := gpaController.transports.Client("test-namespace/test-gpa", u, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	tc.runTestWithController(t, gpaController, informerFactory, scalerFactory)
+	assert.Equal(t, 0, gpaController.transports.Len(), "the transport of removed webhook mode should be released")
+}
+
+func TestSelectModeProposal(t *testing.T) {
+	proposals := []modeProposal{
+		{mode: autoscalingv1alpha1.MetricModeName, replicas: 5},
+		{mode: autoscalingv1alpha1.WebhookModeName, replicas: 7},
+		{mode: autoscalingv1alpha1.TimeModeName, replicas: 3},
+	}
+	for _, c := range []struct {
+		name      string
+		policy    *autoscalingv1alpha1.ModeSelectPolicy
+		proposals []modeProposal
+		selected  int
+	}{
+		{name: "default max", proposals: proposals, selected: 1},
+		{name: "min", policy: modeSelectPolicy(autoscalingv1alpha1.MinModeSelect), proposals: proposals, selected: 2},
+		{name: "named mode", policy: modeSelectPolicy(autoscalingv1alpha1.MetricModeName), proposals: proposals, selected: 0},
+		{name: "named mode without proposal", policy: modeSelectPolicy(autoscalingv1alpha1.EventModeName), proposals: proposals, selected: 1},
+		{name: "no proposals", selected: -1},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.selected, selectModeProposal(c.policy, c.proposals))
+		})
+	}
+}
+
 func TestScaleUpUnreadyLessScale(t *testing.T) {
 	tc := testCase{
 		minReplicas:             2,
@@ -1587,6 +2248,90 @@ func TestScaleDownToZeroCMExternal(t *testing.T) {
 	tc.runTest(t)
 }
 
+func TestScaleDownToZeroIdlePeriod(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		idleSince *metav1.Time
+		desired   int32
+	}{
+		{name: "idle period not passed", desired: 1},
+		{name: "idle period passed", idleSince: &metav1.Time{Time: time.Now().Add(-10 * time.Minute)}, desired: 0},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tc := testCase{
+				minReplicas:             0,
+				maxReplicas:             6,
+				specReplicas:            5,
+				statusReplicas:          5,
+				expectedDesiredReplicas: c.desired,
+				idle:                    &autoscalingv1alpha1.IdleScaling{IdlePeriodSeconds: 300},
+				idleSince:               c.idleSince,
+				metricsTarget: []autoscalingv1alpha1.MetricSpec{
+					{
+						Type: autoscalingv1alpha1.ExternalMetricSourceType,
+						External: &autoscalingv1alpha1.ExternalMetricSource{
+							Metric: autoscalingv1alpha1.MetricIdentifier{
+								Name:     "qps",
+								Selector: &metav1.LabelSelector{},
+							},
+							Target: autoscalingv1alpha1.MetricTarget{
+								Value: resource.NewMilliQuantity(14400, resource.DecimalSI),
+							},
+						},
+					},
+				},
+				reportedLevels:  []uint64{0},
+				recommendations: []timestampedRecommendation{},
+			}
+			tc.runTest(t)
+		})
+	}
+}
+
+func TestScaleUpFromZeroCMExternal(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		idle    *autoscalingv1alpha1.IdleScaling
+		level   uint64
+		desired int32
+	}{
+		{name: "default activation", level: 8600, desired: 1},
+		{name: "activation replicas", idle: &autoscalingv1alpha1.IdleScaling{ActivationReplicas: int32Ptr(3)},
+			level: 8600, desired: 3},
+		{name: "above activation replicas", idle: &autoscalingv1alpha1.IdleScaling{ActivationReplicas: int32Ptr(2)},
+			level: 43200, desired: 3},
+		{name: "still idle", idle: &autoscalingv1alpha1.IdleScaling{ActivationReplicas: int32Ptr(3)}, desired: 0},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tc := testCase{
+				minReplicas:             0,
+				maxReplicas:             6,
+				specReplicas:            0,
+				statusReplicas:          0,
+				expectedDesiredReplicas: c.desired,
+				idle:                    c.idle,
+				metricsTarget: []autoscalingv1alpha1.MetricSpec{
+					{
+						Type: autoscalingv1alpha1.ExternalMetricSourceType,
+						External: &autoscalingv1alpha1.ExternalMetricSource{
+							Metric: autoscalingv1alpha1.MetricIdentifier{
+								Name:     "qps",
+								Selector: &metav1.LabelSelector{},
+							},
+							Target: autoscalingv1alpha1.MetricTarget{
+								Value: resource.NewMilliQuantity(14400, resource.DecimalSI),
+							},
+						},
+					},
+				},
+				reportedLevels:  []uint64{c.level},
+				recommendations: []timestampedRecommendation{},
+			}
+			tc.runTest(t)
+		})
+	}
+}
+
 func TestScaleDownPerPodCMExternal(t *testing.T) {
 	tc := testCase{
 		minReplicas:             2,
@@ -3006,6 +3751,7 @@ func TestConvertDesiredReplicasWithRules(t *testing.T) {
 }
 
 func TestNormalizeDesiredReplicas(t *testing.T) {
+	now := time.Date(2020, 12, 18, 10, 0, 0, 0, time.UTC)
 	tests := []struct {
 		name                         string
 		key                          string
@@ -3026,8 +3772,8 @@ func TestNormalizeDesiredReplicas(t *testing.T) {
 			"stabilize",
 			"",
 			[]timestampedRecommendation{
-				{4, time.Now().Add(-2 * time.Minute)},
-				{5, time.Now().Add(-1 * time.Minute)},
+				{4, now.Add(-2 * time.Minute)},
+				{5, now.Add(-1 * time.Minute)},
 			},
 			3,
 			5,
@@ -3037,8 +3783,8 @@ func TestNormalizeDesiredReplicas(t *testing.T) {
 			"no stabilize",
 			"",
 			[]timestampedRecommendation{
-				{1, time.Now().Add(-2 * time.Minute)},
-				{2, time.Now().Add(-1 * time.Minute)},
+				{1, now.Add(-2 * time.Minute)},
+				{2, now.Add(-1 * time.Minute)},
 			},
 			3,
 			3,
@@ -3048,8 +3794,8 @@ func TestNormalizeDesiredReplicas(t *testing.T) {
 			"no stabilize - old recommendations",
 			"",
 			[]timestampedRecommendation{
-				{10, time.Now().Add(-10 * time.Minute)},
-				{9, time.Now().Add(-9 * time.Minute)},
+				{10, now.Add(-10 * time.Minute)},
+				{9, now.Add(-9 * time.Minute)},
 			},
 			3,
 			3,
@@ -3059,10 +3805,10 @@ func TestNormalizeDesiredReplicas(t *testing.T) {
 			"stabilize - old recommendations",
 			"",
 			[]timestampedRecommendation{
-				{10, time.Now().Add(-10 * time.Minute)},
-				{4, time.Now().Add(-1 * time.Minute)},
-				{5, time.Now().Add(-2 * time.Minute)},
-				{9, time.Now().Add(-9 * time.Minute)},
+				{10, now.Add(-10 * time.Minute)},
+				{4, now.Add(-1 * time.Minute)},
+				{5, now.Add(-2 * time.Minute)},
+				{9, now.Add(-9 * time.Minute)},
 			},
 			3,
 			5,
@@ -3072,6 +3818,7 @@ func TestNormalizeDesiredReplicas(t *testing.T) {
 	for _, tc := range tests {
 		hc := GeneralController{
 			downscaleStabilisationWindow: 5 * time.Minute,
+			clock:                        clock.NewFakeClock(now),
 			recommendations: map[string][]timestampedRecommendation{
 				tc.key: tc.recommendations,
 			},
@@ -3185,6 +3932,60 @@ func TestNoScaleDownOneMetricEmpty(t *testing.T) {
 	tc.runTest(t)
 }
 
+func TestComputeReplicasForTimeModeConcurrently(t *testing.T) {
+	now := time.Date(2020, 12, 18, 10, 30, 30, 0, time.UTC)
+	a := &GeneralController{clock: clock.NewFakeClock(now)}
+	scale := &autoscalinginternal.Scale{
+		Spec:   autoscalinginternal.ScaleSpec{Replicas: 1},
+		Status: autoscalinginternal.ScaleStatus{Replicas: 1, Selector: "app=test"},
+	}
+	gpas := make([]*autoscalingv1alpha1.GeneralPodAutoscaler, 30)
+	for i := range gpas {
+		gpas[i] = &autoscalingv1alpha1.GeneralPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("test-gpa-%d", i),
+				Namespace:         "test-namespace",
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+			},
+			Spec: autoscalingv1alpha1.GeneralPodAutoscalerSpec{
+				AutoScalingDrivenMode: autoscalingv1alpha1.AutoScalingDrivenMode{
+					TimeMode: &autoscalingv1alpha1.TimeMode{
+						TimeRanges: []autoscalingv1alpha1.TimeRange{
+							{Schedule: fmt.Sprintf("%d-59 10 * * *", i), DesiredReplicas: int32(i + 1)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// each gpa keeps its own schedule while reconciled at the same time
+	var wg sync.WaitGroup
+	for _, gpa := range gpas {
+		wg.Add(1)
+		go func(gpa *autoscalingv1alpha1.GeneralPodAutoscaler) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				proposals, err := a.computeReplicasForSimple(gpa, scale, gpa.Namespace+"/"+gpa.Name)
+				if err != nil {
+					t.Errorf("%v compute replicas failed: %v", gpa.Name, err)
+					return
+				}
+				desired := gpa.Spec.TimeMode.TimeRanges[0].DesiredReplicas
+				if len(proposals) != 1 || proposals[0].replicas != desired {
+					t.Errorf("%v desired proposal: %v, actual: %v", gpa.Name, desired, proposals)
+					return
+				}
+			}
+		}(gpa)
+	}
+	wg.Wait()
+	for _, gpa := range gpas {
+		schedule := gpa.Spec.TimeMode.TimeRanges[0].Schedule
+		assert.Equal(t, schedule, gpa.Status.LastCronScheduleName, gpa.Name)
+	}
+}
+
 func testScheme() *runtime.Scheme {
 	s := runtime.NewScheme()
 	s.AddKnownTypes(schema.GroupVersion{
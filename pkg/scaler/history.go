@@ -0,0 +1,162 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+
+	autoscaling "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// statusRefreshInterval is how long the timestamps of unchanged recommendations are kept in status, so
+// that the status of a steady autoscaler is not updated on every resync
+const statusRefreshInterval = time.Minute
+
+// restoreScalingHistory restores the recommendations and scale events of gpa from its status if the
+// controller has none of them, e.g. after restart or leader failover
+func (a *GeneralController) restoreScalingHistory(gpa *autoscaling.GeneralPodAutoscaler, key string) {
+	history := gpa.Status.ScalingHistory
+	if history == nil {
+		return
+	}
+
+	a.recommendationsLock.Lock()
+	if a.recommendations[key] == nil && len(history.Recommendations) != 0 {
+		recommendations := make([]timestampedRecommendation, 0, len(history.Recommendations))
+		for _, rec := range history.Recommendations {
+			recommendations = append(recommendations, timestampedRecommendation{rec.Replicas, rec.Timestamp.Time})
+		}
+		a.recommendations[key] = recommendations
+		klog.V(4).Infof("Restored %v recommendations of %v", len(recommendations), key)
+	}
+	a.recommendationsLock.Unlock()
+
+	a.scaleUpEventsLock.Lock()
+	if a.scaleUpEvents[key] == nil && len(history.ScaleUpEvents) != 0 {
+		a.scaleUpEvents[key] = restoreScaleEvents(history.ScaleUpEvents)
+	}
+	a.scaleUpEventsLock.Unlock()
+
+	a.scaleDownEventsLock.Lock()
+	if a.scaleDownEvents[key] == nil && len(history.ScaleDownEvents) != 0 {
+		a.scaleDownEvents[key] = restoreScaleEvents(history.ScaleDownEvents)
+	}
+	a.scaleDownEventsLock.Unlock()
+}
+
+func restoreScaleEvents(events []autoscaling.TimestampedReplicas) []timestampedScaleEvent {
+	scaleEvents := make([]timestampedScaleEvent, 0, len(events))
+	for _, event := range events {
+		scaleEvents = append(scaleEvents, timestampedScaleEvent{event.Replicas, event.Timestamp.Time, false})
+	}
+	return scaleEvents
+}
+
+// recordScalingHistory keeps the recommendations and scale events of gpa in its status, the ones out
+// of the longest stabilization window and the longest policy period are dropped. The history in status
+// is kept if only the timestamps of recommendations changed within statusRefreshInterval.
+func (a *GeneralController) recordScalingHistory(gpa *autoscaling.GeneralPodAutoscaler, key string) {
+	now := a.clock.Now()
+	history := &autoscaling.ScalingHistory{}
+
+	a.recommendationsLock.Lock()
+	cutoff := now.Add(-a.recommendationWindow(gpa.Spec.Behavior))
+	for _, rec := range a.recommendations[key] {
+		if !rec.timestamp.Before(cutoff) {
+			history.Recommendations = append(history.Recommendations, autoscaling.TimestampedReplicas{
+				Replicas: rec.recommendation, Timestamp: metav1.NewTime(rec.timestamp)})
+		}
+	}
+	a.recommendationsLock.Unlock()
+
+	if behavior := gpa.Spec.Behavior; behavior != nil {
+		if behavior.ScaleUp != nil {
+			a.scaleUpEventsLock.Lock()
+			history.ScaleUpEvents = compactScaleEvents(a.scaleUpEvents[key], getLongestPolicyPeriod(behavior.ScaleUp), now)
+			a.scaleUpEventsLock.Unlock()
+		}
+		if behavior.ScaleDown != nil {
+			a.scaleDownEventsLock.Lock()
+			history.ScaleDownEvents = compactScaleEvents(a.scaleDownEvents[key], getLongestPolicyPeriod(behavior.ScaleDown), now)
+			a.scaleDownEventsLock.Unlock()
+		}
+	}
+
+	if len(history.Recommendations) == 0 && len(history.ScaleUpEvents) == 0 && len(history.ScaleDownEvents) == 0 {
+		history = nil
+	}
+	if scalingHistoryChanged(gpa.Status.ScalingHistory, history, now) {
+		gpa.Status.ScalingHistory = history
+	}
+}
+
+// scalingHistoryChanged returns whether the history should replace the previous one in status, that is
+// the scale events or the recommended replicas are changed, or the previous recommendations are not
+// refreshed in statusRefreshInterval
+func scalingHistoryChanged(previous, history *autoscaling.ScalingHistory, now time.Time) bool {
+	if previous == nil || history == nil {
+		return previous != history
+	}
+	if !apiequality.Semantic.DeepEqual(previous.ScaleUpEvents, history.ScaleUpEvents) ||
+		!apiequality.Semantic.DeepEqual(previous.ScaleDownEvents, history.ScaleDownEvents) {
+		return true
+	}
+	previousReplicas, replicas := sets.NewInt32(), sets.NewInt32()
+	var refreshed time.Time
+	for _, rec := range previous.Recommendations {
+		previousReplicas.Insert(rec.Replicas)
+		if rec.Timestamp.After(refreshed) {
+			refreshed = rec.Timestamp.Time
+		}
+	}
+	for _, rec := range history.Recommendations {
+		replicas.Insert(rec.Replicas)
+	}
+	return !previousReplicas.Equal(replicas) || now.Sub(refreshed) >= statusRefreshInterval
+}
+
+// recommendationWindow returns the longest stabilization window of behavior, the recommendations in it
+// are used by the stabilization
+func (a *GeneralController) recommendationWindow(behavior *autoscaling.GeneralPodAutoscalerBehavior) time.Duration {
+	if behavior == nil {
+		return a.downscaleStabilisationWindow
+	}
+	var seconds int32
+	for _, rules := range []*autoscaling.GPAScalingRules{behavior.ScaleUp, behavior.ScaleDown} {
+		if rules != nil && rules.StabilizationWindowSeconds != nil && *rules.StabilizationWindowSeconds > seconds {
+			seconds = *rules.StabilizationWindowSeconds
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// compactScaleEvents returns the scale events in the longest policy period
+func compactScaleEvents(scaleEvents []timestampedScaleEvent, longestPolicyPeriod int32,
+	now time.Time) []autoscaling.TimestampedReplicas {
+	var events []autoscaling.TimestampedReplicas
+	cutoff := now.Add(-time.Second * time.Duration(longestPolicyPeriod))
+	for _, event := range scaleEvents {
+		if !event.outdated && !event.timestamp.Before(cutoff) {
+			events = append(events, autoscaling.TimestampedReplicas{
+				Replicas: event.replicaChange, Timestamp: metav1.NewTime(event.timestamp)})
+		}
+	}
+	return events
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	autoscalingv1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// newHistoryController returns a controller without history, as the one started after failover
+func newHistoryController(clock clock.Clock) *GeneralController {
+	return &GeneralController{
+		downscaleStabilisationWindow: 5 * time.Minute,
+		clock:                        clock,
+		recommendations:              map[string][]timestampedRecommendation{},
+		scaleUpEvents:                map[string][]timestampedScaleEvent{},
+		scaleDownEvents:              map[string][]timestampedScaleEvent{},
+	}
+}
+
+// persist returns the gpa read back after its status is written
+func persist(t *testing.T, gpa *autoscalingv1alpha1.GeneralPodAutoscaler) *autoscalingv1alpha1.GeneralPodAutoscaler {
+	data, err := json.Marshal(gpa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &autoscalingv1alpha1.GeneralPodAutoscaler{}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// scalingRules returns the rules of stabilization window and the policy of pods per period
+func scalingRules(window, pods, periodSeconds int32) *autoscalingv1alpha1.GPAScalingRules {
+	selectPolicy := autoscalingv1alpha1.MaxPolicySelect
+	return &autoscalingv1alpha1.GPAScalingRules{
+		StabilizationWindowSeconds: &window,
+		SelectPolicy:               &selectPolicy,
+		Policies: []autoscalingv1alpha1.GPAScalingPolicy{
+			{Type: autoscalingv1alpha1.PodsScalingPolicy, Value: pods, PeriodSeconds: periodSeconds},
+		},
+	}
+}
+
+// scalingStep is a reconcile recommending desired replicas at the offset from the start
+type scalingStep struct {
+	offset   time.Duration
+	current  int32
+	desired  int32
+	expected int32
+}
+
+func TestScalingHistoryRestart(t *testing.T) {
+	key := "test-namespace/test-gpa"
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		name     string
+		behavior *autoscalingv1alpha1.GeneralPodAutoscalerBehavior
+		// steps are reconciled by the leader before failover
+		steps []scalingStep
+		// next is reconciled by the new leader, restored is the replicas desired with the history restored,
+		// fresh is the one without history
+		next     scalingStep
+		restored int32
+		fresh    int32
+	}{
+		{
+			name: "scale down policy period",
+			behavior: &autoscalingv1alpha1.GeneralPodAutoscalerBehavior{
+				ScaleUp: scalingRules(0, 4, 60), ScaleDown: scalingRules(0, 1, 300)},
+			steps:    []scalingStep{{current: 10, desired: 9, expected: 9}},
+			next:     scalingStep{offset: time.Minute, current: 9, desired: 2},
+			restored: 9,
+			fresh:    8,
+		},
+		{
+			name: "scale up policy period",
+			behavior: &autoscalingv1alpha1.GeneralPodAutoscalerBehavior{
+				ScaleUp: scalingRules(0, 4, 120), ScaleDown: scalingRules(300, 10, 60)},
+			steps:    []scalingStep{{current: 3, desired: 6, expected: 6}},
+			next:     scalingStep{offset: time.Minute, current: 6, desired: 12},
+			restored: 7,
+			fresh:    10,
+		},
+		{
+			name: "scale down stabilization window",
+			behavior: &autoscalingv1alpha1.GeneralPodAutoscalerBehavior{
+				ScaleUp: scalingRules(0, 10, 60), ScaleDown: scalingRules(300, 10, 60)},
+			steps: []scalingStep{
+				{current: 5, desired: 9, expected: 9},
+				{offset: 200 * time.Second, current: 9, desired: 2, expected: 9},
+			},
+			// the window of the recommendation 9 ends before the one of the recommendations after restart
+			next:     scalingStep{offset: 310 * time.Second, current: 9, desired: 2},
+			restored: 2,
+			fresh:    9,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{
+				Spec: autoscalingv1alpha1.GeneralPodAutoscalerSpec{MaxReplicas: 20, Behavior: c.behavior},
+			}
+			fakeClock := clock.NewFakeClock(start)
+			leader := newHistoryController(fakeClock)
+			for _, step := range c.steps {
+				fakeClock.SetTime(start.Add(step.offset))
+				leader.restoreScalingHistory(gpa, key)
+				leader.recordInitialRecommendation(step.current, key)
+				desired := leader.normalizeDesiredReplicasWithBehaviors(gpa, key, step.current, step.desired, 1)
+				assert.Equal(t, step.expected, desired)
+				if desired != step.current {
+					leader.storeScaleEvent(gpa.Spec.Behavior, key, step.current, desired)
+				}
+				leader.recordScalingHistory(gpa, key)
+				gpa = persist(t, gpa)
+			}
+
+			fakeClock.SetTime(start.Add(c.next.offset))
+			for _, restore := range []bool{true, false} {
+				next := newHistoryController(fakeClock)
+				restarted := gpa.DeepCopy()
+				expected := c.fresh
+				if restore {
+					next.restoreScalingHistory(restarted, key)
+					expected = c.restored
+				}
+				next.recordInitialRecommendation(c.next.current, key)
+				assert.Equal(t, expected, next.normalizeDesiredReplicasWithBehaviors(restarted, key, c.next.current,
+					c.next.desired, 1), "restore: %v", restore)
+			}
+
+			// the history out of the windows is dropped
+			fakeClock.SetTime(start.Add(time.Hour))
+			leader.recordScalingHistory(gpa, key)
+			assert.Nil(t, gpa.Status.ScalingHistory)
+		})
+	}
+}
+
+func TestScalingHistoryChanged(t *testing.T) {
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	history := func(recommended time.Duration, replicas []int32, scaleUp ...int32) *autoscalingv1alpha1.ScalingHistory {
+		h := &autoscalingv1alpha1.ScalingHistory{}
+		for _, r := range replicas {
+			h.Recommendations = append(h.Recommendations, autoscalingv1alpha1.TimestampedReplicas{
+				Replicas: r, Timestamp: metav1.NewTime(now.Add(-recommended))})
+		}
+		for _, r := range scaleUp {
+			h.ScaleUpEvents = append(h.ScaleUpEvents, autoscalingv1alpha1.TimestampedReplicas{
+				Replicas: r, Timestamp: metav1.NewTime(now.Add(-2 * time.Minute))})
+		}
+		return h
+	}
+	for _, c := range []struct {
+		name     string
+		previous *autoscalingv1alpha1.ScalingHistory
+		history  *autoscalingv1alpha1.ScalingHistory
+		changed  bool
+	}{
+		{
+			name:    "nothing recorded",
+			changed: false,
+		},
+		{
+			name:    "first recommendation",
+			history: history(0, []int32{3}),
+			changed: true,
+		},
+		{
+			name:     "history dropped",
+			previous: history(10*time.Second, []int32{3}),
+			changed:  true,
+		},
+		{
+			name:     "only timestamps changed",
+			previous: history(10*time.Second, []int32{3, 3}, 2),
+			history:  history(0, []int32{3, 3, 3}, 2),
+			changed:  false,
+		},
+		{
+			name:     "recommended replicas changed",
+			previous: history(10*time.Second, []int32{3}),
+			history:  history(0, []int32{3, 4}),
+			changed:  true,
+		},
+		{
+			name:     "scale event recorded",
+			previous: history(10*time.Second, []int32{3}),
+			history:  history(0, []int32{3}, 2),
+			changed:  true,
+		},
+		{
+			name:     "refresh interval passed",
+			previous: history(time.Minute, []int32{3}),
+			history:  history(0, []int32{3}),
+			changed:  true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.changed, scalingHistoryChanged(c.previous, c.history, now))
+		})
+	}
+}
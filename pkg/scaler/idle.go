@@ -0,0 +1,60 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscaling "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// idleMinReplicas returns the min replicas of gpa whose minReplicas is 0: 0 if the signals have proposed
+// zero replicas for the idle period, otherwise 1. status.idleSince is updated with the proposal.
+func (a *GeneralController) idleMinReplicas(gpa *autoscaling.GeneralPodAutoscaler, proposal int32) int32 {
+	if proposal != 0 {
+		gpa.Status.IdleSince = nil
+		return 1
+	}
+	now := a.clock.Now()
+	if gpa.Status.IdleSince == nil {
+		idleSince := metav1.NewTime(now)
+		gpa.Status.IdleSince = &idleSince
+	}
+	var idlePeriod time.Duration
+	if gpa.Spec.Idle != nil {
+		idlePeriod = time.Duration(gpa.Spec.Idle.IdlePeriodSeconds) * time.Second
+	}
+	if now.Sub(gpa.Status.IdleSince.Time) < idlePeriod {
+		return 1
+	}
+	return 0
+}
+
+// activationReplicas returns the replicas gpa is activated from zero with, at least desiredReplicas
+func activationReplicas(gpa *autoscaling.GeneralPodAutoscaler, desiredReplicas int32) int32 {
+	replicas := int32(1)
+	if gpa.Spec.Idle != nil && gpa.Spec.Idle.ActivationReplicas != nil {
+		replicas = *gpa.Spec.Idle.ActivationReplicas
+	}
+	if replicas > gpa.Spec.MaxReplicas {
+		replicas = gpa.Spec.MaxReplicas
+	}
+	if desiredReplicas > replicas {
+		return desiredReplicas
+	}
+	return replicas
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"fmt"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog"
+
+	autoscaling "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/scalercore"
+)
+
+// longRunScalers are the running long run scalers of a gpa
+type longRunScalers struct {
+	// mode is the driven mode which the scalers are built from
+	mode    autoscaling.AutoScalingDrivenMode
+	scalers map[string]scalercore.LongRunScaler
+	// done is closed when the scalers should stop
+	done chan struct{}
+}
+
+// runLongRunScalers keeps one instance for each long run scaler of the gpa. If the gpa has running
+// scalers built from the same spec, the long run scalers in chain are replaced by the running ones,
+// otherwise the running ones are stopped and the long run scalers in chain are started.
+func (a *GeneralController) runLongRunScalers(gpa *autoscaling.GeneralPodAutoscaler, key string,
+	chain []scalercore.Scaler) []scalercore.Scaler {
+	a.longRunScalersLock.Lock()
+	defer a.longRunScalersLock.Unlock()
+
+	running, ok := a.longRunScalers[key]
+	if ok && apiequality.Semantic.DeepEqual(running.mode, gpa.Spec.AutoScalingDrivenMode) {
+		for i, s := range chain {
+			if r, ok := running.scalers[s.ScalerName()]; ok {
+				chain[i] = r
+			}
+		}
+		return chain
+	}
+	if ok {
+		klog.Infof("Spec of GPA %v changed, restart long run scalers", key)
+		close(running.done)
+		delete(a.longRunScalers, key)
+	}
+
+	lrs := &longRunScalers{
+		mode:    *gpa.Spec.AutoScalingDrivenMode.DeepCopy(),
+		scalers: map[string]scalercore.LongRunScaler{},
+		done:    make(chan struct{}),
+	}
+	for _, s := range chain {
+		lr, ok := s.(scalercore.LongRunScaler)
+		if !ok {
+			continue
+		}
+		if n, ok := lr.(scalercore.Notifier); ok {
+			n.SetNotifyFunc(func() {
+				a.queue.Add(key)
+			})
+		}
+		lrs.scalers[lr.ScalerName()] = lr
+	}
+	if len(lrs.scalers) == 0 {
+		return chain
+	}
+	a.longRunScalers[key] = lrs
+
+	// scalers stop if the controller stopped or the scalers are stopped by spec changing or gpa deleting
+	stopCh := make(chan struct{})
+	go func() {
+		defer close(stopCh)
+		select {
+		case <-a.stopCh:
+		case <-lrs.done:
+		}
+	}()
+	for _, lr := range lrs.scalers {
+		go a.runLongRunScaler(key, lr, stopCh)
+	}
+	return chain
+}
+
+// runLongRunScaler runs the scaler until stopCh is closed
+func (a *GeneralController) runLongRunScaler(key string, s scalercore.LongRunScaler, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	klog.Infof("Start long run scaler %v of GPA %v", s.ScalerName(), key)
+	if err := s.Run(stopCh); err != nil {
+		utilruntime.HandleError(fmt.Errorf("long run scaler %v of GPA %v failed: %v", s.ScalerName(), key, err))
+	}
+}
+
+// stopLongRunScalers stops the running long run scalers of the gpa
+func (a *GeneralController) stopLongRunScalers(key string) {
+	a.longRunScalersLock.Lock()
+	defer a.longRunScalersLock.Unlock()
+
+	if running, ok := a.longRunScalers[key]; ok {
+		klog.Infof("Stop long run scalers of GPA %v", key)
+		close(running.done)
+		delete(a.longRunScalers, key)
+	}
+}
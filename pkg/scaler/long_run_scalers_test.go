@@ -0,0 +1,169 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	autoscaling "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/scalercore"
+)
+
+const pushTrigger = "test-push"
+
+var (
+	pushScalers     []*pushScaler
+	pushScalersLock sync.Mutex
+)
+
+func init() {
+	scalercore.RegisterTrigger(pushTrigger, func(trigger autoscaling.ScaleTriggers) (scalercore.Scaler, error) {
+		s := &pushScaler{name: trigger.Name, started: make(chan struct{}), stopped: make(chan struct{})}
+		pushScalersLock.Lock()
+		pushScalers = append(pushScalers, s)
+		pushScalersLock.Unlock()
+		return s, nil
+	})
+}
+
+// pushScaler is a long run trigger which pushes its signal by notify
+type pushScaler struct {
+	name    string
+	notify  func()
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (s *pushScaler) GetReplicas(gpa *autoscaling.GeneralPodAutoscaler, currentReplicas int32) (int32, error) {
+	return currentReplicas, nil
+}
+
+func (s *pushScaler) ScalerName() string {
+	return s.name
+}
+
+func (s *pushScaler) SetNotifyFunc(notify func()) {
+	s.notify = notify
+}
+
+func (s *pushScaler) Run(stopCh <-chan struct{}) error {
+	close(s.started)
+	<-stopCh
+	close(s.stopped)
+	return nil
+}
+
+func lastPushScaler(t *testing.T, count int) *pushScaler {
+	pushScalersLock.Lock()
+	defer pushScalersLock.Unlock()
+	if len(pushScalers) != count {
+		t.Fatalf("desired %v push scalers built, actual: %v", count, len(pushScalers))
+	}
+	return pushScalers[count-1]
+}
+
+func waitClosed(t *testing.T, ch chan struct{}, msg string) {
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal(msg)
+	}
+}
+
+func assertNotClosed(t *testing.T, ch chan struct{}, msg string) {
+	select {
+	case <-ch:
+		t.Fatal(msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLongRunScalerLifecycle(t *testing.T) {
+	pushScalersLock.Lock()
+	pushScalers = nil
+	pushScalersLock.Unlock()
+
+	stopCh := make(chan struct{})
+	a := &GeneralController{
+		queue:               workqueue.NewNamedRateLimitingQueue(NewDefaultGPARateLimiter(time.Minute), "test"),
+		longRunScalers:      map[string]*longRunScalers{},
+		transports:          scalercore.NewTransportCache(),
+		scheduleTransitions: scalercore.NewScheduleTransitionCache(),
+		stopCh:              stopCh,
+	}
+	defer a.queue.ShutDown()
+
+	key := "test-namespace/test-gpa"
+	gpa := &autoscaling.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"},
+		Spec: autoscaling.GeneralPodAutoscalerSpec{
+			AutoScalingDrivenMode: autoscaling.AutoScalingDrivenMode{
+				EventMode: &autoscaling.EventMode{
+					Triggers: []autoscaling.ScaleTriggers{
+						{Type: pushTrigger, Name: "queue", Metadata: map[string]string{"queue": "a"}},
+					},
+				},
+			},
+		},
+	}
+
+	// started once, and kept running for the same spec
+	chain := a.buildScalerChain(gpa, key)
+	first := lastPushScaler(t, 1)
+	waitClosed(t, first.started, "long run scaler should be started")
+	if again := a.buildScalerChain(gpa, key); again[0] != chain[0] {
+		t.Fatal("running scaler should be reused for the same spec")
+	}
+	assertNotClosed(t, first.stopped, "long run scaler should keep running for the same spec")
+
+	// notify enqueues the gpa immediately
+	first.notify()
+	if a.queue.Len() != 1 {
+		t.Fatalf("desired 1 key in queue, actual: %v", a.queue.Len())
+	}
+	item, _ := a.queue.Get()
+	if item.(string) != key {
+		t.Fatalf("desired key: %v, actual: %v", key, item)
+	}
+	a.queue.Done(item)
+
+	// restarted if spec changed
+	updated := gpa.DeepCopy()
+	updated.Spec.EventMode.Triggers[0].Metadata["queue"] = "b"
+	a.updateGPA(gpa, updated)
+	waitClosed(t, first.stopped, "long run scaler should be stopped when spec changed")
+	a.buildScalerChain(updated, key)
+	second := lastPushScaler(t, 3)
+	waitClosed(t, second.started, "long run scaler should be restarted when spec changed")
+
+	// stopped if gpa deleted
+	a.deleteGPA(updated)
+	waitClosed(t, second.stopped, "long run scaler should be stopped when gpa deleted")
+	if len(a.longRunScalers) != 0 {
+		t.Fatalf("desired no long run scalers, actual: %v", len(a.longRunScalers))
+	}
+
+	// stopped if controller stopped
+	a.buildScalerChain(updated, key)
+	third := lastPushScaler(t, 4)
+	waitClosed(t, third.started, "long run scaler should be started")
+	close(stopCh)
+	waitClosed(t, third.stopped, "long run scaler should be stopped when controller stopped")
+}
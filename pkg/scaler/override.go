@@ -0,0 +1,87 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+
+	autoscaling "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// overrideInForce returns whether the override has not expired at now
+func overrideInForce(override *autoscaling.ScaleOverride, now time.Time) bool {
+	if override == nil {
+		return false
+	}
+	return override.ExpirationTime == nil || now.Before(override.ExpirationTime.Time)
+}
+
+// overridden returns whether gpa was overridden in the last reconcile
+func overridden(gpa *autoscaling.GeneralPodAutoscaler) bool {
+	for _, cond := range gpa.Status.Conditions {
+		if cond.Type == autoscaling.ScalingOverridden {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// applyOverride replaces the desired replicas with the ones held by the override of gpa if it is in force,
+// the recommendation is reported in the condition only. It returns the desired replicas, whether to
+// rescale and the reason of rescale.
+func (a *GeneralController) applyOverride(gpa *autoscaling.GeneralPodAutoscaler, currentReplicas,
+	desiredReplicas int32, rescale bool, rescaleReason string) (int32, bool, string) {
+	override := gpa.Spec.Override
+	wasOverridden := overridden(gpa)
+	if !overrideInForce(override, a.clock.Now()) {
+		if wasOverridden {
+			reason, message := "OverrideRemoved", "the override is removed, the autoscaling takes back control"
+			if override != nil {
+				reason, message = "OverrideExpired", "the override expired, the autoscaling takes back control"
+			}
+			setCondition(gpa, autoscaling.ScalingOverridden, v1.ConditionFalse, reason, message)
+			a.eventRecorder.Event(gpa, v1.EventTypeNormal, reason, message)
+		}
+		return desiredReplicas, rescale, rescaleReason
+	}
+
+	recommendation := currentReplicas
+	if rescale {
+		recommendation = desiredReplicas
+	}
+	reason, message := "Paused", "the autoscaling is paused"
+	heldReplicas := currentReplicas
+	if override.Replicas != nil {
+		heldReplicas = *override.Replicas
+		reason, message = "HeldAtReplicas", fmt.Sprintf("the replicas are held at %d", heldReplicas)
+	}
+	if override.ExpirationTime != nil {
+		message = fmt.Sprintf("%s until %s", message, override.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	if len(override.Reason) != 0 {
+		message = fmt.Sprintf("%s: %s", message, override.Reason)
+	}
+	if !wasOverridden {
+		a.eventRecorder.Event(gpa, v1.EventTypeNormal, "OverrideStarted", message)
+	}
+	setCondition(gpa, autoscaling.ScalingOverridden, v1.ConditionTrue, reason,
+		"%s, the recommendation is %d replicas", message, recommendation)
+	klog.V(4).Infof("%s is overridden, held: %v, recommendation: %v", gpa.Name, heldReplicas, recommendation)
+	return heldReplicas, heldReplicas != currentReplicas, "Held by override"
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+
+	autoscalingv1alpha1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+func TestApplyOverride(t *testing.T) {
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiration := metav1.NewTime(start.Add(time.Hour))
+	fakeClock := clock.NewFakeClock(start)
+	recorder := record.NewFakeRecorder(10)
+	a := &GeneralController{clock: fakeClock, eventRecorder: recorder}
+	gpa := &autoscalingv1alpha1.GeneralPodAutoscaler{}
+
+	for _, c := range []struct {
+		name     string
+		override *autoscalingv1alpha1.ScaleOverride
+		offset   time.Duration
+		// the autoscaling recommends 6 replicas with 4 current replicas
+		desired int32
+		rescale bool
+		reason  string
+		// status of condition ScalingOverridden, empty if it is not set
+		condition v1.ConditionStatus
+		event     string
+	}{
+		{name: "no override", desired: 6, rescale: true, reason: "metric above target"},
+		{name: "paused", override: &autoscalingv1alpha1.ScaleOverride{Reason: "incident"},
+			desired: 4, rescale: false, reason: "Held by override", condition: v1.ConditionTrue, event: "OverrideStarted"},
+		{name: "held", override: &autoscalingv1alpha1.ScaleOverride{Replicas: int32Ptr(8), ExpirationTime: &expiration},
+			desired: 8, rescale: true, reason: "Held by override", condition: v1.ConditionTrue},
+		{name: "held at current replicas", override: &autoscalingv1alpha1.ScaleOverride{Replicas: int32Ptr(4),
+			ExpirationTime: &expiration}, desired: 4, rescale: false, reason: "Held by override", condition: v1.ConditionTrue},
+		{name: "expired", override: &autoscalingv1alpha1.ScaleOverride{Replicas: int32Ptr(8), ExpirationTime: &expiration},
+			offset: time.Hour, desired: 6, rescale: true, reason: "metric above target", condition: v1.ConditionFalse,
+			event: "OverrideExpired"},
+		{name: "held again", override: &autoscalingv1alpha1.ScaleOverride{Replicas: int32Ptr(8)}, offset: time.Hour,
+			desired: 8, rescale: true, reason: "Held by override", condition: v1.ConditionTrue, event: "OverrideStarted"},
+		{name: "removed", offset: time.Hour, desired: 6, rescale: true, reason: "metric above target",
+			condition: v1.ConditionFalse, event: "OverrideRemoved"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			fakeClock.SetTime(start.Add(c.offset))
+			gpa.Spec.Override = c.override
+			desired, rescale, reason := a.applyOverride(gpa, 4, 6, true, "metric above target")
+			assert.Equal(t, c.desired, desired)
+			assert.Equal(t, c.rescale, rescale)
+			assert.Equal(t, c.reason, reason)
+			if c.condition == "" {
+				assert.Empty(t, gpa.Status.Conditions)
+			} else {
+				assert.Equal(t, autoscalingv1alpha1.ScalingOverridden, gpa.Status.Conditions[0].Type)
+				assert.Equal(t, c.condition, gpa.Status.Conditions[0].Status)
+			}
+			select {
+			case event := <-recorder.Events:
+				assert.Contains(t, event, c.event)
+			default:
+				assert.Empty(t, c.event, "the event should be recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+)
+
+const (
+	// defaultBatchWindow is how long a call waits for the calls of other GPAs before the batch is sent
+	defaultBatchWindow = 100 * time.Millisecond
+	// defaultBatchMaxSize is the max number of requests in a batch
+	defaultBatchMaxSize = 100
+)
+
+// batchKey identifies the webhook shared by GPAs, the client is the same for the same CA bundle, client
+// certificate and endpoint
+type batchKey struct {
+	client *http.Client
+	url    string
+	// token is the sha256 of bearer token
+	token string
+}
+
+// batchItem is a call waiting in batch, done is closed when the review or err is set
+type batchItem struct {
+	request *requests.AutoscaleRequest
+	review  *requests.AutoscaleReview
+	err     error
+	done    chan struct{}
+}
+
+// pendingBatch is the calls grouped for a webhook, the batch is sent by the first call
+type pendingBatch struct {
+	call  *webhookCall
+	items []*batchItem
+	sent  bool
+}
+
+// WebhookBatcher groups the calls of GPAs sharing a webhook within a window, and sends them in one
+// AutoscaleBatchReview. The response of each request is fanned back to the call of it.
+type WebhookBatcher struct {
+	lock    sync.Mutex
+	pending map[batchKey]*pendingBatch
+}
+
+// NewWebhookBatcher returns a batcher without pending calls
+func NewWebhookBatcher() *WebhookBatcher {
+	return &WebhookBatcher{pending: map[batchKey]*pendingBatch{}}
+}
+
+// do adds the call to the batch of its webhook and waits for the review answered for it. The batch is sent
+// after the window of the first call in it, or once it has maxSize calls.
+func (b *WebhookBatcher) do(ctx context.Context, call *webhookCall, batch *autoscalingv1.WebhookBatch) (
+	*requests.AutoscaleReview, error) {
+	window, maxSize := time.Duration(defaultBatchWindow), int32(defaultBatchMaxSize)
+	if batch.Window != nil {
+		window = batch.Window.Duration
+	}
+	if batch.MaxSize != nil {
+		maxSize = *batch.MaxSize
+	}
+	key := batchKey{client: call.client, url: call.url.String()}
+	if len(call.token) != 0 {
+		key.token = sha256Hex([]byte(call.token))
+	}
+	item := &batchItem{request: call.review.Request, done: make(chan struct{})}
+
+	b.lock.Lock()
+	p, ok := b.pending[key]
+	if !ok {
+		p = &pendingBatch{call: call}
+		b.pending[key] = p
+		time.AfterFunc(window, func() {
+			b.flush(key, p)
+		})
+	}
+	p.items = append(p.items, item)
+	full := int32(len(p.items)) >= maxSize
+	if full {
+		// the full batch is taken out under the lock, so that the later calls start a new batch
+		p.sent = true
+		delete(b.pending, key)
+	}
+	b.lock.Unlock()
+	if full {
+		go b.deliver(p)
+	}
+
+	select {
+	case <-item.done:
+		return item.review, item.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flush sends the batch if it is not sent
+func (b *WebhookBatcher) flush(key batchKey, p *pendingBatch) {
+	b.lock.Lock()
+	if p.sent {
+		b.lock.Unlock()
+		return
+	}
+	p.sent = true
+	if b.pending[key] == p {
+		delete(b.pending, key)
+	}
+	b.lock.Unlock()
+	b.deliver(p)
+}
+
+// deliver sends the batch taken out of pending, and fans the reviews back to the calls
+func (b *WebhookBatcher) deliver(p *pendingBatch) {
+	reviews, err := b.send(p)
+	for _, item := range p.items {
+		switch {
+		case err != nil:
+			item.err = err
+		case reviews[item.request.UID] == nil:
+			item.err = &WebhookRejectedError{Reason: ReasonInvalidResponse,
+				Message: fmt.Sprintf("no response of request %q in batch", item.request.UID)}
+		default:
+			item.review = reviews[item.request.UID]
+		}
+		close(item.done)
+	}
+}
+
+// send posts the requests of batch, and returns the review answered of each request by uid
+func (b *WebhookBatcher) send(p *pendingBatch) (map[types.UID]*requests.AutoscaleReview, error) {
+	batch := requests.AutoscaleBatchReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: requests.APIVersion, Kind: requests.BatchKind},
+	}
+	for _, item := range p.items {
+		batch.Requests = append(batch.Requests, *item.request)
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+	// the batch is sent with the timeout of the first call
+	ctx, cancel := context.WithTimeout(context.Background(), p.call.timeout)
+	defer cancel()
+	klog.V(4).Infof("Send batch of %v requests to %v", len(batch.Requests), p.call.url)
+	code, result, err := postJSON(ctx, p.call.client, p.call.url, p.call.token, body)
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, badStatusCode(code, p.call.url)
+	}
+	var answer requests.AutoscaleBatchReview
+	if err := json.Unmarshal(result, &answer); err != nil {
+		return nil, err
+	}
+	reviews := map[types.UID]*requests.AutoscaleReview{}
+	for i := range answer.Responses {
+		reviews[answer.Responses[i].UID] = &requests.AutoscaleReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: answer.APIVersion, Kind: requests.Kind},
+			Response: &answer.Responses[i],
+		}
+	}
+	return reviews, nil
+}
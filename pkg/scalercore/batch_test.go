@@ -0,0 +1,151 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+)
+
+// batchWebhook answers each request of batch with its current replicas plus one, the requests of
+// workload named skip are not answered
+type batchWebhook struct {
+	lock    sync.Mutex
+	batches [][]string
+	skip    string
+}
+
+func (b *batchWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var review requests.AutoscaleBatchReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Kind != requests.BatchKind {
+		http.Error(w, "batch review desired", http.StatusBadRequest)
+		return
+	}
+	var names []string
+	for _, req := range review.Requests {
+		names = append(names, req.Name)
+		if req.Name == b.skip {
+			continue
+		}
+		review.Responses = append(review.Responses, requests.AutoscaleResponse{UID: req.UID, Scale: true,
+			Replicas: req.CurrentReplicas + 1})
+	}
+	b.lock.Lock()
+	b.batches = append(b.batches, names)
+	b.lock.Unlock()
+	json.NewEncoder(w).Encode(review)
+}
+
+// getReplicasInBatch calls the webhook for count gpas concurrently, the gpa i has i replicas
+func getReplicasInBatch(t *testing.T, url string, batch *autoscalingv1.WebhookBatch, count int) ([]int32, []error) {
+	transports, batcher := NewTransportCache(), NewWebhookBatcher()
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url},
+		Batch:               batch,
+	}
+	replicas, errs := make([]int32, count), make([]error, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("squad-%d", i)
+			gpa := &autoscalingv1.GeneralPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-namespace"},
+				Spec:       autoscalingv1.GeneralPodAutoscalerSpec{ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Name: name}},
+			}
+			s := NewWebhookScaler(mode, transports, batcher, nil, "test-namespace/"+name, clock.RealClock{})
+			replicas[i], errs[i] = s.GetReplicas(gpa, int32(i))
+		}(i)
+	}
+	wg.Wait()
+	return replicas, errs
+}
+
+func TestWebhookBatch(t *testing.T) {
+	webhook := &batchWebhook{skip: "squad-3"}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	replicas, errs := getReplicasInBatch(t, server.URL, &autoscalingv1.WebhookBatch{Window: &metav1.Duration{Duration: 500 * time.Millisecond}}, 10)
+	webhook.lock.Lock()
+	defer webhook.lock.Unlock()
+	if len(webhook.batches) != 1 || len(webhook.batches[0]) != 10 {
+		t.Fatalf("desired 1 batch of 10 requests, actual: %v", webhook.batches)
+	}
+	for i := range replicas {
+		if i == 3 {
+			if _, ok := errs[i].(*WebhookRejectedError); !ok {
+				t.Errorf("gpa without response should be rejected, actual: %v", errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("gpa %v: %v", i, errs[i])
+		} else if replicas[i] != int32(i+1) {
+			t.Errorf("gpa %v: desired replicas: %v, actual: %v", i, i+1, replicas[i])
+		}
+	}
+}
+
+func TestWebhookBatchMaxSize(t *testing.T) {
+	webhook := &batchWebhook{}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	// the batches are sent once full, not after the window
+	start := time.Now()
+	_, errs := getReplicasInBatch(t, server.URL, &autoscalingv1.WebhookBatch{Window: &metav1.Duration{Duration: time.Minute},
+		MaxSize: intPtr(4)}, 8)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("full batches should be sent immediately, actual: %v", elapsed)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("gpa %v: %v", i, err)
+		}
+	}
+	webhook.lock.Lock()
+	defer webhook.lock.Unlock()
+	if len(webhook.batches) != 2 || len(webhook.batches[0]) != 4 || len(webhook.batches[1]) != 4 {
+		t.Errorf("desired 2 batches of 4 requests, actual: %v", webhook.batches)
+	}
+}
+
+func TestWebhookBatchFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, errs := getReplicasInBatch(t, server.URL, &autoscalingv1.WebhookBatch{}, 3)
+	for i, err := range errs {
+		if rejected, ok := err.(*WebhookRejectedError); !ok || rejected.Code != http.StatusServiceUnavailable {
+			t.Errorf("gpa %v: desired failure of batch, actual: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+const (
+	// defaultMaxRetries is the max number of retries of a failed webhook call
+	defaultMaxRetries = 2
+	// defaultRetryBackoff is the interval before the first retry
+	defaultRetryBackoff = 200 * time.Millisecond
+	// defaultMaxRetryBackoff is the max interval between retries
+	defaultMaxRetryBackoff = 2 * time.Second
+	// defaultFailureThreshold is the number of consecutive failed calls which opens the breaker
+	defaultFailureThreshold = 3
+	// defaultOpenDuration is how long the breaker keeps open before the webhook is tried again
+	defaultOpenDuration = time.Minute
+)
+
+// retryPolicy is the retry policy of webhook calls with defaults applied
+type retryPolicy struct {
+	maxRetries int32
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// newRetryPolicy returns the policy of retry, a call is not retried if retry is nil
+func newRetryPolicy(retry *autoscalingv1.WebhookRetry) retryPolicy {
+	policy := retryPolicy{backoff: defaultRetryBackoff, maxBackoff: defaultMaxRetryBackoff}
+	if retry == nil {
+		return policy
+	}
+	policy.maxRetries = defaultMaxRetries
+	if retry.MaxRetries != nil {
+		policy.maxRetries = *retry.MaxRetries
+	}
+	if retry.Backoff != nil {
+		policy.backoff = retry.Backoff.Duration
+	}
+	if retry.MaxBackoff != nil {
+		policy.maxBackoff = retry.MaxBackoff.Duration
+	}
+	return policy
+}
+
+// retryable returns whether the failed call should be retried and counted by the breaker, it is true
+// if the webhook gives no answer, or answers 429 or 5xx
+func retryable(err error) bool {
+	rejected, ok := err.(*WebhookRejectedError)
+	if !ok {
+		return true
+	}
+	return rejected.Code == http.StatusTooManyRequests || rejected.Code >= http.StatusInternalServerError
+}
+
+// circuitBreaker decides whether the webhook is called by the breaker state in status of gpa
+type circuitBreaker struct {
+	config *autoscalingv1.WebhookCircuitBreaker
+	status *autoscalingv1.WebhookBreakerStatus
+}
+
+// newCircuitBreaker returns the breaker of config starting from the previous state, a closed breaker
+// is returned if there is no previous state
+func newCircuitBreaker(config *autoscalingv1.WebhookCircuitBreaker,
+	previous *autoscalingv1.WebhookBreakerStatus, now time.Time) *circuitBreaker {
+	status := &autoscalingv1.WebhookBreakerStatus{State: autoscalingv1.BreakerClosed,
+		LastTransitionTime: metav1.NewTime(now)}
+	if previous != nil {
+		status = previous.DeepCopy()
+	}
+	return &circuitBreaker{config: config, status: status}
+}
+
+// allow returns whether the webhook should be called, the webhook is tried once the breaker has been
+// open for openDuration
+func (b *circuitBreaker) allow(now time.Time) bool {
+	if b.status.State != autoscalingv1.BreakerOpen {
+		return true
+	}
+	openDuration := defaultOpenDuration
+	if b.config.OpenDuration != nil {
+		openDuration = b.config.OpenDuration.Duration
+	}
+	return !now.Before(b.status.LastTransitionTime.Add(openDuration))
+}
+
+// succeed closes the breaker and records the replicas as the last good answer
+func (b *circuitBreaker) succeed(replicas int32, now time.Time) {
+	if b.status.State != autoscalingv1.BreakerClosed {
+		b.status.State = autoscalingv1.BreakerClosed
+		b.status.LastTransitionTime = metav1.NewTime(now)
+	}
+	b.status.ConsecutiveFailures = 0
+	b.status.LastGoodReplicas = &replicas
+	t := metav1.NewTime(now)
+	b.status.LastGoodTime = &t
+}
+
+// fail counts the failed call, the breaker opens if the failures reach the threshold, and opens again
+// if the try of an open breaker fails
+func (b *circuitBreaker) fail(err error, now time.Time) {
+	threshold := int32(defaultFailureThreshold)
+	if b.config.FailureThreshold != nil {
+		threshold = *b.config.FailureThreshold
+	}
+	b.status.ConsecutiveFailures++
+	b.status.LastError = err.Error()
+	if b.status.State == autoscalingv1.BreakerOpen || b.status.ConsecutiveFailures >= threshold {
+		b.status.State = autoscalingv1.BreakerOpen
+		b.status.LastTransitionTime = metav1.NewTime(now)
+	}
+}
+
+// open returns whether the breaker is open
+func (b *circuitBreaker) open() bool {
+	return b.status.State == autoscalingv1.BreakerOpen
+}
+
+// fallback returns the replicas recommended by the fallback policy
+func (b *circuitBreaker) fallback(gpa *autoscalingv1.GeneralPodAutoscaler, currentReplicas int32) int32 {
+	switch b.config.Fallback {
+	case autoscalingv1.LastGoodFallback:
+		if b.status.LastGoodReplicas != nil {
+			return *b.status.LastGoodReplicas
+		}
+	case autoscalingv1.MinFallback:
+		if gpa.Spec.MinReplicas != nil {
+			return *gpa.Spec.MinReplicas
+		}
+		return 1
+	}
+	return currentReplicas
+}
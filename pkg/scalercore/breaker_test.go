@@ -0,0 +1,366 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+)
+
+// flakyWebhook answers 503 to the first failures calls, then answers replicas
+type flakyWebhook struct {
+	failures int32
+	replicas int32
+	calls    int32
+}
+
+func (f *flakyWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var review requests.AutoscaleReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if atomic.AddInt32(&f.calls, 1) <= atomic.LoadInt32(&f.failures) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	review.Response = &requests.AutoscaleResponse{UID: review.Request.UID, Scale: true, Replicas: f.replicas}
+	json.NewEncoder(w).Encode(review)
+}
+
+func millis(n int) *metav1.Duration {
+	return &metav1.Duration{Duration: time.Duration(n) * time.Millisecond}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	for _, c := range []struct {
+		name     string
+		retry    *autoscalingv1.WebhookRetry
+		failures int32
+		calls    int32
+		hasErr   bool
+	}{
+		{name: "no retry", failures: 1, calls: 1, hasErr: true},
+		{name: "succeed after retries", retry: &autoscalingv1.WebhookRetry{Backoff: millis(1)}, failures: 2, calls: 3},
+		{name: "retries exhausted", retry: &autoscalingv1.WebhookRetry{MaxRetries: intPtr(1), Backoff: millis(1)},
+			failures: 2, calls: 2, hasErr: true},
+	} {
+		webhook := &flakyWebhook{failures: c.failures, replicas: 5}
+		server := httptest.NewServer(webhook)
+		mode := &autoscalingv1.WebhookMode{
+			WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+			Retry:               c.retry,
+		}
+		replicas, err := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 3)
+		server.Close()
+		if (err != nil) != c.hasErr {
+			t.Errorf("%v: desired error: %v, actual: %v", c.name, c.hasErr, err)
+		}
+		if err == nil && replicas != 5 {
+			t.Errorf("%v: desired replicas: 5, actual: %v", c.name, replicas)
+		}
+		if webhook.calls != c.calls {
+			t.Errorf("%v: desired calls: %v, actual: %v", c.name, c.calls, webhook.calls)
+		}
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		webhookHandler(5)(w, r)
+	}))
+	defer server.Close()
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+		Timeout:             millis(20),
+	}
+	start := time.Now()
+	if _, err := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 3); err == nil {
+		t.Fatal("call exceeding the timeout should fail")
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("call should fail after timeout, actual: %v", elapsed)
+	}
+}
+
+func TestWebhookRetryTimeout(t *testing.T) {
+	webhook := &flakyWebhook{failures: 100, replicas: 5}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+		Timeout:             millis(100),
+		Retry:               &autoscalingv1.WebhookRetry{MaxRetries: intPtr(10), Backoff: millis(40), MaxBackoff: millis(40)},
+	}
+	start := time.Now()
+	if _, err := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 3); err == nil {
+		t.Fatal("call failing in all retries should fail")
+	}
+	// the retries stop at the timeout of the whole call
+	if elapsed := time.Since(start); elapsed >= 300*time.Millisecond {
+		t.Errorf("retries should stop after timeout, actual: %v", elapsed)
+	}
+	if calls := atomic.LoadInt32(&webhook.calls); calls >= 11 {
+		t.Errorf("desired less than 11 calls, actual: %v", calls)
+	}
+}
+
+func TestWebhookCircuitBreaker(t *testing.T) {
+	webhook := &flakyWebhook{replicas: 5}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+	gpa := &autoscalingv1.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"},
+		Spec:       autoscalingv1.GeneralPodAutoscalerSpec{MinReplicas: intPtr(2), MaxReplicas: 10},
+	}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+		CircuitBreaker: &autoscalingv1.WebhookCircuitBreaker{FailureThreshold: intPtr(2),
+			OpenDuration: &metav1.Duration{Duration: time.Minute}, Fallback: autoscalingv1.LastGoodFallback},
+	}
+	s := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", fakeClock).(*WebhookScaler)
+	// getReplicas calls the scaler as the controller does, the breaker state is kept in status
+	getReplicas := func(step string, desired int32, hasErr bool, state autoscalingv1.WebhookBreakerState, calls int32) {
+		replicas, err := s.GetReplicas(gpa, 3)
+		gpa.Status.WebhookBreaker = s.BreakerStatus()
+		if (err != nil) != hasErr {
+			t.Fatalf("%v: desired error: %v, actual: %v", step, hasErr, err)
+		}
+		if err == nil && replicas != desired {
+			t.Errorf("%v: desired replicas: %v, actual: %v", step, desired, replicas)
+		}
+		if gpa.Status.WebhookBreaker.State != state {
+			t.Errorf("%v: desired state: %v, actual: %v", step, state, gpa.Status.WebhookBreaker.State)
+		}
+		if actual := atomic.LoadInt32(&webhook.calls); actual != calls {
+			t.Errorf("%v: desired calls: %v, actual: %v", step, calls, actual)
+		}
+	}
+
+	getReplicas("success", 5, false, autoscalingv1.BreakerClosed, 1)
+	atomic.StoreInt32(&webhook.failures, 100)
+	getReplicas("failure under threshold", 0, true, autoscalingv1.BreakerClosed, 2)
+	getReplicas("failure opens breaker", 5, false, autoscalingv1.BreakerOpen, 3)
+	if gpa.Status.WebhookBreaker.ConsecutiveFailures != 2 || len(gpa.Status.WebhookBreaker.LastError) == 0 {
+		t.Errorf("desired 2 failures with error, actual: %+v", gpa.Status.WebhookBreaker)
+	}
+
+	fakeClock.Step(30 * time.Second)
+	getReplicas("open breaker falls back", 5, false, autoscalingv1.BreakerOpen, 3)
+	mode.CircuitBreaker.Fallback = autoscalingv1.MinFallback
+	getReplicas("min fallback", 2, false, autoscalingv1.BreakerOpen, 3)
+	mode.CircuitBreaker.Fallback = autoscalingv1.KeepCurrentFallback
+	getReplicas("keep current fallback", 3, false, autoscalingv1.BreakerOpen, 3)
+
+	fakeClock.Step(30 * time.Second)
+	getReplicas("failed try keeps breaker open", 3, false, autoscalingv1.BreakerOpen, 4)
+	if !gpa.Status.WebhookBreaker.LastTransitionTime.Time.Equal(fakeClock.Now()) {
+		t.Errorf("breaker should be opened again at %v, actual: %v", fakeClock.Now(),
+			gpa.Status.WebhookBreaker.LastTransitionTime)
+	}
+
+	atomic.StoreInt32(&webhook.failures, 0)
+	fakeClock.Step(59 * time.Second)
+	getReplicas("breaker open in duration", 3, false, autoscalingv1.BreakerOpen, 4)
+	fakeClock.Step(time.Second)
+	getReplicas("successful try closes breaker", 5, false, autoscalingv1.BreakerClosed, 5)
+	if gpa.Status.WebhookBreaker.ConsecutiveFailures != 0 || *gpa.Status.WebhookBreaker.LastGoodReplicas != 5 {
+		t.Errorf("desired no failures and last good replicas 5, actual: %+v", gpa.Status.WebhookBreaker)
+	}
+}
+
+func TestWebhookCircuitBreakerCacheHit(t *testing.T) {
+	webhook := &flakyWebhook{replicas: 5, failures: 100}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+		CircuitBreaker: &autoscalingv1.WebhookCircuitBreaker{FailureThreshold: intPtr(2),
+			OpenDuration: &metav1.Duration{Duration: time.Minute}, Fallback: autoscalingv1.LastGoodFallback},
+	}
+	s := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", fakeClock).(*WebhookScaler)
+	gpa.Status.WebhookBreaker = &autoscalingv1.WebhookBreakerStatus{State: autoscalingv1.BreakerClosed,
+		ConsecutiveFailures: 1, LastError: "bad status code 503", LastGoodReplicas: intPtr(4)}
+	gpa.Status.WebhookCache = &autoscalingv1.WebhookCachedResponse{Scale: true, Replicas: 7,
+		ExpirationTime: metav1.NewTime(fakeClock.Now().Add(30 * time.Second)), ConfigHash: s.configHash()}
+
+	// the cache hit keeps the breaker in status
+	previous := gpa.Status.WebhookBreaker.DeepCopy()
+	if replicas, err := s.GetReplicas(gpa, 3); err != nil || replicas != 7 {
+		t.Fatalf("desired replicas 7 of cache, actual: %v, %v", replicas, err)
+	}
+	gpa.Status.WebhookBreaker = s.BreakerStatus()
+	if !apiequality.Semantic.DeepEqual(previous, gpa.Status.WebhookBreaker) {
+		t.Errorf("desired breaker: %+v, actual: %+v", previous, gpa.Status.WebhookBreaker)
+	}
+
+	// the failure after the cache hit opens the breaker, the last good replicas is the fallback
+	fakeClock.Step(time.Minute)
+	gpa.Status.WebhookCache = nil
+	if replicas, err := s.GetReplicas(gpa, 3); err != nil || replicas != 4 {
+		t.Fatalf("desired replicas 4 of last good, actual: %v, %v", replicas, err)
+	}
+	gpa.Status.WebhookBreaker = s.BreakerStatus()
+	if gpa.Status.WebhookBreaker.State != autoscalingv1.BreakerOpen || gpa.Status.WebhookBreaker.ConsecutiveFailures != 2 {
+		t.Errorf("desired breaker open after 2 failures, actual: %+v", gpa.Status.WebhookBreaker)
+	}
+}
+
+func TestWebhookResponseCache(t *testing.T) {
+	var replicas, failing, calls int32 = 5, 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var review requests.AutoscaleReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review.Response = &requests.AutoscaleResponse{UID: review.Request.UID, Scale: true,
+			Replicas: atomic.LoadInt32(&replicas), TTLSeconds: intPtr(60), MinReplicas: intPtr(2)}
+		json.NewEncoder(w).Encode(review)
+	}))
+	defer server.Close()
+
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	mode := &autoscalingv1.WebhookMode{WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL}}
+	s := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", fakeClock).(*WebhookScaler)
+	// getReplicas calls the scaler as the controller does, the cached answer is kept in status
+	getReplicas := func(step string, desired int32, desiredCalls int32, desiredStale bool) {
+		got, err := s.GetReplicas(gpa, 3)
+		if err != nil {
+			t.Fatalf("%v: %v", step, err)
+		}
+		cache, used, stale := s.CachedResponse()
+		gpa.Status.WebhookCache = cache
+		if !used {
+			t.Errorf("%v: the replicas should be answered by the cache", step)
+		}
+		if got != desired {
+			t.Errorf("%v: desired replicas: %v, actual: %v", step, desired, got)
+		}
+		if calls != desiredCalls {
+			t.Errorf("%v: desired calls: %v, actual: %v", step, desiredCalls, calls)
+		}
+		if stale != desiredStale {
+			t.Errorf("%v: desired stale: %v, actual: %v", step, desiredStale, stale)
+		}
+		// the limits of cached answer are used as the replicas
+		if bounds, boundsOnly := s.Bounds(); bounds == nil || *bounds.MinReplicas != 2 || boundsOnly {
+			t.Errorf("%v: desired min replicas 2 with replicas, actual: %+v, %v", step, bounds, boundsOnly)
+		}
+	}
+
+	getReplicas("first call", 5, 1, false)
+	atomic.StoreInt32(&replicas, 7)
+	fakeClock.Step(30 * time.Second)
+	getReplicas("answer cached", 5, 1, false)
+	fakeClock.Step(30 * time.Second)
+	getReplicas("answer expired", 7, 2, false)
+
+	atomic.StoreInt32(&failing, 1)
+	fakeClock.Step(time.Minute)
+	getReplicas("refresh failed", 7, 3, true)
+	getReplicas("refresh failed again", 7, 4, true)
+
+	atomic.StoreInt32(&failing, 0)
+	atomic.StoreInt32(&replicas, 4)
+	getReplicas("refreshed", 4, 5, false)
+	if expiration := gpa.Status.WebhookCache.ExpirationTime.Time; !expiration.Equal(fakeClock.Now().Add(time.Minute)) {
+		t.Errorf("desired expiration: %v, actual: %v", fakeClock.Now().Add(time.Minute), expiration)
+	}
+
+	atomic.StoreInt32(&replicas, 6)
+	mode.Timeout = &metav1.Duration{Duration: 5 * time.Second}
+	getReplicas("webhook mode changed", 6, 6, false)
+	if !s.CacheDropped() {
+		t.Errorf("the answer cached before the webhook mode changed should be dropped")
+	}
+
+	// the defaults of service are not written to the webhook mode, the answer cached is kept
+	service := &autoscalingv1.WebhookMode{WebhookClientConfig: &admregv1b.WebhookClientConfig{
+		Service: &admregv1b.ServiceReference{Name: "gpa-webhook"}}}
+	s = NewWebhookScaler(service, nil, nil, nil, "test-namespace/test-gpa", fakeClock).(*WebhookScaler)
+	hash := s.configHash()
+	if _, err := s.newCall(gpa, 3); err != nil {
+		t.Fatal(err)
+	}
+	if s.configHash() != hash {
+		t.Errorf("the webhook mode of service should not be changed by the call: %+v", service.Service)
+	}
+	gpa.Status.WebhookCache = &autoscalingv1.WebhookCachedResponse{Scale: true, Replicas: 8,
+		ExpirationTime: metav1.NewTime(fakeClock.Now().Add(time.Minute)), ConfigHash: hash}
+	if got, err := s.GetReplicas(gpa, 3); err != nil || got != 8 || s.CacheDropped() {
+		t.Errorf("the answer cached for the service should be used, actual: %v, %v, dropped: %v", got, err,
+			s.CacheDropped())
+	}
+}
+
+func TestWebhookResponseCacheBreakerOpen(t *testing.T) {
+	webhook := &flakyWebhook{replicas: 5, failures: 100}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+		CircuitBreaker:      &autoscalingv1.WebhookCircuitBreaker{FailureThreshold: intPtr(1)},
+	}
+	s := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", fakeClock).(*WebhookScaler)
+	expired := &autoscalingv1.WebhookCachedResponse{Scale: true, Replicas: 7,
+		ExpirationTime: metav1.NewTime(fakeClock.Now().Add(-time.Minute)), ConfigHash: s.configHash()}
+	gpa.Status.WebhookCache = expired
+
+	// the failed call opens the breaker, the replicas is answered by the fallback
+	replicas, err := s.GetReplicas(gpa, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas != 3 {
+		t.Errorf("desired replicas of fallback: 3, actual: %v", replicas)
+	}
+	cache, used, stale := s.CachedResponse()
+	if cache == nil || used || stale {
+		t.Errorf("the expired cache should be kept but not used, actual: %+v, used: %v, stale: %v", cache, used,
+			stale)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// clockStep is the time of fake clock and the schedule desired active at the time
+type clockStep struct {
+	time     time.Time
+	schedule string
+}
+
+// runClockSteps moves the fake clock through the steps, and checks the active schedule of a scaler
+// driven by the clock
+func runClockSteps(t *testing.T, ranges []v1alpha1.CronMetricSpec, timeZone string, steps []clockStep) {
+	def := v1alpha1.CronMetricSpec{
+		Schedule:    "default",
+		MinReplicas: intPtr(1),
+		MaxReplicas: 2,
+	}
+	fakeClock := clock.NewFakeClock(steps[0].time)
+	scaler := NewCronMetricsScaler(append(ranges, def), timeZone, nil, fakeClock)
+	for _, step := range steps {
+		fakeClock.SetTime(step.time)
+		if _, _, schedule := scaler.GetCurrentMaxAndMinReplicas(&v1alpha1.GeneralPodAutoscaler{}); schedule != step.schedule {
+			t.Errorf("at %v desired schedule: %v, actual: %v", step.time, step.schedule, schedule)
+		}
+	}
+}
+
+func TestCronScheduleHourBoundary(t *testing.T) {
+	at := func(hour, min, sec int) time.Time {
+		return time.Date(2020, 12, 18, hour, min, sec, 0, time.UTC)
+	}
+	runClockSteps(t, []v1alpha1.CronMetricSpec{
+		{Schedule: "0-4 10 * * *", MinReplicas: intPtr(5), MaxReplicas: 7},
+		{Schedule: "59 10 * * *", MinReplicas: intPtr(5), MaxReplicas: 7},
+	}, "", []clockStep{
+		{at(9, 59, 30), "default"},
+		{at(10, 0, 0), "0-4 10 * * *"},
+		{at(10, 4, 59), "0-4 10 * * *"},
+		{at(10, 5, 30), "default"},
+		{at(10, 59, 0), "59 10 * * *"},
+		{at(10, 59, 59), "59 10 * * *"},
+		{at(11, 0, 30), "default"},
+	})
+}
+
+func TestCronScheduleYearChange(t *testing.T) {
+	runClockSteps(t, []v1alpha1.CronMetricSpec{
+		{Schedule: "* 23 31 12 * 2020", MinReplicas: intPtr(5), MaxReplicas: 7, Priority: 1},
+		{Start: "0 22 31 12 *", End: "0 2 1 1 *", MinReplicas: intPtr(5), MaxReplicas: 7},
+	}, "", []clockStep{
+		{time.Date(2020, 12, 31, 21, 59, 0, 0, time.UTC), "default"},
+		{time.Date(2020, 12, 31, 22, 30, 0, 0, time.UTC), "start 0 22 31 12 * end 0 2 1 1 *"},
+		{time.Date(2020, 12, 31, 23, 59, 30, 0, time.UTC), "* 23 31 12 * 2020"},
+		{time.Date(2021, 1, 1, 0, 0, 30, 0, time.UTC), "start 0 22 31 12 * end 0 2 1 1 *"},
+		{time.Date(2021, 1, 1, 2, 0, 0, 0, time.UTC), "default"},
+		{time.Date(2021, 12, 31, 23, 30, 0, 0, time.UTC), "start 0 22 31 12 * end 0 2 1 1 *"},
+	})
+}
+
+func TestCronScheduleDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(month, day, hour, min int) time.Time {
+		return time.Date(2021, time.Month(month), day, hour, min, 0, 0, newYork)
+	}
+	oneHour := &metav1.Duration{Duration: time.Hour}
+	// 2021-03-14 02:00 EST jumps to 03:00 EDT, 2021-11-07 02:00 EDT falls back to 01:00 EST
+	runClockSteps(t, []v1alpha1.CronMetricSpec{
+		{Start: "30 1 * * *", Duration: oneHour, MinReplicas: intPtr(5), MaxReplicas: 7},
+		{Start: "0 4 * * *", End: "0 6 * * *", MinReplicas: intPtr(5), MaxReplicas: 7},
+	}, "America/New_York", []clockStep{
+		{at(3, 14, 1, 40), "start 30 1 * * * duration 1h0m0s"},
+		// an hour after 01:30 EST is 03:30 EDT
+		{at(3, 14, 3, 20), "start 30 1 * * * duration 1h0m0s"},
+		{at(3, 14, 3, 30), "default"},
+		{at(3, 14, 5, 0), "start 0 4 * * * end 0 6 * * *"},
+		{at(11, 7, 1, 40), "start 30 1 * * * duration 1h0m0s"},
+		{at(11, 7, 5, 59), "start 0 4 * * * end 0 6 * * *"},
+		{at(11, 7, 6, 0), "default"},
+	})
+}
+
+func TestCronScalerWithFakeClock(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2020, 12, 18, 8, 59, 30, 0, time.UTC))
+	scaler := NewCronScaler([]v1alpha1.TimeRange{
+		{Schedule: "*/1 9-10 * * *", DesiredReplicas: 3},
+	}, "", fakeClock)
+	gpa := &v1alpha1.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.Time{Time: fakeClock.Now().Add(-time.Hour)},
+		},
+	}
+	for _, step := range []struct {
+		step    time.Duration
+		desired int32
+	}{
+		{0, 0},
+		{time.Minute, 3},
+		{2 * time.Hour, 0},
+	} {
+		fakeClock.Step(step.step)
+		replicas, err := scaler.GetReplicas(gpa, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replicas != step.desired {
+			t.Errorf("at %v desired replicas: %v, actual: %v", fakeClock.Now(), step.desired, replicas)
+		}
+	}
+}
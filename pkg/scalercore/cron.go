@@ -18,44 +18,55 @@ import (
 	"time"
 
 	"github.com/robfig/cron"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/klog"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
 )
 
-var _ Scaler = &CronScaler{}
-var recordScheduleName = ""
+var _ MultiSourceScaler = &CronScaler{}
 
 // CronScaler is a crontab GPA
 type CronScaler struct {
-	ranges []v1alpha1.TimeRange
-	name   string
-	now    time.Time
+	ranges          []v1alpha1.TimeRange
+	name            string
+	timeZone        string
+	clock           clock.PassiveClock
+	recommendations []Recommendation
+	// schedule is the schedule recommending the replicas in the last GetReplicas
+	schedule string
 }
 
-// NewCronScaler initializer crontab GPA
-func NewCronScaler(ranges []v1alpha1.TimeRange) Scaler {
-	return &CronScaler{ranges: ranges, name: Cron, now: time.Now()}
+// NewCronScaler initializer crontab GPA, schedules are evaluated in timeZone unless overridden by range,
+// at the time of clock
+func NewCronScaler(ranges []v1alpha1.TimeRange, timeZone string, clock clock.PassiveClock) Scaler {
+	return &CronScaler{ranges: ranges, name: Cron, timeZone: timeZone, clock: clock}
 }
 
 // GetReplicas return replicas  recommend by crontab GPA
 func (s *CronScaler) GetReplicas(gpa *v1alpha1.GeneralPodAutoscaler, currentReplicas int32) (int32, error) {
 	var max int32 = 0
+	s.recommendations = nil
+	s.schedule = ""
+	// all ranges are evaluated at the same time
+	now := s.clock.Now()
 	for _, t := range s.ranges {
-		misMatch, finalMatch, err := s.getFinalMatchAndMisMatch(gpa, t.Schedule)
+		misMatch, finalMatch, err := s.getFinalMatchAndMisMatch(gpa, t, now)
 		if err != nil {
 			klog.Error(err)
+			s.recommendations = append(s.recommendations, Recommendation{Name: TimeRangeName(t), Err: err})
 			return currentReplicas, nil
 		}
 		klog.Infof("firstMisMatch: %v, finalMatch: %v", misMatch, finalMatch)
 		if finalMatch == nil {
 			continue
 		}
+		s.recommendations = append(s.recommendations, Recommendation{Name: TimeRangeName(t), Replicas: t.DesiredReplicas})
 		if max < t.DesiredReplicas {
 			max = t.DesiredReplicas
-			recordScheduleName = t.Schedule
+			s.schedule = TimeRangeName(t)
 		}
-		klog.Infof("Schedule %v recommend %v replicas, desire: %v", t.Schedule, max, t.DesiredReplicas)
+		klog.Infof("Schedule %v recommend %v replicas, desire: %v", TimeRangeName(t), max, t.DesiredReplicas)
 	}
 	if max == 0 {
 		klog.Info("Recommend 0 replicas, use current replicas number")
@@ -69,13 +80,38 @@ func (s *CronScaler) ScalerName() string {
 	return s.name
 }
 
-func (s *CronScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAutoscaler, schedule string) (*time.Time, *time.Time, error) {
+// Recommendations returns the recommendations of the time ranges in schedule
+func (s *CronScaler) Recommendations() []Recommendation {
+	return s.recommendations
+}
+
+// ScheduleName returns the schedule recommending the replicas in the last GetReplicas, empty if
+// no schedule is active
+func (s *CronScaler) ScheduleName() string {
+	return s.schedule
+}
+
+func (s *CronScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAutoscaler, timeRange v1alpha1.TimeRange,
+	now time.Time) (*time.Time, *time.Time, error) {
+	loc, err := LoadScheduleLocation(s.timeZone, timeRange.TimeZone)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(timeRange.Start) != 0 {
+		window, err := ParseWindow(timeRange.Start, timeRange.End, timeRange.Duration)
+		if err != nil {
+			return nil, nil, err
+		}
+		opened, closed := window.Active(inLocation(now, loc))
+		return opened, closed, nil
+	}
+	schedule := timeRange.Schedule
 	sched, err := cron.ParseStandard(schedule)
 	if err != nil {
 		return nil, nil, err
 	}
 	lastTime := gpa.Status.LastCronScheduleTime.DeepCopy()
-	if recordScheduleName != schedule {
+	if gpa.Status.LastCronScheduleName != schedule {
 		lastTime = nil
 	}
 	if lastTime == nil || lastTime.IsZero() {
@@ -83,10 +119,11 @@ func (s *CronScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAutoscaler
 	}
 	match := lastTime.Time
 	misMatch := lastTime.Time
-	klog.Infof("Init time: %v, now: %v", lastTime, s.now)
-	t := lastTime.Time
+	klog.Infof("Init time: %v, now: %v", lastTime, now)
+	// schedule is evaluated in the wall clock of loc
+	t := inLocation(lastTime.Time, loc)
 	for {
-		if !t.After(s.now) {
+		if !t.After(now) {
 			misMatch = t
 			t = sched.Next(t)
 			continue
@@ -94,9 +131,17 @@ func (s *CronScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAutoscaler
 		match = t
 		break
 	}
-	if s.now.Sub(misMatch).Minutes() < 1 && s.now.After(misMatch) {
+	if now.Sub(misMatch).Minutes() < 1 && now.After(misMatch) {
 		return &misMatch, &match, nil
 	}
 
 	return nil, nil, nil
 }
+
+// TimeRangeName returns the schedule of range, or the window of range if start is set
+func TimeRangeName(timeRange v1alpha1.TimeRange) string {
+	if len(timeRange.Start) != 0 {
+		return windowName(timeRange.Start, timeRange.End, timeRange.Duration)
+	}
+	return timeRange.Schedule
+}
@@ -15,29 +15,36 @@
 package scalercore
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/robfig/cron"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/klog"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	listers "github.com/ocgi/general-pod-autoscaler/pkg/client/listers/autoscaling/v1alpha1"
 )
 
 var _ Scaler = &CronMetricsScaler{}
-var recordCronMetricsScheduleName = ""
 
 // CronMetricsScaler is a crontab GPA
 type CronMetricsScaler struct {
 	ranges     []v1alpha1.CronMetricSpec
 	defaultSet v1alpha1.CronMetricSpec
 	name       string
-	now        time.Time
+	timeZone   string
+	calendars  listers.HolidayCalendarLister
+	clock      clock.PassiveClock
 }
 
-// NewCronMetricsScaler initializer crontab GPA
-func NewCronMetricsScaler(ranges []v1alpha1.CronMetricSpec) *CronMetricsScaler {
+// NewCronMetricsScaler initializer crontab GPA, schedules are evaluated in timeZone unless overridden by range,
+// at the time of clock. The holiday calendars referenced by ranges are got from calendars
+func NewCronMetricsScaler(ranges []v1alpha1.CronMetricSpec, timeZone string,
+	calendars listers.HolidayCalendarLister, clock clock.PassiveClock) *CronMetricsScaler {
 	var def v1alpha1.CronMetricSpec
 	filter := make([]v1alpha1.CronMetricSpec, 0)
 	for _, cr := range ranges {
@@ -47,14 +54,17 @@ func NewCronMetricsScaler(ranges []v1alpha1.CronMetricSpec) *CronMetricsScaler {
 			def = cr
 		}
 	}
-	return &CronMetricsScaler{ranges: filter, name: Cron, now: time.Now(), defaultSet: def}
+	return &CronMetricsScaler{ranges: filter, name: Cron, timeZone: timeZone, calendars: calendars,
+		clock: clock, defaultSet: def}
 }
 
 // GetReplicas return replicas  recommend by crontab GPA
 func (s *CronMetricsScaler) GetReplicas(gpa *v1alpha1.GeneralPodAutoscaler, currentReplicas int32) (int32, error) {
 	var max int32 = 0
+	// all ranges are evaluated at the same time
+	now := s.clock.Now()
 	for _, t := range s.ranges {
-		misMatch, finalMatch, err := s.getFinalMatchAndMisMatch(gpa, t.Schedule)
+		misMatch, finalMatch, err := s.getFinalMatchAndMisMatch(gpa, t, now)
 		if err != nil {
 			klog.Error(err)
 			return currentReplicas, nil
@@ -65,9 +75,8 @@ func (s *CronMetricsScaler) GetReplicas(gpa *v1alpha1.GeneralPodAutoscaler, curr
 		}
 		if max < t.MaxReplicas {
 			max = t.MaxReplicas
-			recordCronMetricsScheduleName = t.Schedule
 		}
-		klog.Infof("Schedule %v recommend %v replicas, desire: %v", t.Schedule, max, t.MaxReplicas)
+		klog.Infof("Schedule %v recommend %v replicas, desire: %v", CronMetricSpecName(t), max, t.MaxReplicas)
 	}
 	if max == 0 {
 		klog.Info("Recommend 0 replicas, use current replicas number")
@@ -78,15 +87,23 @@ func (s *CronMetricsScaler) GetReplicas(gpa *v1alpha1.GeneralPodAutoscaler, curr
 
 // GetCurrentMaxAndMinReplicas get current cron config max and min replicas
 func (s *CronMetricsScaler) GetCurrentMaxAndMinReplicas(gpa *v1alpha1.GeneralPodAutoscaler) (int32, int32, string) {
-	var max, min int32
+	schedule := s.getActiveSchedule(gpa, s.clock.Now())
+	return schedule.MaxReplicas, schedule.MinReplicas, schedule.Schedule
+}
+
+// getActiveSchedule returns the schedule in force at now, it is the active cron spec with the max priority,
+// or the default cron spec if no cron spec is active
+func (s *CronMetricsScaler) getActiveSchedule(gpa *v1alpha1.GeneralPodAutoscaler, now time.Time) *v1alpha1.CronScheduleStatus {
 	if s.defaultSet.MaxReplicas == 0 && s.defaultSet.MinReplicas == nil {
 		klog.Errorf("gpa %v not set default scheduler", gpa)
-		return 2, 4, "default empty"
+		return &v1alpha1.CronScheduleStatus{Schedule: "default empty", MaxReplicas: 2, MinReplicas: 4}
 	}
 	//use defaultSet max min replicas
-	max = s.defaultSet.MaxReplicas
-	min = *s.defaultSet.MinReplicas
-	recordCronMetricsScheduleName = s.defaultSet.Schedule
+	def := &v1alpha1.CronScheduleStatus{
+		Schedule:    s.defaultSet.Schedule,
+		MaxReplicas: s.defaultSet.MaxReplicas,
+		MinReplicas: *s.defaultSet.MinReplicas,
+	}
 	//only one schedule satisfy
 	crs := make([]v1alpha1.CronMetricSpec, 0)
 	for _, cr := range s.ranges {
@@ -94,46 +111,41 @@ func (s *CronMetricsScaler) GetCurrentMaxAndMinReplicas(gpa *v1alpha1.GeneralPod
 			//ignore `default` cron set
 			continue
 		}
-		misMatch, finalMatch, err := s.getFinalMatchAndMisMatch(gpa, cr.Schedule)
+		misMatch, finalMatch, err := s.getFinalMatchAndMisMatch(gpa, cr, now)
 		if err != nil {
 			//can't get final, use default max min replicas, avoid use 0 0 replace
 			klog.Error(err)
-			return max, min, recordCronMetricsScheduleName
+			return def
 		}
-		klog.Infof("firstMisMatch: %v, finalMatch: %v, schedule: %v", misMatch, finalMatch, cr.Schedule)
+		klog.V(4).Infof("firstMisMatch: %v, finalMatch: %v, schedule: %v", misMatch, finalMatch, CronMetricSpecName(cr))
 		if finalMatch == nil {
 			continue
 		} else {
 			// exist multi cr with Priority
 			crs = append(crs, cr)
-			//max = cr.MaxReplicas
-			//min = *cr.MinReplicas
-			//recordCronMetricsScheduleName = cr.Schedule
-			//klog.Infof("Schedule %v recommend %v max replicas, min replicas: %v", cr.Schedule, max, min)
-			//return max, min, recordCronMetricsScheduleName
 		}
 	}
-	klog.Infof("get crs: %v", crs)
+	klog.V(4).Infof("get crs: %v", crs)
 	// not found, use default
 	if len(crs) == 0 {
-		return max, min, recordCronMetricsScheduleName
+		return def
 	}
-	var maxPriority int
 	var maxCr v1alpha1.CronMetricSpec
 	// choose max priority cron spec
-	for _, cr := range crs {
+	for i, cr := range crs {
 		// equal some old cronHpa config not set Priority
-		if cr.Priority >= maxPriority {
-			maxPriority = cr.Priority
+		if i == 0 || cr.Priority >= maxCr.Priority {
 			maxCr = cr
 		}
 	}
-	max = maxCr.MaxReplicas
-	min = *maxCr.MinReplicas
-	recordCronMetricsScheduleName = maxCr.Schedule
-	klog.Infof("Schedule %v recommend %v max replicas, min replicas: %v, Priority: %d",
-		maxCr.Schedule, max, min, maxCr.Priority)
-	return max, min, recordCronMetricsScheduleName
+	klog.V(4).Infof("Schedule %v recommend %v max replicas, min replicas: %v, Priority: %d",
+		CronMetricSpecName(maxCr), maxCr.MaxReplicas, *maxCr.MinReplicas, maxCr.Priority)
+	return &v1alpha1.CronScheduleStatus{
+		Schedule:    CronMetricSpecName(maxCr),
+		Priority:    maxCr.Priority,
+		MaxReplicas: maxCr.MaxReplicas,
+		MinReplicas: *maxCr.MinReplicas,
+	}
 }
 
 // GetCurrentCronMetricSpecs get schedule relate cronMetricSpec
@@ -141,7 +153,7 @@ func (s *CronMetricsScaler) GetCurrentCronMetricSpecs(gpa *v1alpha1.GeneralPodAu
 	cronMetricSpecs := gpa.Spec.CronMetricMode.CronMetrics
 	expectedCronMetricSpecs := make([]v1alpha1.CronMetricSpec, 0)
 	for _, cronInfo := range cronMetricSpecs {
-		if cronInfo.Schedule == schedule {
+		if CronMetricSpecName(cronInfo) == schedule {
 			expectedCronMetricSpecs = append(expectedCronMetricSpecs, cronInfo)
 		}
 	}
@@ -153,15 +165,43 @@ func (s *CronMetricsScaler) ScalerName() string {
 	return s.name
 }
 
-func (s *CronMetricsScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAutoscaler, schedule string) (*time.Time, *time.Time, error) {
-	year, sched, err := ParseStandardWithYear(schedule)
+func (s *CronMetricsScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAutoscaler, cronSpec v1alpha1.CronMetricSpec,
+	now time.Time) (*time.Time, *time.Time, error) {
+	loc, err := LoadScheduleLocation(s.timeZone, cronSpec.TimeZone)
+	if err != nil {
+		klog.Errorf("LoadScheduleLocation err: %s", err)
+		return nil, nil, err
+	}
+	// schedule is evaluated in the wall clock of loc
+	now = inLocation(now, loc)
+	if len(cronSpec.Calendar) != 0 {
+		start, end, err := s.getHoliday(cronSpec.Calendar, now)
+		if err != nil {
+			klog.Errorf("getHoliday err: %s", err)
+			return nil, nil, err
+		}
+		// not a holiday, or a holiday without schedule
+		if start == nil || (len(cronSpec.Schedule) == 0 && len(cronSpec.Start) == 0) {
+			return start, end, nil
+		}
+	}
+	if len(cronSpec.Start) != 0 {
+		window, err := ParseWindow(cronSpec.Start, cronSpec.End, cronSpec.Duration)
+		if err != nil {
+			klog.Errorf("ParseWindow err: %s", err)
+			return nil, nil, err
+		}
+		opened, closed := window.Active(now)
+		return opened, closed, nil
+	}
+	year, sched, err := ParseStandardWithYear(cronSpec.Schedule)
 	if err != nil {
 		klog.Errorf("ParseStandardWithYear err: %s", err)
 		return nil, nil, err
 	}
-	// year is not zero, not same with s.now then ignore
+	// year is not zero, not same with now then ignore
 	// year is zero, not set year scheduled
-	if year != 0 && year != s.now.Year() {
+	if year != 0 && year != now.Year() {
 		return nil, nil, nil
 	}
 	//sched, err := cron.ParseStandard(schedule)
@@ -169,7 +209,7 @@ func (s *CronMetricsScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAut
 	//	return nil, nil, err
 	//}
 	//lastTime := gpa.Status.LastCronScheduleTime.DeepCopy()
-	//if recordCronMetricsScheduleName != schedule {
+	//if gpa.Status.LastCronScheduleName != schedule {
 	//	lastTime = nil
 	//}
 	//if lastTime == nil || lastTime.IsZero() {
@@ -177,13 +217,13 @@ func (s *CronMetricsScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAut
 	//}
 	// fix bug: create time 12:08:31, now 12:09:01
 	// schedule: 10-14 12 * * *
-	initTime := getYesterdayFirstTime()
+	initTime := getYesterdayFirstTime(now)
 	match := initTime
 	misMatch := initTime
-	klog.Infof("Init time: %v, now: %v", initTime, s.now)
+	klog.V(4).Infof("Init time: %v, now: %v", initTime, now)
 	t := initTime
 	for {
-		if !t.After(s.now) {
+		if !t.After(now) {
 			misMatch = t
 			t = sched.Next(t)
 			continue
@@ -191,21 +231,79 @@ func (s *CronMetricsScaler) getFinalMatchAndMisMatch(gpa *v1alpha1.GeneralPodAut
 		match = t
 		break
 	}
-	klog.Infof("get misMatch: %s, match: %s", misMatch, match)
-	// fix bug: misMatch diff s.now < 1 ,but match diff s.now > 1
+	klog.V(4).Infof("get misMatch: %s, match: %s", misMatch, match)
+	// fix bug: misMatch diff now < 1 ,but match diff now > 1
 	// fix bug: misMatch minute is 59, now is xx:59:02
 	// fix bug: current time(now) is the hour and the second, 16:59:00.000, use equal check
-	if s.now.Sub(misMatch).Minutes() <= 1 && (s.now.After(misMatch) || s.now.Equal(misMatch)) &&
-		(match.Sub(s.now).Minutes() <= 1 || misMatch.Minute() == s.now.Minute()) {
+	if now.Sub(misMatch).Minutes() <= 1 && (now.After(misMatch) || now.Equal(misMatch)) &&
+		(match.Sub(now).Minutes() <= 1 || misMatch.Minute() == now.Minute()) {
 		return &misMatch, &match, nil
 	}
 
 	return nil, nil, nil
 }
 
-// getYesterdayFirstTime get today init start time
-func getYesterdayFirstTime() time.Time {
-	t1 := time.Now().Add(-1 * time.Hour)
+// getHoliday returns the start and end of the holiday in calendar that now is in, the dates of holidays
+// are in the location of now. nil is returned if now is not in any holiday.
+func (s *CronMetricsScaler) getHoliday(calendar string, now time.Time) (*time.Time, *time.Time, error) {
+	if s.calendars == nil {
+		return nil, nil, errors.Errorf("can not get holiday calendar %v", calendar)
+	}
+	cal, err := s.calendars.Get(calendar)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "get holiday calendar %v failed", calendar)
+	}
+	for _, holiday := range cal.Spec.Holidays {
+		start, end, err := ParseHoliday(holiday, now.Location())
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "holiday calendar %v", calendar)
+		}
+		if !now.Before(start) && now.Before(end) {
+			klog.V(4).Infof("Now %v is in holiday %v of calendar %v", now, holiday.Name, calendar)
+			return &start, &end, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// ParseHoliday returns the start and the exclusive end of holiday in loc
+func ParseHoliday(holiday v1alpha1.Holiday, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(v1alpha1.HolidayDateFormat, holiday.Start, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrapf(err, "parse start of holiday %v failed", holiday.Name)
+	}
+	end := start
+	if len(holiday.End) != 0 {
+		end, err = time.ParseInLocation(v1alpha1.HolidayDateFormat, holiday.End, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.Wrapf(err, "parse end of holiday %v failed", holiday.Name)
+		}
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errors.Errorf("end of holiday %v is before start", holiday.Name)
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// CronMetricSpecName returns the name of the schedule that spec belongs to, specs with the same
+// name are active at the same time
+func CronMetricSpecName(spec v1alpha1.CronMetricSpec) string {
+	schedule := spec.Schedule
+	if len(spec.Start) != 0 {
+		schedule = windowName(spec.Start, spec.End, spec.Duration)
+	}
+	if len(spec.Calendar) == 0 {
+		return schedule
+	}
+	if len(schedule) == 0 {
+		return fmt.Sprintf("calendar %s", spec.Calendar)
+	}
+	return fmt.Sprintf("calendar %s %s", spec.Calendar, schedule)
+}
+
+// getYesterdayFirstTime get the start of the hour before now, in the location of now
+func getYesterdayFirstTime(now time.Time) time.Time {
+	t1 := now.Add(-1 * time.Hour)
 	return time.Date(t1.Year(), t1.Month(), t1.Day(), t1.Hour(), 0, 0, 0, t1.Location())
 }
 
@@ -218,7 +316,7 @@ func ParseStandardWithYear(schedule string) (int, cron.Schedule, error) {
 			return 0, nil, err
 		}
 		leaveSchedule := strings.Join(schSlice[:len(schSlice)-1], " ")
-		klog.Infof("get year: %s, schedule: %s, leave schedule: %s", schSlice[len(schSlice)-1],
+		klog.V(4).Infof("get year: %s, schedule: %s, leave schedule: %s", schSlice[len(schSlice)-1],
 			schedule, leaveSchedule)
 		sched, err := cron.ParseStandard(leaveSchedule)
 		return year, sched, err
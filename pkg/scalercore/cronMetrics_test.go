@@ -19,6 +19,7 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
 )
@@ -72,7 +73,7 @@ func TestInCronScheduleFirst(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 6 || actualMax != 8 {
 			t.Errorf("desired min: 6, max: 8, actual min: %v, max: %v", actualMin, actualMax)
@@ -124,7 +125,7 @@ func TestInCronScheduleSecond(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 6 || actualMax != 8 {
 			t.Errorf("desired min: 6, max: 8, actual min: %v, max: %v", actualMin, actualMax)
@@ -184,7 +185,7 @@ func TestInCronScheduleThird(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "0-4 13-14 * * *" {
 			t.Errorf("desired schedule: `0-4 13-14 * * *`, actual schedule: %v", schedule)
@@ -244,7 +245,7 @@ func TestInCronScheduleFour(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "15-59 19 * * *" {
 			t.Errorf("desired schedule: `15-59 19 * * *`, actual schedule: %v", schedule)
@@ -304,7 +305,7 @@ func TestInCronScheduleFive(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "0-4 22 * * *" {
 			t.Errorf("desired schedule: `0-4 22 * * *`, actual schedule: %v", schedule)
@@ -364,7 +365,7 @@ func TestInCronScheduleSix(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "default" {
 			t.Errorf("desired schedule: `default`, actual schedule: %v", schedule)
@@ -424,7 +425,7 @@ func TestInCronScheduleSeven(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "55-59 23 * * *" {
 			t.Errorf("desired schedule: `55-59 23 * * *`, actual schedule: %v", schedule)
@@ -485,7 +486,7 @@ func TestInCronScheduleEighth(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "0-4 22 * * *" {
 			t.Errorf("desired schedule: `0-4 22 * * *`, actual schedule: %v", schedule)
@@ -546,7 +547,7 @@ func TestInCronScheduleNinth(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "0-4 22 * * *" {
 			t.Errorf("desired schedule: `0-4 22 * * *`, actual schedule: %v", schedule)
@@ -606,7 +607,7 @@ func TestNotInCronScheduleFirst(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "default" {
 			t.Errorf("desired schedule: `default`, actual schedule: %v", schedule)
@@ -661,7 +662,7 @@ func TestAcrossPeriods(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "0-59 12 * * *" {
 			t.Errorf("desired schedule: `0-59 10-12 * * *`, actual schedule: %v", schedule)
@@ -717,7 +718,7 @@ func TestAcrossPeriodsSecond(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if schedule != "0-59 12 * * *" {
 			t.Errorf("desired schedule: `0-59 10-12 * * *`, actual schedule: %v", schedule)
@@ -727,3 +728,120 @@ func TestAcrossPeriodsSecond(t *testing.T) {
 		}
 	})
 }
+
+func TestInCronScheduleTimeZone(t *testing.T) {
+	// 09:02 in Asia/Shanghai, 20:02 of the day before in America/New_York
+	testTime := time.Date(2020, 12, 18, 1, 2, 0, 0, time.UTC)
+	gpa := &v1alpha1.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.Time{Time: testTime.Add(-60 * time.Minute)},
+		},
+	}
+	def := v1alpha1.CronMetricSpec{
+		Schedule:    "default",
+		MinReplicas: intPtr(9),
+		MaxReplicas: 10,
+	}
+	for _, tc := range []struct {
+		name             string
+		timeZone         string
+		rangeTimeZone    string
+		desiredMin       int32
+		desiredMax       int32
+		desiredSchedule  string
+		desiredOtherZone bool
+	}{
+		{
+			name:            "mode time zone, in range",
+			timeZone:        "Asia/Shanghai",
+			desiredMin:      6,
+			desiredMax:      8,
+			desiredSchedule: "0-4 9-10 * * *",
+		},
+		{
+			name:            "range time zone overrides mode time zone, out of range",
+			timeZone:        "Asia/Shanghai",
+			rangeTimeZone:   "America/New_York",
+			desiredMin:      9,
+			desiredMax:      10,
+			desiredSchedule: "default",
+		},
+		{
+			name:            "range time zone, in range",
+			timeZone:        "America/New_York",
+			rangeTimeZone:   "Asia/Shanghai",
+			desiredMin:      6,
+			desiredMax:      8,
+			desiredSchedule: "0-4 9-10 * * *",
+		},
+		{
+			name:            "invalid time zone, use default",
+			timeZone:        "Mars/Olympus",
+			desiredMin:      9,
+			desiredMax:      10,
+			desiredSchedule: "default",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges := []v1alpha1.CronMetricSpec{
+				{
+					Schedule:    "0-4 9-10 * * *",
+					MinReplicas: intPtr(6),
+					MaxReplicas: 8,
+					TimeZone:    tc.rangeTimeZone,
+				},
+			}
+			cron := NewCronMetricsScaler(append(ranges, def), tc.timeZone, nil, clock.NewFakePassiveClock(testTime))
+			actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(gpa)
+			if actualMin != tc.desiredMin || actualMax != tc.desiredMax {
+				t.Errorf("desired min: %v, max: %v, actual min: %v, max: %v", tc.desiredMin, tc.desiredMax, actualMin, actualMax)
+			}
+			if schedule != tc.desiredSchedule {
+				t.Errorf("desired schedule: %v, actual schedule: %v", tc.desiredSchedule, schedule)
+			}
+		})
+	}
+}
+
+func TestInCronScheduleWindow(t *testing.T) {
+	// controller was down when the window opened at 08:00
+	testTime := time.Date(2020, 12, 18, 9, 30, 0, 0, time.UTC)
+	gpa := &v1alpha1.GeneralPodAutoscaler{}
+	def := v1alpha1.CronMetricSpec{
+		Schedule:    "default",
+		MinReplicas: intPtr(9),
+		MaxReplicas: 10,
+	}
+	ranges := []v1alpha1.CronMetricSpec{
+		{
+			Start:       "0 8 * * *",
+			End:         "0 12 * * *",
+			MinReplicas: intPtr(6),
+			MaxReplicas: 8,
+		},
+		{
+			Start:       "0 9 * * *",
+			Duration:    &metav1.Duration{Duration: 20 * time.Minute},
+			MinReplicas: intPtr(16),
+			MaxReplicas: 18,
+			Priority:    100,
+		},
+	}
+	cron := NewCronMetricsScaler(append(ranges, def), "", nil, clock.NewFakePassiveClock(testTime))
+	actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(gpa)
+	if actualMin != 6 || actualMax != 8 {
+		t.Errorf("desired min: 6, max: 8, actual min: %v, max: %v", actualMin, actualMax)
+	}
+	if schedule != "start 0 8 * * * end 0 12 * * *" {
+		t.Errorf("desired schedule: `start 0 8 * * * end 0 12 * * *`, actual schedule: %v", schedule)
+	}
+	if specs := cron.GetCurrentCronMetricSpecs(&v1alpha1.GeneralPodAutoscaler{
+		Spec: v1alpha1.GeneralPodAutoscalerSpec{
+			AutoScalingDrivenMode: v1alpha1.AutoScalingDrivenMode{
+				CronMetricMode: &v1alpha1.CronMetricMode{CronMetrics: append(ranges, def)},
+			},
+		},
+	}, schedule); len(specs) != 1 || specs[0].MaxReplicas != 8 {
+		t.Errorf("desired the spec of window, actual: %v", specs)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// scheduleLookahead is how far the next transition of schedule is looked up
+const scheduleLookahead = 7 * 24 * time.Hour
+
+// scheduleRecheckInterval is how long it is cached that no transition of schedule is in lookahead
+const scheduleRecheckInterval = time.Hour
+
+// ScheduleTransitionCache keeps the next transition of schedule of each gpa until it passes, so the
+// transitions are not looked up in every reconcile
+type ScheduleTransitionCache struct {
+	lock        sync.Mutex
+	transitions map[string]cachedTransition
+}
+
+// cachedTransition is the next transition from schedule looked up with the cron config, it is used
+// before expiration
+type cachedTransition struct {
+	config     string
+	schedule   string
+	next       *v1alpha1.CronScheduleTransition
+	expiration time.Time
+}
+
+// NewScheduleTransitionCache returns an empty schedule transition cache
+func NewScheduleTransitionCache() *ScheduleTransitionCache {
+	return &ScheduleTransitionCache{transitions: map[string]cachedTransition{}}
+}
+
+// Release drops the transition of gpa of owner
+func (c *ScheduleTransitionCache) Release(owner string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.transitions, owner)
+}
+
+// get returns the next transition of gpa of owner from schedule if it is cached with config and not expired
+func (c *ScheduleTransitionCache) get(owner, config, schedule string,
+	now time.Time) (*v1alpha1.CronScheduleTransition, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t, ok := c.transitions[owner]
+	if !ok || t.config != config || t.schedule != schedule || !now.Before(t.expiration) {
+		return nil, false
+	}
+	return t.next.DeepCopy(), true
+}
+
+// set caches the next transition of gpa of owner
+func (c *ScheduleTransitionCache) set(owner string, t cachedTransition) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.transitions[owner] = t
+}
+
+// GetCronSchedule returns the schedule in force and the next transition of schedule in a week. The time
+// the schedule became active is kept from the status of gpa if the schedule is not changed. The next
+// transition is cached in transitions for gpa of owner until it passes, transitions may be nil.
+func (s *CronMetricsScaler) GetCronSchedule(gpa *v1alpha1.GeneralPodAutoscaler, transitions *ScheduleTransitionCache,
+	owner string) *v1alpha1.CronScheduleStatus {
+	now := s.clock.Now()
+	current := s.getActiveSchedule(gpa, now)
+	current.ActiveSince = metav1.NewTime(now)
+	if last := gpa.Status.CronSchedule; last != nil && last.Schedule == current.Schedule {
+		current.ActiveSince = last.ActiveSince
+	}
+	var config string
+	if transitions != nil {
+		config = s.configHash()
+		if next, ok := transitions.get(owner, config, current.Schedule, now); ok {
+			current.Next = next
+			return current
+		}
+	}
+	current.Next = s.nextTransition(gpa, current.Schedule, now)
+	if transitions != nil {
+		// the schedule in force is not changed before the next transition
+		expiration := now.Add(scheduleRecheckInterval)
+		if current.Next != nil {
+			expiration = current.Next.Time.Time
+		}
+		transitions.set(owner, cachedTransition{config: config, schedule: current.Schedule,
+			next: current.Next.DeepCopy(), expiration: expiration})
+	}
+	return current
+}
+
+// nextTransition returns the first transition from schedule in lookahead, nil if the schedule keeps in force
+func (s *CronMetricsScaler) nextTransition(gpa *v1alpha1.GeneralPodAutoscaler, schedule string,
+	now time.Time) *v1alpha1.CronScheduleTransition {
+	for _, t := range s.transitionCandidates(now) {
+		next := s.getActiveSchedule(gpa, t)
+		if next.Schedule != schedule {
+			return &v1alpha1.CronScheduleTransition{
+				Schedule:    next.Schedule,
+				Time:        metav1.NewTime(t),
+				MaxReplicas: next.MaxReplicas,
+				MinReplicas: next.MinReplicas,
+			}
+		}
+	}
+	return nil
+}
+
+// configHash returns the hash of cron specs, time zone and the versions of holiday calendars referenced,
+// the transitions are looked up again if any of them is changed
+func (s *CronMetricsScaler) configHash() string {
+	calendars := map[string]string{}
+	for _, spec := range s.ranges {
+		if len(spec.Calendar) == 0 || s.calendars == nil {
+			continue
+		}
+		if cal, err := s.calendars.Get(spec.Calendar); err == nil {
+			calendars[spec.Calendar] = cal.ResourceVersion
+		}
+	}
+	data, _ := json.Marshal(struct {
+		Ranges     []v1alpha1.CronMetricSpec
+		DefaultSet v1alpha1.CronMetricSpec
+		TimeZone   string
+		Calendars  map[string]string
+	}{s.ranges, s.defaultSet, s.timeZone, calendars})
+	return sha256Hex(data)
+}
+
+// transitionCandidates returns the times in lookahead that any cron spec becomes active or inactive, sorted
+// ascending. The schedule in force can only change at these times.
+func (s *CronMetricsScaler) transitionCandidates(now time.Time) []time.Time {
+	until := now.Add(scheduleLookahead)
+	var candidates []time.Time
+	add := func(t time.Time) {
+		if t.After(now) && !t.After(until) {
+			candidates = append(candidates, t)
+		}
+	}
+	for _, spec := range s.ranges {
+		loc, err := LoadScheduleLocation(s.timeZone, spec.TimeZone)
+		if err != nil {
+			klog.Errorf("LoadScheduleLocation err: %s", err)
+			continue
+		}
+		from := inLocation(now, loc)
+		if len(spec.Calendar) != 0 && s.calendars != nil {
+			if cal, err := s.calendars.Get(spec.Calendar); err == nil {
+				for _, holiday := range cal.Spec.Holidays {
+					if start, end, err := ParseHoliday(holiday, loc); err == nil {
+						add(start)
+						add(end)
+					}
+				}
+			}
+		}
+		switch {
+		case len(spec.Start) != 0:
+			window, err := ParseWindow(spec.Start, spec.End, spec.Duration)
+			if err != nil {
+				continue
+			}
+			if _, closed := window.Active(from); closed != nil {
+				add(*closed)
+			}
+			for t := window.Start.Next(from); !t.After(until); t = window.Start.Next(t) {
+				add(t)
+				if window.End != nil {
+					add(window.End.Next(t))
+				} else {
+					add(t.Add(window.Duration))
+				}
+			}
+		case len(spec.Schedule) != 0:
+			_, sched, err := ParseStandardWithYear(spec.Schedule)
+			if err != nil {
+				continue
+			}
+			// a schedule is active in the minutes it fires, the consecutive minutes are one transition
+			var last time.Time
+			for t := sched.Next(from.Add(-time.Minute)); !t.After(until); t = sched.Next(t) {
+				if !t.Equal(last.Add(time.Minute)) {
+					add(last.Add(time.Minute))
+					add(t)
+				}
+				last = t
+			}
+			add(last.Add(time.Minute))
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+	return candidates
+}
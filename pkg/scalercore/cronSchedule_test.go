@@ -0,0 +1,150 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"testing"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+func TestGetCronSchedule(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2020, 12, day, hour, min, 0, 0, time.UTC)
+	}
+	specs := []v1alpha1.CronMetricSpec{
+		{Schedule: "* 10-11 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, Priority: 1},
+		{Start: "0 20 * * *", Duration: &metav1.Duration{Duration: 2 * time.Hour}, MinReplicas: intPtr(8),
+			MaxReplicas: 10, Priority: 2},
+		{Schedule: "* 21 * * *", MinReplicas: intPtr(3), MaxReplicas: 4, Priority: 1},
+		{Schedule: "default", MinReplicas: intPtr(1), MaxReplicas: 2},
+	}
+	cases := []struct {
+		name    string
+		time    time.Time
+		last    *v1alpha1.CronScheduleStatus
+		desired *v1alpha1.CronScheduleStatus
+	}{
+		{
+			name: "default before schedule",
+			time: at(18, 9, 30),
+			desired: &v1alpha1.CronScheduleStatus{
+				Schedule: "default", MinReplicas: 1, MaxReplicas: 2,
+				ActiveSince: metav1.NewTime(at(18, 9, 30)),
+				Next: &v1alpha1.CronScheduleTransition{
+					Schedule: "* 10-11 * * *", Time: metav1.NewTime(at(18, 10, 0)), MinReplicas: 5, MaxReplicas: 7,
+				},
+			},
+		},
+		{
+			name: "active since is kept",
+			time: at(18, 10, 30),
+			last: &v1alpha1.CronScheduleStatus{
+				Schedule: "* 10-11 * * *", ActiveSince: metav1.NewTime(at(18, 10, 0)),
+			},
+			desired: &v1alpha1.CronScheduleStatus{
+				Schedule: "* 10-11 * * *", Priority: 1, MinReplicas: 5, MaxReplicas: 7,
+				ActiveSince: metav1.NewTime(at(18, 10, 0)),
+				Next: &v1alpha1.CronScheduleTransition{
+					Schedule: "default", Time: metav1.NewTime(at(18, 12, 0)), MinReplicas: 1, MaxReplicas: 2,
+				},
+			},
+		},
+		{
+			name: "lower priority schedule does not change the window",
+			time: at(18, 20, 30),
+			last: &v1alpha1.CronScheduleStatus{
+				Schedule: "default", ActiveSince: metav1.NewTime(at(18, 12, 0)),
+			},
+			desired: &v1alpha1.CronScheduleStatus{
+				Schedule: "start 0 20 * * * duration 2h0m0s", Priority: 2, MinReplicas: 8, MaxReplicas: 10,
+				ActiveSince: metav1.NewTime(at(18, 20, 30)),
+				Next: &v1alpha1.CronScheduleTransition{
+					Schedule: "default", Time: metav1.NewTime(at(18, 22, 0)), MinReplicas: 1, MaxReplicas: 2,
+				},
+			},
+		},
+		{
+			name: "next transition on the next day",
+			time: at(18, 23, 0),
+			desired: &v1alpha1.CronScheduleStatus{
+				Schedule: "default", MinReplicas: 1, MaxReplicas: 2,
+				ActiveSince: metav1.NewTime(at(18, 23, 0)),
+				Next: &v1alpha1.CronScheduleTransition{
+					Schedule: "* 10-11 * * *", Time: metav1.NewTime(at(19, 10, 0)), MinReplicas: 5, MaxReplicas: 7,
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		gpa := &v1alpha1.GeneralPodAutoscaler{
+			Status: v1alpha1.GeneralPodAutoscalerStatus{CronSchedule: c.last},
+		}
+		scaler := NewCronMetricsScaler(specs, "", nil, clock.NewFakePassiveClock(c.time))
+		if actual := scaler.GetCronSchedule(gpa, nil, ""); !apiequality.Semantic.DeepEqual(c.desired, actual) {
+			t.Errorf("%v desired: %+v, next: %+v, actual: %+v, next: %+v", c.name, c.desired, c.desired.Next,
+				actual, actual.Next)
+		}
+	}
+}
+
+func TestGetCronScheduleCached(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2020, 12, 18, hour, min, 0, 0, time.UTC)
+	}
+	specs := []v1alpha1.CronMetricSpec{
+		{Schedule: "* 10-11 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, Priority: 1},
+		{Schedule: "default", MinReplicas: intPtr(1), MaxReplicas: 2},
+	}
+	owner := "test-namespace/test-gpa"
+	transitions := NewScheduleTransitionCache()
+	gpa := &v1alpha1.GeneralPodAutoscaler{}
+	fakeClock := clock.NewFakeClock(at(9, 0))
+	getNext := func(specs []v1alpha1.CronMetricSpec) *v1alpha1.CronScheduleTransition {
+		return NewCronMetricsScaler(specs, "", nil, fakeClock).GetCronSchedule(gpa, transitions, owner).Next
+	}
+	assertNext := func(step string, desired time.Time, next *v1alpha1.CronScheduleTransition) {
+		if next == nil || !next.Time.Time.Equal(desired) {
+			t.Errorf("%v: desired next transition at %v, actual: %+v", step, desired, next)
+		}
+	}
+
+	assertNext("looked up", at(10, 0), getNext(specs))
+	// the cached transition is used before it passes
+	transitions.transitions[owner] = cachedTransition{config: transitions.transitions[owner].config,
+		schedule: "default", next: &v1alpha1.CronScheduleTransition{Time: metav1.NewTime(at(9, 45))},
+		expiration: at(9, 45)}
+	fakeClock.SetTime(at(9, 30))
+	assertNext("cached", at(9, 45), getNext(specs))
+
+	// the transition is looked up again once the cron specs are changed or the transition passes
+	changed := []v1alpha1.CronMetricSpec{
+		{Schedule: "* 9-11 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, Priority: 1},
+		{Schedule: "default", MinReplicas: intPtr(1), MaxReplicas: 2},
+	}
+	assertNext("specs changed", at(12, 0), getNext(changed))
+	fakeClock.SetTime(at(12, 0))
+	assertNext("transition passed", at(9, 0).Add(24*time.Hour), getNext(changed))
+
+	transitions.Release(owner)
+	if len(transitions.transitions) != 0 {
+		t.Errorf("desired no transitions after released, actual: %v", transitions.transitions)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
 )
@@ -51,11 +52,12 @@ func Test_GetReplicas(t *testing.T) {
 		t.Fatal(err)
 	}
 	for _, c := range []struct {
-		name    string
-		ranges  []v1alpha1.TimeRange
-		desired int32
-		gpa     *v1alpha1.GeneralPodAutoscaler
-		time    time.Time
+		name     string
+		ranges   []v1alpha1.TimeRange
+		timeZone string
+		desired  int32
+		gpa      *v1alpha1.GeneralPodAutoscaler
+		time     time.Time
 	}{
 		{
 			name: "single timeRange, out of range",
@@ -200,6 +202,66 @@ func Test_GetReplicas(t *testing.T) {
 			time:    testTime4,
 			gpa:     cronGPA,
 		},
+		{
+			name: "single timeRange, time zone, out of range",
+			ranges: []v1alpha1.TimeRange{
+				{
+					Schedule:        "*/1 9-12 * * *",
+					DesiredReplicas: 1,
+				},
+			},
+			timeZone: "Asia/Shanghai",
+			desired:  0,
+		},
+		{
+			name: "single timeRange, time zone, in range",
+			ranges: []v1alpha1.TimeRange{
+				{
+					Schedule:        "*/1 17 * * *",
+					DesiredReplicas: 1,
+				},
+			},
+			timeZone: "Asia/Shanghai",
+			desired:  1,
+		},
+		{
+			name: "multi timeRange, range time zone overrides, one match",
+			ranges: []v1alpha1.TimeRange{
+				{
+					Schedule:        "*/1 9-12 * * *",
+					DesiredReplicas: 1,
+				},
+				{
+					Schedule:        "*/1 9-12 * * *",
+					DesiredReplicas: 3,
+					TimeZone:        "UTC",
+				},
+			},
+			timeZone: "Asia/Shanghai",
+			desired:  3,
+		},
+		{
+			name: "window opened an hour ago",
+			ranges: []v1alpha1.TimeRange{
+				{
+					Start:           "0 8 * * *",
+					End:             "0 10 * * *",
+					DesiredReplicas: 2,
+				},
+			},
+			desired: 2,
+		},
+		{
+			name: "window closed",
+			ranges: []v1alpha1.TimeRange{
+				{
+					Start:           "0 8 * * *",
+					Duration:        &metav1.Duration{Duration: time.Hour},
+					DesiredReplicas: 2,
+				},
+			},
+			desired: 0,
+		},
 	} {
 		t.Run(c.name, func(t *testing.T) {
 			defaultGPA := gpa
@@ -210,7 +272,7 @@ func Test_GetReplicas(t *testing.T) {
 			if !c.time.IsZero() {
 				testTime = c.time
 			}
-			cron := &CronScaler{ranges: c.ranges, name: Cron, now: testTime}
+			cron := NewCronScaler(c.ranges, c.timeZone, clock.NewFakePassiveClock(testTime))
 			actual, err := cron.GetReplicas(defaultGPA, 0)
 			if err != nil {
 				t.Error(err)
@@ -14,27 +14,118 @@
 
 package scalercore
 
-import autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+import (
+	"sync"
 
-var _ Scaler = &EventScaler{}
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
 
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+var _ LongRunScaler = &EventScaler{}
+var _ MultiSourceScaler = &EventScaler{}
+var _ Notifier = &EventScaler{}
+
+// EventScaler is a event driven GPA, it recommends the max replicas of all triggers
 type EventScaler struct {
-	schedule string
-	name     string
+	triggers []autoscalingv1.ScaleTriggers
+	scalers  []Scaler
+	// err is the error of building triggers, returned by GetReplicas
+	err             error
+	notify          func()
+	recommendations []Recommendation
+	name            string
 }
 
-func NewEventScaler(schedule string) Scaler {
-	return &EventScaler{schedule: schedule, name: Event}
+// NewEventScaler initializer event GPA
+func NewEventScaler(triggers []autoscalingv1.ScaleTriggers) Scaler {
+	e := &EventScaler{triggers: triggers, name: Event}
+	for _, trigger := range triggers {
+		s, err := NewTriggerScaler(trigger)
+		if err != nil {
+			e.err = errors.Wrapf(err, "build trigger %v failed", triggerName(trigger))
+			break
+		}
+		e.scalers = append(e.scalers, s)
+	}
+	return e
 }
 
-func (e *EventScaler) Run(stopCh <-chan struct{}) error {
-	return nil
+// GetReplicas return replicas recommend by event triggers
+func (e *EventScaler) GetReplicas(gpa *autoscalingv1.GeneralPodAutoscaler, currentReplicas int32) (int32, error) {
+	if len(e.triggers) == 0 {
+		return 0, errors.New("at least one trigger should set")
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+	var (
+		max      int32
+		firstErr error
+	)
+	e.recommendations = nil
+	for _, s := range e.scalers {
+		replicas, err := s.GetReplicas(gpa, currentReplicas)
+		e.recommendations = append(e.recommendations, Recommendation{Name: s.ScalerName(), Replicas: replicas, Err: err})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "trigger %v get replicas failed", s.ScalerName())
+			}
+			continue
+		}
+		klog.V(4).Infof("GPA: %v trigger: %v, suggested replicas: %v", gpa.Name, s.ScalerName(), replicas)
+		if replicas > max {
+			max = replicas
+		}
+	}
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	return max, nil
+}
+
+// ScalerName returns scaler name
+func (e *EventScaler) ScalerName() string {
+	return e.name
+}
+
+// Recommendations returns the recommendations of triggers
+func (e *EventScaler) Recommendations() []Recommendation {
+	return e.recommendations
 }
 
-func (e *EventScaler) GetReplicas(*autoscalingv1.GeneralPodAutoscaler, int32) (int32, error) {
-	return 0, nil
+// SetNotifyFunc sets notify for the long run triggers
+func (e *EventScaler) SetNotifyFunc(notify func()) {
+	e.notify = notify
 }
 
-func (s *EventScaler) ScalerName() string {
-	return s.name
+// Run runs the long run triggers until stopCh is closed
+func (e *EventScaler) Run(stopCh <-chan struct{}) error {
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		errs []error
+	)
+	for _, s := range e.scalers {
+		lr, ok := s.(LongRunScaler)
+		if !ok {
+			continue
+		}
+		if n, ok := lr.(Notifier); ok && e.notify != nil {
+			n.SetNotifyFunc(e.notify)
+		}
+		wg.Add(1)
+		go func(lr LongRunScaler) {
+			defer wg.Done()
+			if err := lr.Run(stopCh); err != nil {
+				lock.Lock()
+				errs = append(errs, errors.Wrapf(err, "trigger %v run failed", lr.ScalerName()))
+				lock.Unlock()
+			}
+		}(lr)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
 }
@@ -0,0 +1,231 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+func newQueueServer(body string, code int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestEventScalerGetReplicas(t *testing.T) {
+	queue := newQueueServer(`{"queue": {"depth": 25}, "shards": [{"depth": "7"}]}`, http.StatusOK)
+	defer queue.Close()
+	broken := newQueueServer(`internal error`, http.StatusInternalServerError)
+	defer broken.Close()
+
+	gpa := &v1alpha1.GeneralPodAutoscaler{}
+	for _, c := range []struct {
+		name     string
+		triggers []v1alpha1.ScaleTriggers
+		desired  int32
+		err      bool
+	}{
+		{
+			name: "queue depth rounds up",
+			triggers: []v1alpha1.ScaleTriggers{
+				{
+					Type: HTTPTrigger,
+					Metadata: map[string]string{
+						"url":           queue.URL,
+						"valueLocation": "queue.depth",
+						"targetValue":   "10",
+					},
+				},
+			},
+			desired: 3,
+		},
+		{
+			name: "max of triggers, value in array and string",
+			triggers: []v1alpha1.ScaleTriggers{
+				{
+					Type: HTTPTrigger,
+					Metadata: map[string]string{
+						"url":           queue.URL,
+						"valueLocation": "queue.depth",
+						"targetValue":   "10",
+					},
+				},
+				{
+					Type: HTTPTrigger,
+					Name: "shard",
+					Metadata: map[string]string{
+						"url":           queue.URL,
+						"valueLocation": "shards.0.depth",
+						"targetValue":   "1",
+					},
+				},
+			},
+			desired: 7,
+		},
+		{
+			name: "value location not found",
+			triggers: []v1alpha1.ScaleTriggers{
+				{
+					Type: HTTPTrigger,
+					Metadata: map[string]string{
+						"url":           queue.URL,
+						"valueLocation": "queue.length",
+						"targetValue":   "10",
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			name: "bad status code",
+			triggers: []v1alpha1.ScaleTriggers{
+				{
+					Type: HTTPTrigger,
+					Metadata: map[string]string{
+						"url":           broken.URL,
+						"valueLocation": "queue.depth",
+						"targetValue":   "10",
+					},
+				},
+			},
+			err: true,
+		},
+		{
+			name: "unknown trigger type",
+			triggers: []v1alpha1.ScaleTriggers{
+				{
+					Type:     "unknown",
+					Metadata: map[string]string{"url": queue.URL},
+				},
+			},
+			err: true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			s := NewEventScaler(c.triggers)
+			replicas, err := s.GetReplicas(gpa, 1)
+			if c.err {
+				if err == nil {
+					t.Errorf("desired error, actual replicas: %v", replicas)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if replicas != c.desired {
+				t.Errorf("desired: %v, actual: %v", c.desired, replicas)
+			}
+		})
+	}
+}
+
+func TestNewHTTPTriggerScaler(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		metadata map[string]string
+		err      bool
+	}{
+		{
+			name: "valid",
+			metadata: map[string]string{
+				"url":           "http://queue.default.svc:8080/stats",
+				"valueLocation": "depth",
+				"targetValue":   "5",
+			},
+		},
+		{
+			name: "url missing",
+			metadata: map[string]string{
+				"valueLocation": "depth",
+				"targetValue":   "5",
+			},
+			err: true,
+		},
+		{
+			name: "invalid scheme",
+			metadata: map[string]string{
+				"url":           "tcp://queue.default.svc:8080",
+				"valueLocation": "depth",
+				"targetValue":   "5",
+			},
+			err: true,
+		},
+		{
+			name: "target value not positive",
+			metadata: map[string]string{
+				"url":           "http://queue.default.svc:8080/stats",
+				"valueLocation": "depth",
+				"targetValue":   "0",
+			},
+			err: true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewTriggerScaler(v1alpha1.ScaleTriggers{Type: HTTPTrigger, Metadata: c.metadata})
+			if c.err != (err != nil) {
+				t.Errorf("desired error: %v, actual: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestEventScalerRecommendations(t *testing.T) {
+	queue := newQueueServer(`{"queue": {"depth": 25}}`, http.StatusOK)
+	defer queue.Close()
+	broken := newQueueServer(`internal error`, http.StatusInternalServerError)
+	defer broken.Close()
+
+	s := NewEventScaler([]v1alpha1.ScaleTriggers{
+		{
+			Type: HTTPTrigger,
+			Name: "queue",
+			Metadata: map[string]string{
+				"url":           queue.URL,
+				"valueLocation": "queue.depth",
+				"targetValue":   "10",
+			},
+		},
+		{
+			Type: HTTPTrigger,
+			Name: "broken",
+			Metadata: map[string]string{
+				"url":           broken.URL,
+				"valueLocation": "queue.depth",
+				"targetValue":   "10",
+			},
+		},
+	})
+	if _, err := s.GetReplicas(&v1alpha1.GeneralPodAutoscaler{}, 1); err == nil {
+		t.Fatal("desired error of broken trigger")
+	}
+	recommendations := s.(MultiSourceScaler).Recommendations()
+	if len(recommendations) != 2 {
+		t.Fatalf("desired 2 recommendations, actual: %v", recommendations)
+	}
+	if recommendations[0].Name != "queue" || recommendations[0].Replicas != 3 || recommendations[0].Err != nil {
+		t.Errorf("unexpected recommendation of queue: %+v", recommendations[0])
+	}
+	if recommendations[1].Name != "broken" || recommendations[1].Err == nil {
+		t.Errorf("unexpected recommendation of broken: %+v", recommendations[1])
+	}
+}
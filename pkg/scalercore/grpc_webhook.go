@@ -0,0 +1,76 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests/autoscalepb"
+)
+
+// grpcStatusCodes are the HTTP status codes of the gRPC codes answered by server, the codes not listed
+// are answered as 500
+var grpcStatusCodes = map[codes.Code]int32{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.Aborted:            http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Unimplemented:      http.StatusNotImplemented,
+}
+
+// doGRPC calls Autoscale of webhook with the review, and returns the review answered
+func doGRPC(ctx context.Context, call *webhookCall) (*requests.AutoscaleReview, error) {
+	in, err := autoscalepb.FromReview(call.review)
+	if err != nil {
+		return nil, err
+	}
+	if len(call.token) != 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+call.token)
+	}
+	out, err := autoscalepb.NewAutoscalerClient(call.conn).Autoscale(ctx, in)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return autoscalepb.ToReview(out)
+}
+
+// grpcError returns the rejection of the status answered by server, the errors of connection and
+// deadline are returned as they are
+func grpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		return err
+	}
+	code, ok := grpcStatusCodes[st.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+	return &WebhookRejectedError{Code: code, Reason: st.Code().String(), Message: st.Message()}
+}
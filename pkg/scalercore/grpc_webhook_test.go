@@ -0,0 +1,244 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests/autoscalepb"
+)
+
+// grpcAutoscaler answers the review by answer, the token of request should be the one of token if set
+type grpcAutoscaler struct {
+	token    string
+	answer   func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error)
+	received *requests.AutoscaleRequest
+}
+
+func (a *grpcAutoscaler) Autoscale(ctx context.Context, in *autoscalepb.AutoscaleReview) (*autoscalepb.AutoscaleReview, error) {
+	if len(a.token) != 0 {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if auth := md.Get("authorization"); len(auth) != 1 || auth[0] != "Bearer "+a.token {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+	}
+	review, err := autoscalepb.ToReview(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	a.received = review.Request
+	review.Response, err = a.answer(review.Request)
+	if err != nil {
+		return nil, err
+	}
+	return autoscalepb.FromReview(review)
+}
+
+// startGRPCWebhook starts the in-process gRPC server of autoscaler, and returns its url
+func startGRPCWebhook(t *testing.T, autoscaler autoscalepb.AutoscalerServer, opts ...grpc.ServerOption) (string, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(opts...)
+	autoscalepb.RegisterAutoscalerServer(server, autoscaler)
+	go server.Serve(lis)
+	scheme := "http"
+	if len(opts) != 0 {
+		scheme = "https"
+	}
+	return scheme + "://" + lis.Addr().String(), server.Stop
+}
+
+func TestGRPCWebhook(t *testing.T) {
+	autoscaler := &grpcAutoscaler{answer: func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+		return &requests.AutoscaleResponse{UID: req.UID, Scale: true, Replicas: req.CurrentReplicas + 2}, nil
+	}}
+	url, stop := startGRPCWebhook(t, autoscaler)
+	defer stop()
+
+	gpa := &autoscalingv1.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"},
+		Spec: autoscalingv1.GeneralPodAutoscalerSpec{
+			MinReplicas: intPtr(2),
+			MaxReplicas: 10,
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Squad", Name: "squad-example",
+				APIVersion: "carrier.ocgi.dev/v1alpha1"},
+		},
+	}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url},
+		Parameters:          map[string]string{"buffer": "2"},
+		Protocol:            autoscalingv1.GRPCWebhookProtocol,
+	}
+	transports := NewTransportCache()
+	s := NewWebhookScaler(mode, transports, nil, nil, "test-namespace/test-gpa", clock.RealClock{})
+	s.(TargetObserver).SetTargetStatus(TargetStatus{Selector: "app=squad-example", ReadyPods: intPtr(3),
+		TotalPods: intPtr(3)})
+	replicas, err := s.GetReplicas(gpa, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas != 5 {
+		t.Errorf("desired replicas: 5, actual: %v", replicas)
+	}
+	desired := &requests.AutoscaleRequest{
+		UID:             autoscaler.received.UID,
+		Name:            "squad-example",
+		Namespace:       "test-namespace",
+		Parameters:      map[string]string{"buffer": "2"},
+		CurrentReplicas: 3,
+		MinReplicas:     intPtr(2),
+		MaxReplicas:     10,
+		Target:          &requests.TargetReference{Kind: "Squad", APIVersion: "carrier.ocgi.dev/v1alpha1"},
+		Selector:        "app=squad-example",
+		ReadyPods:       intPtr(3),
+		TotalPods:       intPtr(3),
+	}
+	if !apiequality.Semantic.DeepEqual(desired, autoscaler.received) {
+		t.Errorf("desired request: %+v, actual: %+v", desired, autoscaler.received)
+	}
+
+	// the connection is reused
+	if _, err := s.GetReplicas(gpa, 3); err != nil {
+		t.Fatal(err)
+	}
+	if transports.Len() != 1 {
+		t.Errorf("desired 1 connection, actual: %v", transports.Len())
+	}
+	transports.Release("test-namespace/test-gpa")
+	if transports.Len() != 0 {
+		t.Errorf("desired no connections after released, actual: %v", transports.Len())
+	}
+}
+
+func TestGRPCWebhookRejection(t *testing.T) {
+	var answer func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error)
+	url, stop := startGRPCWebhook(t, &grpcAutoscaler{answer: func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+		return answer(req)
+	}})
+	defer stop()
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url},
+		Protocol:            autoscalingv1.GRPCWebhookProtocol,
+	}
+	for _, c := range []struct {
+		name     string
+		answer   func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error)
+		rejected *WebhookRejectedError
+	}{
+		{
+			name: "status error",
+			answer: func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+				return nil, status.Error(codes.FailedPrecondition, "workload is draining")
+			},
+			rejected: &WebhookRejectedError{Code: http.StatusBadRequest, Reason: "FailedPrecondition",
+				Message: "workload is draining"},
+		},
+		{
+			name: "result",
+			answer: func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+				return &requests.AutoscaleResponse{UID: req.UID, Result: &metav1.Status{Status: metav1.StatusFailure,
+					Code: http.StatusConflict, Reason: "Draining", Message: "workload is draining"}}, nil
+			},
+			rejected: &WebhookRejectedError{Code: http.StatusConflict, Reason: "Draining", Message: "workload is draining"},
+		},
+		{
+			name: "uid mismatch",
+			answer: func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+				return &requests.AutoscaleResponse{UID: "other", Scale: true, Replicas: 3}, nil
+			},
+			rejected: &WebhookRejectedError{Reason: ReasonUIDMismatch},
+		},
+	} {
+		answer = c.answer
+		_, err := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 3)
+		rejected, ok := err.(*WebhookRejectedError)
+		if !ok {
+			t.Errorf("%v: desired rejection, actual: %v", c.name, err)
+			continue
+		}
+		if rejected.Code != c.rejected.Code || rejected.Reason != c.rejected.Reason ||
+			(len(c.rejected.Message) != 0 && rejected.Message != c.rejected.Message) {
+			t.Errorf("%v: desired rejection: %+v, actual: %+v", c.name, c.rejected, rejected)
+		}
+	}
+}
+
+func TestGRPCWebhookAuthentication(t *testing.T) {
+	serverCA, serverCAKey, caBundle := newTestCA(t, "webhook-server-ca")
+	clientCA, clientCAKey, _ := newTestCA(t, "webhook-client-ca")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA)
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{newTestKeyPair(t, serverCA, serverCAKey, x509.ExtKeyUsageServerAuth)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	url, stop := startGRPCWebhook(t, &grpcAutoscaler{token: "test-token",
+		answer: func(req *requests.AutoscaleRequest) (*requests.AutoscaleResponse, error) {
+			return &requests.AutoscaleResponse{UID: req.UID, Scale: true, Replicas: 4}, nil
+		}}, grpc.Creds(creds))
+	defer stop()
+
+	certPEM, keyPEM := newTestCert(t, clientCA, clientCAKey, x509.ExtKeyUsageClientAuth)
+	secrets := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-auth", Namespace: "test-namespace"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:             certPEM,
+			corev1.TLSPrivateKeyKey:       keyPEM,
+			corev1.ServiceAccountTokenKey: []byte("test-token"),
+		},
+	}).CoreV1()
+	gpa := &autoscalingv1.GeneralPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"}}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url, CABundle: caBundle},
+		Authentication:      &autoscalingv1.WebhookAuthentication{SecretName: "webhook-auth"},
+		Protocol:            autoscalingv1.GRPCWebhookProtocol,
+	}
+	replicas, err := NewWebhookScaler(mode, nil, nil, secrets, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas != 4 {
+		t.Errorf("desired replicas: 4, actual: %v", replicas)
+	}
+
+	// the server requires the client certificate
+	mode.Authentication = nil
+	if _, err := NewWebhookScaler(mode, nil, nil, secrets, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 1); err == nil {
+		t.Error("call without client certificate should fail")
+	}
+}
@@ -19,8 +19,11 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	listers "github.com/ocgi/general-pod-autoscaler/pkg/client/listers/autoscaling/v1alpha1"
 )
 
 type TestHolidaysSchedule struct {
@@ -79,7 +82,7 @@ func TestInHolidaysScheduleFirst(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 5 || actualMax != 7 {
 			t.Errorf("desired min: 5, max: 7, actual min: %v, max: %v", actualMin, actualMax)
@@ -134,7 +137,7 @@ func TestInHolidaysScheduleTwo(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 9 || actualMax != 10 {
 			t.Errorf("desired min: 9, max: 10, actual min: %v, max: %v", actualMin, actualMax)
@@ -195,7 +198,7 @@ func TestInHolidaysScheduleThree(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 5 || actualMax != 7 {
 			t.Errorf("desired min: 5, max: 7, actual min: %v, max: %v", actualMin, actualMax)
@@ -256,7 +259,7 @@ func TestInHolidaysScheduleFour(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 12 || actualMax != 13 {
 			t.Errorf("desired min: 12, max: 13, actual min: %v, max: %v", actualMin, actualMax)
@@ -317,7 +320,7 @@ func TestInHolidaysScheduleFive(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 12 || actualMax != 13 {
 			t.Errorf("desired min: 12, max: 13, actual min: %v, max: %v", actualMin, actualMax)
@@ -379,7 +382,7 @@ func TestInHolidaysScheduleSix(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 9 || actualMax != 10 {
 			t.Errorf("desired min: 9, max: 10, actual min: %v, max: %v", actualMin, actualMax)
@@ -441,7 +444,7 @@ func TestInHolidaysScheduleSeven(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 12 || actualMax != 13 {
 			t.Errorf("desired min: 12, max: 13, actual min: %v, max: %v", actualMin, actualMax)
@@ -502,7 +505,7 @@ func TestInHolidaysScheduleEighth(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 5 || actualMax != 7 {
 			t.Errorf("desired min: 12, max: 13, actual min: %v, max: %v", actualMin, actualMax)
@@ -617,7 +620,7 @@ func TestInHolidaysScheduleNinth(t *testing.T) {
 		if !tc.time.IsZero() {
 			testTime = tc.time
 		}
-		cron := &CronMetricsScaler{ranges: tc.mode.CronMetrics, name: Cron, now: testTime, defaultSet: def}
+		cron := NewCronMetricsScaler(tc.mode.CronMetrics, "", nil, clock.NewFakePassiveClock(testTime))
 		actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(defaultGPA)
 		if actualMin != 5 || actualMax != 7 {
 			t.Errorf("desired min: 12, max: 13, actual min: %v, max: %v", actualMin, actualMax)
@@ -627,3 +630,127 @@ func TestInHolidaysScheduleNinth(t *testing.T) {
 		}
 	})
 }
+
+func TestInHolidayCalendar(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&v1alpha1.HolidayCalendar{
+		ObjectMeta: metav1.ObjectMeta{Name: "cn-public-holidays"},
+		Spec: v1alpha1.HolidayCalendarSpec{
+			Holidays: []v1alpha1.Holiday{
+				{Name: "national-day", Start: "2023-10-01", End: "2023-10-07"},
+				{Name: "new-year", Start: "2024-01-01"},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	calendars := listers.NewHolidayCalendarLister(indexer)
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpa := &v1alpha1.GeneralPodAutoscaler{}
+	def := v1alpha1.CronMetricSpec{
+		Schedule:    "default",
+		MinReplicas: intPtr(9),
+		MaxReplicas: 10,
+	}
+	holiday := v1alpha1.CronMetricSpec{
+		Calendar:    "cn-public-holidays",
+		MinReplicas: intPtr(20),
+		MaxReplicas: 30,
+		Priority:    100,
+	}
+	holidayNight := v1alpha1.CronMetricSpec{
+		Calendar:    "cn-public-holidays",
+		Schedule:    "* 20-22 * * *",
+		MinReplicas: intPtr(40),
+		MaxReplicas: 50,
+		Priority:    200,
+	}
+	missing := v1alpha1.CronMetricSpec{
+		Calendar:    "missing",
+		MinReplicas: intPtr(40),
+		MaxReplicas: 50,
+	}
+	for _, tc := range []struct {
+		name            string
+		ranges          []v1alpha1.CronMetricSpec
+		timeZone        string
+		now             time.Time
+		desiredMin      int32
+		desiredMax      int32
+		desiredSchedule string
+	}{
+		{
+			name:            "in holiday",
+			ranges:          []v1alpha1.CronMetricSpec{holiday, holidayNight},
+			now:             time.Date(2023, 10, 3, 10, 0, 0, 0, shanghai),
+			desiredMin:      20,
+			desiredMax:      30,
+			desiredSchedule: "calendar cn-public-holidays",
+		},
+		{
+			name:            "in single day holiday",
+			ranges:          []v1alpha1.CronMetricSpec{holiday},
+			now:             time.Date(2024, 1, 1, 23, 59, 59, 0, shanghai),
+			desiredMin:      20,
+			desiredMax:      30,
+			desiredSchedule: "calendar cn-public-holidays",
+		},
+		{
+			name:            "in holiday and schedule",
+			ranges:          []v1alpha1.CronMetricSpec{holiday, holidayNight},
+			now:             time.Date(2023, 10, 7, 20, 30, 0, 0, shanghai),
+			desiredMin:      40,
+			desiredMax:      50,
+			desiredSchedule: "calendar cn-public-holidays * 20-22 * * *",
+		},
+		{
+			name:            "not in holiday",
+			ranges:          []v1alpha1.CronMetricSpec{holiday, holidayNight},
+			now:             time.Date(2023, 10, 8, 20, 30, 0, 0, shanghai),
+			desiredMin:      9,
+			desiredMax:      10,
+			desiredSchedule: "default",
+		},
+		{
+			name:            "holiday in time zone",
+			ranges:          []v1alpha1.CronMetricSpec{holiday},
+			timeZone:        "Asia/Shanghai",
+			now:             time.Date(2023, 9, 30, 17, 0, 0, 0, time.UTC),
+			desiredMin:      20,
+			desiredMax:      30,
+			desiredSchedule: "calendar cn-public-holidays",
+		},
+		{
+			name:            "not holiday in other time zone",
+			ranges:          []v1alpha1.CronMetricSpec{holiday},
+			timeZone:        "UTC",
+			now:             time.Date(2023, 9, 30, 17, 0, 0, 0, time.UTC),
+			desiredMin:      9,
+			desiredMax:      10,
+			desiredSchedule: "default",
+		},
+		{
+			name:            "calendar not found",
+			ranges:          []v1alpha1.CronMetricSpec{missing},
+			now:             time.Date(2023, 10, 3, 10, 0, 0, 0, shanghai),
+			desiredMin:      9,
+			desiredMax:      10,
+			desiredSchedule: "default",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cron := NewCronMetricsScaler(append(tc.ranges, def), tc.timeZone, calendars,
+				clock.NewFakePassiveClock(tc.now))
+			actualMax, actualMin, schedule := cron.GetCurrentMaxAndMinReplicas(gpa)
+			if actualMin != tc.desiredMin || actualMax != tc.desiredMax {
+				t.Errorf("desired min: %v, max: %v, actual min: %v, max: %v", tc.desiredMin, tc.desiredMax, actualMin, actualMax)
+			}
+			if schedule != tc.desiredSchedule {
+				t.Errorf("desired schedule: %v, actual schedule: %v", tc.desiredSchedule, schedule)
+			}
+		})
+	}
+}
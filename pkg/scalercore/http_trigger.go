@@ -0,0 +1,160 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+const (
+	// HTTPTrigger is the trigger type which reads a value from a http json endpoint
+	HTTPTrigger = "http"
+
+	// metadata keys of http trigger
+	httpTriggerURL           = "url"
+	httpTriggerValueLocation = "valueLocation"
+	httpTriggerTargetValue   = "targetValue"
+)
+
+var httpTriggerClient = http.Client{
+	Timeout: 15 * time.Second,
+}
+
+func init() {
+	RegisterTrigger(HTTPTrigger, NewHTTPTriggerScaler)
+}
+
+var _ Scaler = &HTTPTriggerScaler{}
+
+// HTTPTriggerScaler gets a value(e.g. queue depth) from a http json endpoint,
+// and recommends ceil(value / targetValue) replicas.
+type HTTPTriggerScaler struct {
+	url           string
+	valueLocation []string
+	targetValue   float64
+	name          string
+}
+
+// NewHTTPTriggerScaler initializer http trigger from trigger metadata
+func NewHTTPTriggerScaler(trigger autoscalingv1.ScaleTriggers) (Scaler, error) {
+	rawURL := trigger.Metadata[httpTriggerURL]
+	if len(rawURL) == 0 {
+		return nil, fmt.Errorf("metadata %v must set", httpTriggerURL)
+	}
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid metadata %v", httpTriggerURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("metadata %v scheme must be http or https", httpTriggerURL)
+	}
+	location := trigger.Metadata[httpTriggerValueLocation]
+	if len(location) == 0 {
+		return nil, fmt.Errorf("metadata %v must set", httpTriggerValueLocation)
+	}
+	target, err := strconv.ParseFloat(trigger.Metadata[httpTriggerTargetValue], 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid metadata %v", httpTriggerTargetValue)
+	}
+	if target <= 0 {
+		return nil, fmt.Errorf("metadata %v must be greater than 0", httpTriggerTargetValue)
+	}
+	return &HTTPTriggerScaler{
+		url:           rawURL,
+		valueLocation: strings.Split(location, "."),
+		targetValue:   target,
+		name:          triggerName(trigger),
+	}, nil
+}
+
+// GetReplicas return replicas recommend by the value of http endpoint
+func (s *HTTPTriggerScaler) GetReplicas(gpa *autoscalingv1.GeneralPodAutoscaler, currentReplicas int32) (int32, error) {
+	value, err := s.getValue()
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("value %v from %v must not be negative", value, s.url)
+	}
+	replicas := math.Ceil(value / s.targetValue)
+	if replicas > math.MaxInt32 {
+		return math.MaxInt32, nil
+	}
+	return int32(replicas), nil
+}
+
+// ScalerName returns scaler name
+func (s *HTTPTriggerScaler) ScalerName() string {
+	return s.name
+}
+
+func (s *HTTPTriggerScaler) getValue() (float64, error) {
+	res, err := httpTriggerClient.Get(s.url)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("bad status code %d from the server: %s", res.StatusCode, s.url)
+	}
+	var body interface{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return 0, err
+	}
+	return getJSONValue(body, s.valueLocation)
+}
+
+// getJSONValue finds the number located by path, e.g. `queue.depth` or `queues.0.depth`
+func getJSONValue(obj interface{}, path []string) (float64, error) {
+	cur := obj
+	for i, p := range path {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[p]
+			if !ok {
+				return 0, fmt.Errorf("value location %v not found", strings.Join(path[:i+1], "."))
+			}
+			cur = next
+		case []interface{}:
+			idx, err := strconv.Atoi(p)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return 0, fmt.Errorf("value location %v out of range", strings.Join(path[:i+1], "."))
+			}
+			cur = v[idx]
+		default:
+			return 0, fmt.Errorf("value location %v not found", strings.Join(path[:i+1], "."))
+		}
+	}
+	switch v := cur.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("value of %v is not a number", strings.Join(path, "."))
+	}
+}
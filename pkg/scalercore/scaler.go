@@ -33,3 +33,38 @@ type LongRunScaler interface {
 	Scaler
 	Run(<-chan struct{}) error
 }
+
+// Notifier is implemented by the LongRunScaler which pushes its signal, the controller
+// sets notify before Run, calling notify makes the gpa reconciled immediately.
+type Notifier interface {
+	SetNotifyFunc(notify func())
+}
+
+// Recommendation is the replicas recommended by a source of scaler, e.g. a time range or a trigger
+type Recommendation struct {
+	Name     string
+	Replicas int32
+	Err      error
+}
+
+// MultiSourceScaler is implemented by the scaler which recommends by multiple sources,
+// Recommendations returns the recommendations of sources in the last GetReplicas.
+type MultiSourceScaler interface {
+	Scaler
+	Recommendations() []Recommendation
+}
+
+// TargetStatus is the state of scale target observed by the controller
+type TargetStatus struct {
+	// Selector is the label selector of pods
+	Selector string
+	// ReadyPods and TotalPods are the numbers of ready pods and all pods, nil if unknown
+	ReadyPods *int32
+	TotalPods *int32
+}
+
+// TargetObserver is implemented by the scaler which needs the state of scale target, the
+// controller sets it before GetReplicas.
+type TargetObserver interface {
+	SetTargetStatus(status TargetStatus)
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LoadScheduleLocation returns the location a schedule is evaluated in, the time zone of range
+// overrides the time zone of mode. It returns nil if neither is set.
+func LoadScheduleLocation(modeTimeZone, rangeTimeZone string) (*time.Location, error) {
+	timeZone := modeTimeZone
+	if len(rangeTimeZone) != 0 {
+		timeZone = rangeTimeZone
+	}
+	if len(timeZone) == 0 {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load time zone %v failed", timeZone)
+	}
+	return loc, nil
+}
+
+// inLocation returns t in loc, t is returned as is if loc is nil
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
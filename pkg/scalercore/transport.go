@@ -0,0 +1,230 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"k8s.io/klog"
+)
+
+// webhookTimeout is the default timeout of a webhook call
+const webhookTimeout = 15 * time.Second
+
+// transportKey identifies a transport by the CA bundle, the client certificate and the endpoint it connects to
+type transportKey struct {
+	// caBundle is the sha256 of CA bundle, empty if no CA bundle
+	caBundle string
+	// clientCert is the sha256 of client certificate and key, empty if no client certificate
+	clientCert string
+	// endpoint is the scheme and host of url
+	endpoint string
+	// grpc is true for the connection of gRPC protocol
+	grpc bool
+}
+
+// TransportCache keeps a http client or a gRPC connection for each CA bundle, client certificate and endpoint,
+// so the connections of webhooks are reused across reconciles. They are released when no gpa uses them.
+type TransportCache struct {
+	lock    sync.Mutex
+	clients map[transportKey]*http.Client
+	conns   map[transportKey]*grpc.ClientConn
+	// owners are the transports used by each gpa
+	owners map[string]transportKey
+	// configs are the hash of webhook config of each gpa
+	configs map[string]string
+}
+
+// NewTransportCache returns an empty transport cache
+func NewTransportCache() *TransportCache {
+	return &TransportCache{
+		clients: map[transportKey]*http.Client{},
+		conns:   map[transportKey]*grpc.ClientConn{},
+		owners:  map[string]transportKey{},
+		configs: map[string]string{},
+	}
+}
+
+// Client returns the client of the endpoint of u for gpa of owner, the server is verified with caBundle,
+// and the client certificate of certPEM and keyPEM is presented if set. The client used before by the
+// gpa is released if it is changed.
+func (c *TransportCache) Client(owner string, u *url.URL, caBundle, certPEM, keyPEM []byte) (*http.Client, error) {
+	key := newTransportKey(u, caBundle, certPEM, keyPEM, false)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	client, ok := c.clients[key]
+	if !ok {
+		tlsConfig, err := newTLSConfig(caBundle, certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		// the timeout is set by each call
+		client = &http.Client{Transport: transport}
+		c.clients[key] = client
+	}
+	c.use(owner, key)
+	return client, nil
+}
+
+// Conn returns the gRPC connection of the host of u for gpa of owner, TLS is used if the scheme of u is
+// https. The server is verified with caBundle, and the client certificate of certPEM and keyPEM is
+// presented if set. The connection used before by the gpa is released if it is changed.
+func (c *TransportCache) Conn(owner string, u *url.URL, caBundle, certPEM, keyPEM []byte) (*grpc.ClientConn, error) {
+	key := newTransportKey(u, caBundle, certPEM, keyPEM, true)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	conn, ok := c.conns[key]
+	if !ok {
+		creds := grpc.WithInsecure()
+		if u.Scheme == "https" {
+			tlsConfig, err := newTLSConfig(caBundle, certPEM, keyPEM)
+			if err != nil {
+				return nil, err
+			}
+			creds = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+		}
+		var err error
+		// the connection is established in background, and reconnected if it is broken
+		conn, err = grpc.Dial(u.Host, creds)
+		if err != nil {
+			return nil, err
+		}
+		c.conns[key] = conn
+	}
+	c.use(owner, key)
+	return conn, nil
+}
+
+// Configure records the hash of webhook config of gpa of owner, the transport used by the gpa is released
+// if the config is changed, e.g. the endpoint or CA bundle is changed
+func (c *TransportCache) Configure(owner, config string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	last, ok := c.configs[owner]
+	c.configs[owner] = config
+	if !ok || last == config {
+		return
+	}
+	if key, ok := c.owners[owner]; ok {
+		delete(c.owners, owner)
+		c.releaseIfUnused(key)
+	}
+}
+
+// Release releases the transport used by gpa of owner
+func (c *TransportCache) Release(owner string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.configs, owner)
+	key, ok := c.owners[owner]
+	if !ok {
+		return
+	}
+	delete(c.owners, owner)
+	c.releaseIfUnused(key)
+}
+
+// Len returns the number of cached transports
+func (c *TransportCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.clients) + len(c.conns)
+}
+
+// use records gpa of owner uses the transport of key, and releases the one used before if it is changed,
+// the lock should be held.
+func (c *TransportCache) use(owner string, key transportKey) {
+	last, ok := c.owners[owner]
+	c.owners[owner] = key
+	if ok && last != key {
+		c.releaseIfUnused(last)
+	}
+}
+
+// releaseIfUnused closes the idle connections of client of key, or the gRPC connection of key, and
+// removes it if no gpa uses it, the lock should be held.
+func (c *TransportCache) releaseIfUnused(key transportKey) {
+	for _, k := range c.owners {
+		if k == key {
+			return
+		}
+	}
+	if client, ok := c.clients[key]; ok {
+		client.CloseIdleConnections()
+		delete(c.clients, key)
+	}
+	if conn, ok := c.conns[key]; ok {
+		if err := conn.Close(); err != nil {
+			klog.Warningf("Close gRPC connection of %v failed: %v", key.endpoint, err)
+		}
+		delete(c.conns, key)
+	}
+}
+
+// newTransportKey returns the key of transport connecting to the endpoint of u
+func newTransportKey(u *url.URL, caBundle, certPEM, keyPEM []byte, useGRPC bool) transportKey {
+	key := transportKey{endpoint: u.Scheme + "://" + u.Host, grpc: useGRPC}
+	if len(caBundle) != 0 {
+		key.caBundle = sha256Hex(caBundle)
+	}
+	if len(certPEM) != 0 {
+		key.clientCert = sha256Hex(certPEM, keyPEM)
+	}
+	return key
+}
+
+// newTLSConfig returns the TLS config verifying the server with caBundle, and presenting the client
+// certificate of certPEM and keyPEM if set
+func newTLSConfig(caBundle, certPEM, keyPEM []byte) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if len(caBundle) != 0 {
+		rootCAs := x509.NewCertPool()
+		if ok := rootCAs.AppendCertsFromPEM(caBundle); !ok {
+			return nil, errors.New("no certs were appended from caBundle")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if len(certPEM) != 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// sha256Hex returns the hex encoded sha256 of data
+func sha256Hex(data ...[]byte) string {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,242 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+)
+
+// newTestCA returns a CA signing test certificates, and its PEM encoded certificate
+func newTestCA(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// newTestCert returns the PEM encoded certificate and key signed by ca for usage, the server
+// certificate is for 127.0.0.1
+func newTestCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "webhook"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if usage == x509.ExtKeyUsageServerAuth {
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// newTLSWebhook starts a webhook recommending replicas, its certificate is signed by a CA of its own.
+// The CA bundle is returned with the server.
+func newTLSWebhook(t *testing.T, replicas int32) (*httptest.Server, []byte) {
+	ca, caKey, caBundle := newTestCA(t, fmt.Sprintf("webhook-ca-%d", replicas))
+	server := httptest.NewUnstartedServer(webhookHandler(replicas))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{newTestKeyPair(t, ca, caKey, x509.ExtKeyUsageServerAuth)}}
+	server.StartTLS()
+	return server, caBundle
+}
+
+// newTestKeyPair returns the certificate signed by ca for usage
+func newTestKeyPair(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, usage x509.ExtKeyUsage) tls.Certificate {
+	cert, err := tls.X509KeyPair(newTestCert(t, ca, caKey, usage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// webhookHandler is a webhook recommending replicas
+func webhookHandler(replicas int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var review requests.AutoscaleReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review.Response = &requests.AutoscaleResponse{UID: review.Request.UID, Scale: true, Replicas: replicas}
+		json.NewEncoder(w).Encode(review)
+	}
+}
+
+func TestWebhookTransportsConcurrently(t *testing.T) {
+	transports := NewTransportCache()
+	var scalers []Scaler
+	for i := 0; i < 5; i++ {
+		server, caBundle := newTLSWebhook(t, int32(i+1))
+		defer server.Close()
+		mode := &autoscalingv1.WebhookMode{
+			WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL, CABundle: caBundle},
+		}
+		scalers = append(scalers, NewWebhookScaler(mode, transports, nil, nil, fmt.Sprintf("test-namespace/test-gpa-%d", i), clock.RealClock{}))
+	}
+
+	var wg sync.WaitGroup
+	for i, s := range scalers {
+		wg.Add(1)
+		go func(desired int32, s Scaler) {
+			defer wg.Done()
+			gpa := &autoscalingv1.GeneralPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace"},
+			}
+			for i := 0; i < 20; i++ {
+				replicas, err := s.GetReplicas(gpa, 0)
+				if err != nil {
+					t.Errorf("webhook %v failed: %v", desired, err)
+					return
+				}
+				if replicas != desired {
+					t.Errorf("desired replicas: %v, actual: %v", desired, replicas)
+					return
+				}
+			}
+		}(int32(i+1), s)
+	}
+	wg.Wait()
+
+	if transports.Len() != len(scalers) {
+		t.Errorf("desired %v transports, actual: %v", len(scalers), transports.Len())
+	}
+	for i := range scalers {
+		transports.Release(fmt.Sprintf("test-namespace/test-gpa-%d", i))
+	}
+	if transports.Len() != 0 {
+		t.Errorf("desired no transports after released, actual: %v", transports.Len())
+	}
+}
+
+func TestTransportCacheReuse(t *testing.T) {
+	transports := NewTransportCache()
+	server, caBundle := newTLSWebhook(t, 1)
+	server.Close()
+	u := createURL("https", "webhook", "default", "/scale", nil)
+
+	first, err := transports.Client("a", u, caBundle, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := transports.Client("b", u, caBundle, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("client should be shared by the same CA bundle and endpoint")
+	}
+	if _, err := transports.Client("a", u, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if transports.Len() != 2 {
+		t.Errorf("desired 2 transports, actual: %v", transports.Len())
+	}
+	// client of CA bundle is still used by b
+	transports.Release("a")
+	if transports.Len() != 1 {
+		t.Errorf("desired 1 transport, actual: %v", transports.Len())
+	}
+	transports.Release("b")
+	if transports.Len() != 0 {
+		t.Errorf("desired no transports, actual: %v", transports.Len())
+	}
+	if _, err := transports.Client("a", u, []byte("invalid"), nil, nil); err == nil {
+		t.Error("invalid CA bundle should fail")
+	}
+}
+
+func TestTransportCacheConfigure(t *testing.T) {
+	transports := NewTransportCache()
+	u := createURL("http", "webhook", "default", "/scale", nil)
+	use := func(owner, config string) {
+		transports.Configure(owner, config)
+		if _, err := transports.Client(owner, u, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	use("a", "first")
+	use("b", "first")
+	use("a", "first")
+	if transports.Len() != 1 {
+		t.Errorf("desired 1 transport, actual: %v", transports.Len())
+	}
+	// the client is still used by b after the config of a changed
+	transports.Configure("a", "second")
+	transports.Configure("b", "second")
+	if transports.Len() != 0 {
+		t.Errorf("desired no transports after the config changed, actual: %v", transports.Len())
+	}
+	use("a", "second")
+	transports.Release("a")
+	transports.Release("b")
+	if transports.Len() != 0 {
+		t.Errorf("desired no transports, actual: %v", transports.Len())
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+)
+
+// TriggerBuilder builds a scaler from the config of an event trigger.
+// It should return an error if the trigger metadata is invalid.
+type TriggerBuilder func(trigger autoscalingv1.ScaleTriggers) (Scaler, error)
+
+var (
+	triggerBuilders     = map[string]TriggerBuilder{}
+	triggerBuildersLock sync.RWMutex
+)
+
+// RegisterTrigger registers a builder for the given trigger type,
+// it panics if the type has been registered.
+func RegisterTrigger(triggerType string, builder TriggerBuilder) {
+	triggerBuildersLock.Lock()
+	defer triggerBuildersLock.Unlock()
+
+	if _, ok := triggerBuilders[triggerType]; ok {
+		panic(fmt.Sprintf("trigger type %q has been registered", triggerType))
+	}
+	triggerBuilders[triggerType] = builder
+}
+
+// IsTriggerRegistered returns true if the trigger type has been registered
+func IsTriggerRegistered(triggerType string) bool {
+	triggerBuildersLock.RLock()
+	defer triggerBuildersLock.RUnlock()
+
+	_, ok := triggerBuilders[triggerType]
+	return ok
+}
+
+// RegisteredTriggers returns all registered trigger types in order
+func RegisteredTriggers() []string {
+	triggerBuildersLock.RLock()
+	defer triggerBuildersLock.RUnlock()
+
+	types := make([]string, 0, len(triggerBuilders))
+	for t := range triggerBuilders {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewTriggerScaler builds the scaler for the trigger according to its type
+func NewTriggerScaler(trigger autoscalingv1.ScaleTriggers) (Scaler, error) {
+	triggerBuildersLock.RLock()
+	builder, ok := triggerBuilders[trigger.Type]
+	triggerBuildersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown trigger type %q", trigger.Type)
+	}
+	return builder(trigger)
+}
+
+// triggerName returns the name of trigger, use type if name not set
+func triggerName(trigger autoscalingv1.ScaleTriggers) string {
+	if len(trigger.Name) != 0 {
+		return trigger.Name
+	}
+	return trigger.Type
+}
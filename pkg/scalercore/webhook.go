@@ -15,48 +15,300 @@
 package scalercore
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
-	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog"
 
 	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
 	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
 )
 
-var client = http.Client{
-	Timeout: 15 * time.Second,
+var _ Scaler = &WebhookScaler{}
+var _ TargetObserver = &WebhookScaler{}
+
+const (
+	// ReasonRejected is the reason of rejection if the server gives a result without reason
+	ReasonRejected = "Rejected"
+	// ReasonBadStatusCode is the reason of rejection if the server answers non-200 without result
+	ReasonBadStatusCode = "BadStatusCode"
+	// ReasonUnsupportedVersion is the reason of rejection if the version of response is not supported
+	ReasonUnsupportedVersion = "UnsupportedVersion"
+	// ReasonUIDMismatch is the reason of rejection if the uid of response is not the one of request
+	ReasonUIDMismatch = "UIDMismatch"
+	// ReasonInvalidResponse is the reason of rejection if the response is empty or has invalid replicas
+	ReasonInvalidResponse = "InvalidResponse"
+)
+
+// WebhookRejectedError is returned if the webhook rejects the request or its response fails validation
+type WebhookRejectedError struct {
+	// Code is the status code given by the server, it is 0 if the response fails validation
+	Code int32
+	// Reason is the reason given by the server, or the reason of failed validation
+	Reason string
+	// Message is the human readable description of rejection
+	Message string
 }
 
-var _ Scaler = &WebhookScaler{}
+func (e *WebhookRejectedError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("webhook rejected with code %d, reason %v: %v", e.Code, e.Reason, e.Message)
+	}
+	return fmt.Sprintf("webhook rejected with reason %v: %v", e.Reason, e.Message)
+}
+
+// ReplicaBounds is the override of replicas limits answered by the webhook, nil limit is not overridden
+type ReplicaBounds struct {
+	MinReplicas *int32
+	MaxReplicas *int32
+}
 
 type WebhookScaler struct {
 	modeConfig *autoscalingv1.WebhookMode
 	name       string
+	// transports keeps the client of webhook, owner is the gpa using it
+	transports *TransportCache
+	owner      string
+	// batcher groups the calls of gpas sharing the webhook in batch mode
+	batcher *WebhookBatcher
+	// secrets gets the authentication secret of webhook
+	secrets corev1client.SecretsGetter
+	// target is the state of scale target sent to webhook
+	target TargetStatus
+	clock  clock.PassiveClock
+	// breaker is the state of circuit breaker after the last GetReplicas, nil if no circuit breaker
+	breaker *autoscalingv1.WebhookBreakerStatus
+	// bounds is the override of replicas limits in the answer used by the last GetReplicas, boundsOnly is
+	// true if the answer only overrides the limits
+	bounds     *ReplicaBounds
+	boundsOnly bool
+	// cache is the answer of webhook cached after the last GetReplicas, nil if it is not cached. cacheUsed
+	// is true if the replicas is answered by the cache, cacheDropped is true if the cache in status is
+	// dropped for the webhook mode changed
+	cache        *autoscalingv1.WebhookCachedResponse
+	cacheUsed    bool
+	cacheDropped bool
+	// called is true if the webhook is called in the last GetReplicas, callErr is the error of the call
+	called  bool
+	callErr error
 }
 
-func NewWebhookScaler(modeConfig *autoscalingv1.WebhookMode) Scaler {
-	return &WebhookScaler{modeConfig: modeConfig, name: Webhook}
+// NewWebhookScaler initializer webhook GPA, the client of webhook is got from transports for gpa of owner,
+// the calls are batched with other gpas by batcher in batch mode, and the authentication secret is got
+// from secrets. The circuit breaker is evaluated at the time of clock.
+func NewWebhookScaler(modeConfig *autoscalingv1.WebhookMode, transports *TransportCache, batcher *WebhookBatcher,
+	secrets corev1client.SecretsGetter, owner string, clock clock.PassiveClock) Scaler {
+	if transports == nil {
+		transports = NewTransportCache()
+	}
+	if batcher == nil {
+		batcher = NewWebhookBatcher()
+	}
+	return &WebhookScaler{modeConfig: modeConfig, name: Webhook, transports: transports, batcher: batcher,
+		secrets: secrets, owner: owner, clock: clock}
 }
 
+// GetReplicas returns the replicas answered by the webhook. The answer with ttlSeconds is kept in status of
+// gpa and reused until it expires. If the circuit breaker is configured, the breaker starts from the state
+// in status of gpa, and the replicas of fallback policy is returned while it is open. If the expired answer
+// can not be refreshed for no answer of webhook, the expired answer is used.
 func (s *WebhookScaler) GetReplicas(gpa *autoscalingv1.GeneralPodAutoscaler, currentReplicas int32) (int32, error) {
+	s.breaker, s.cache, s.called, s.callErr = nil, nil, false, nil
+	s.bounds, s.boundsOnly, s.cacheUsed, s.cacheDropped = nil, false, false, false
 	if s.modeConfig == nil {
 		return 0, errors.New("webhookPolicy parameter must not be nil")
 	}
+	configHash := s.configHash()
+	s.transports.Configure(s.owner, configHash)
+	// the breaker in status is kept if it is not evaluated, e.g. the replicas is answered by the cache
+	if s.modeConfig.CircuitBreaker != nil {
+		s.breaker = gpa.Status.WebhookBreaker.DeepCopy()
+	}
+	if cache := gpa.Status.WebhookCache; cache != nil && cache.ConfigHash != configHash {
+		klog.V(4).Infof("Drop the answer of GPA %v webhook cached before the webhook mode changed", s.owner)
+		s.cacheDropped = true
+	} else if cache != nil {
+		s.cache = cache.DeepCopy()
+		if s.clock.Now().Before(s.cache.ExpirationTime.Time) {
+			klog.V(4).Infof("Use the answer of GPA %v webhook cached until %v", s.owner, s.cache.ExpirationTime)
+			return s.useCache(currentReplicas), nil
+		}
+	}
+	// the errors of configuration are neither retried nor counted by the breaker
+	call, err := s.newCall(gpa, currentReplicas)
+	if err != nil {
+		return 0, err
+	}
+	replicas, err := s.callWithBreaker(gpa, call, currentReplicas)
+	if err != nil && s.cache != nil && retryable(err) {
+		klog.Warningf("Refresh the answer of GPA %v webhook failed, use the answer expired at %v: %v", s.owner,
+			s.cache.ExpirationTime, err)
+		return s.useCache(currentReplicas), nil
+	}
+	return replicas, err
+}
 
-	u, err := s.buildURLFromWebhookPolicy()
+// callWithBreaker calls the webhook through the circuit breaker if it is configured
+func (s *WebhookScaler) callWithBreaker(gpa *autoscalingv1.GeneralPodAutoscaler, call *webhookCall,
+	currentReplicas int32) (int32, error) {
+	if s.modeConfig.CircuitBreaker == nil {
+		return s.callAndCache(call, currentReplicas)
+	}
+
+	breaker := newCircuitBreaker(s.modeConfig.CircuitBreaker, gpa.Status.WebhookBreaker, s.clock.Now())
+	s.breaker = breaker.status
+	if !breaker.allow(s.clock.Now()) {
+		klog.V(4).Infof("Circuit breaker of GPA %v webhook is open, use fallback %v", s.owner,
+			s.modeConfig.CircuitBreaker.Fallback)
+		return breaker.fallback(gpa, currentReplicas), nil
+	}
+	replicas, err := s.callAndCache(call, currentReplicas)
+	switch {
+	case err == nil:
+		breaker.succeed(replicas, s.clock.Now())
+	case retryable(err):
+		breaker.fail(err, s.clock.Now())
+	default:
+		// the server rejects the request, it is not a failure of the webhook
+		return 0, err
+	}
+	if breaker.open() {
+		klog.Warningf("Circuit breaker of GPA %v webhook is open after %v failures, use fallback %v: %v",
+			s.owner, breaker.status.ConsecutiveFailures, s.modeConfig.CircuitBreaker.Fallback, err)
+		return breaker.fallback(gpa, currentReplicas), nil
+	}
+	return replicas, err
+}
+
+// callAndCache calls the webhook, and caches the answer if the webhook answers with ttlSeconds
+func (s *WebhookScaler) callAndCache(call *webhookCall, currentReplicas int32) (int32, error) {
+	resp, err := s.callWithRetry(call)
+	s.called, s.callErr = true, err
 	if err != nil {
 		return 0, err
 	}
-	req := requests.AutoscaleReview{
+	s.cache = nil
+	if resp.TTLSeconds != nil && *resp.TTLSeconds > 0 {
+		now := s.clock.Now()
+		s.cache = &autoscalingv1.WebhookCachedResponse{
+			Scale:          resp.Scale,
+			Replicas:       resp.Replicas,
+			ReceivedTime:   metav1.NewTime(now),
+			ExpirationTime: metav1.NewTime(now.Add(time.Duration(*resp.TTLSeconds) * time.Second)),
+			MinReplicas:    resp.MinReplicas,
+			MaxReplicas:    resp.MaxReplicas,
+			ConfigHash:     s.configHash(),
+		}
+	}
+	// the answer just cached is the one in use
+	s.cacheUsed = s.cache != nil
+	return s.useAnswer(resp.Scale, resp.Replicas, resp.MinReplicas, resp.MaxReplicas, currentReplicas), nil
+}
+
+// useCache uses the cached answer, and returns the replicas of it
+func (s *WebhookScaler) useCache(currentReplicas int32) int32 {
+	s.cacheUsed = true
+	return s.useAnswer(s.cache.Scale, s.cache.Replicas, s.cache.MinReplicas, s.cache.MaxReplicas, currentReplicas)
+}
+
+// useAnswer keeps the limits overridden by the answer, and returns the replicas of it, the current
+// replicas is kept if the webhook answers not to scale
+func (s *WebhookScaler) useAnswer(scale bool, replicas int32, minReplicas, maxReplicas *int32,
+	currentReplicas int32) int32 {
+	if minReplicas != nil || maxReplicas != nil {
+		s.bounds = &ReplicaBounds{MinReplicas: minReplicas, MaxReplicas: maxReplicas}
+		s.boundsOnly = !scale
+	}
+	if scale {
+		return replicas
+	}
+	return currentReplicas
+}
+
+// BreakerStatus returns the state of circuit breaker after the last GetReplicas, the previous state if the
+// breaker is not evaluated, nil if no circuit breaker
+func (s *WebhookScaler) BreakerStatus() *autoscalingv1.WebhookBreakerStatus {
+	return s.breaker
+}
+
+// Bounds returns the override of replicas limits answered by the webhook in the last GetReplicas, nil if
+// the limits are not overridden. The bool is true if the answer only overrides the limits, the replicas
+// returned should not be proposed then.
+func (s *WebhookScaler) Bounds() (*ReplicaBounds, bool) {
+	return s.bounds, s.boundsOnly
+}
+
+// CachedResponse returns the answer of webhook cached after the last GetReplicas, nil if the answer is not
+// cached. The bools are whether the replicas is answered by the cache, and whether the cache is used after
+// it expired.
+func (s *WebhookScaler) CachedResponse() (*autoscalingv1.WebhookCachedResponse, bool, bool) {
+	if s.cache == nil || !s.cacheUsed {
+		return s.cache, false, false
+	}
+	return s.cache, true, !s.clock.Now().Before(s.cache.ExpirationTime.Time)
+}
+
+// CacheDropped returns whether the cached answer in status is dropped in the last GetReplicas, because the
+// webhook mode is changed after the answer
+func (s *WebhookScaler) CacheDropped() bool {
+	return s.cacheDropped
+}
+
+// configHash returns the hash of the webhook mode, the cached answer is only used by the same webhook mode
+func (s *WebhookScaler) configHash() string {
+	data, _ := json.Marshal(s.modeConfig)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// LastCall returns whether the webhook is called in the last GetReplicas and the error of the call, the
+// webhook is not called if the circuit breaker is open
+func (s *WebhookScaler) LastCall() (bool, error) {
+	return s.called, s.callErr
+}
+
+// webhookCall is a request to webhook, it is sent again if retried
+type webhookCall struct {
+	// client is the client of HTTP protocol, conn is the connection of gRPC protocol
+	client *http.Client
+	conn   *grpc.ClientConn
+	url    *url.URL
+	token  string
+	review *requests.AutoscaleReview
+	// timeout is the timeout of each call
+	timeout time.Duration
+	// body is the review encoded in JSON for HTTP protocol
+	body []byte
+}
+
+// newCall builds the request of gpa to webhook
+func (s *WebhookScaler) newCall(gpa *autoscalingv1.GeneralPodAutoscaler, currentReplicas int32) (*webhookCall, error) {
+	u, err := s.buildURLFromWebhookPolicy()
+	if err != nil {
+		return nil, err
+	}
+	certPEM, keyPEM, token, err := s.getCredentials(gpa)
+	if err != nil {
+		return nil, err
+	}
+	req := &requests.AutoscaleReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: requests.APIVersion, Kind: requests.Kind},
 		Request: &requests.AutoscaleRequest{
 			UID:  uuid.NewUUID(),
 			Name: gpa.Spec.ScaleTargetRef.Name,
@@ -64,61 +316,242 @@ func (s *WebhookScaler) GetReplicas(gpa *autoscalingv1.GeneralPodAutoscaler, cur
 			Namespace:       gpa.Namespace,
 			Parameters:      s.modeConfig.Parameters,
 			CurrentReplicas: currentReplicas,
+			// the replicas limits are adjusted by the cron schedule in force before scalers called
+			MinReplicas: gpa.Spec.MinReplicas,
+			MaxReplicas: gpa.Spec.MaxReplicas,
+			Target: &requests.TargetReference{
+				Kind:       gpa.Spec.ScaleTargetRef.Kind,
+				APIVersion: gpa.Spec.ScaleTargetRef.APIVersion,
+			},
+			Selector:       s.target.Selector,
+			ReadyPods:      s.target.ReadyPods,
+			TotalPods:      s.target.TotalPods,
+			CurrentMetrics: gpa.Status.CurrentMetrics,
 		},
 		Response: nil,
 	}
 
-	b, err := json.Marshal(req)
+	call := &webhookCall{url: u, token: token, review: req, timeout: webhookTimeout}
+	if s.modeConfig.Timeout != nil {
+		call.timeout = s.modeConfig.Timeout.Duration
+	}
+	if s.modeConfig.Protocol == autoscalingv1.GRPCWebhookProtocol {
+		call.conn, err = s.transports.Conn(s.owner, u, s.modeConfig.CABundle, certPEM, keyPEM)
+		return call, err
+	}
+	call.client, err = s.transports.Client(s.owner, u, s.modeConfig.CABundle, certPEM, keyPEM)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	call.body, err = json.Marshal(req)
+	return call, err
+}
 
-	res, err := client.Post(
-		u.String(),
-		"application/json",
-		strings.NewReader(string(b)),
+// callWithRetry sends the call to webhook, the call is retried with backoff if it fails without answer,
+// or the server answers 429 or 5xx. The timeout of webhook bounds the call with all the retries.
+func (s *WebhookScaler) callWithRetry(call *webhookCall) (*requests.AutoscaleResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), call.timeout)
+	defer cancel()
+	policy := newRetryPolicy(s.modeConfig.Retry)
+	backoff := policy.backoff
+	for retries := int32(0); ; retries++ {
+		resp, err := s.do(ctx, call)
+		if err == nil || !retryable(err) || retries >= policy.maxRetries {
+			return resp, err
+		}
+		klog.V(4).Infof("Call webhook of GPA %v failed, retry after %v: %v", s.owner, backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > policy.maxBackoff {
+			backoff = policy.maxBackoff
+		}
+	}
+}
+
+// do sends the call to webhook once in ctx, and returns the validated response
+func (s *WebhookScaler) do(ctx context.Context, call *webhookCall) (*requests.AutoscaleResponse, error) {
+	var (
+		review *requests.AutoscaleReview
+		err    error
 	)
+	switch {
+	case call.conn != nil:
+		review, err = doGRPC(ctx, call)
+	case s.modeConfig.Batch != nil:
+		review, err = s.batcher.do(ctx, call, s.modeConfig.Batch)
+	default:
+		review, err = doHTTP(ctx, call)
+	}
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	defer func() {
-		if cerr := res.Body.Close(); cerr != nil {
-			if err != nil {
-				err = errors.Wrap(err, cerr.Error())
-			} else {
-				err = cerr
-			}
-		}
-	}()
-
-	if res.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("bad status code %d from the server: %s", res.StatusCode, u.String())
+	if err := validateResponse(call.review.Request, review); err != nil {
+		return nil, err
 	}
-	result, err := ioutil.ReadAll(res.Body)
+	return review.Response, nil
+}
+
+// doHTTP posts the review in JSON to webhook, and returns the review answered
+func doHTTP(ctx context.Context, call *webhookCall) (*requests.AutoscaleReview, error) {
+	code, result, err := postJSON(ctx, call.client, call.url, call.token, call.body)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	var faResp requests.AutoscaleReview
-	err = json.Unmarshal(result, &faResp)
+	if code != http.StatusOK {
+		// the server explains the failure by the result in body
+		if json.Unmarshal(result, &faResp) == nil && faResp.Response != nil && faResp.Response.Result != nil {
+			return nil, rejectedByResult(faResp.Response.Result, int32(code))
+		}
+		return nil, badStatusCode(code, call.url)
+	}
+	if err := json.Unmarshal(result, &faResp); err != nil {
+		return nil, err
+	}
+	return &faResp, nil
+}
+
+// postJSON posts body in JSON to u, and returns the status code and the body answered
+func postJSON(ctx context.Context, client *http.Client, u *url.URL, token string, body []byte) (int, []byte, error) {
+	httpReq, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
 	if err != nil {
-		return 0, err
+		return 0, nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/json")
+	if len(token) != 0 {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
 	}
-	if faResp.Response == nil {
-		return 0, fmt.Errorf("received empty reponse")
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return 0, nil, err
 	}
-	if faResp.Response.Scale {
-		return faResp.Response.Replicas, nil
+	defer res.Body.Close()
+
+	result, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
 	}
-	return currentReplicas, nil
+	return res.StatusCode, result, nil
+}
 
+// badStatusCode returns the rejection of a non-200 answer without result
+func badStatusCode(code int, u *url.URL) *WebhookRejectedError {
+	return &WebhookRejectedError{Code: int32(code), Reason: ReasonBadStatusCode,
+		Message: fmt.Sprintf("bad status code %d from the server: %s", code, u.String())}
 }
 
 func (s *WebhookScaler) ScalerName() string {
 	return s.name
 }
 
-// buildURLFromWebhookPolicy - build URL for Webhook and set CARoot for client Transport
+// SetTargetStatus sets the state of scale target sent to webhook
+func (s *WebhookScaler) SetTargetStatus(status TargetStatus) {
+	s.target = status
+}
+
+// getCredentials returns the client certificate and the bearer token in the authentication secret of webhook,
+// all of them are empty if no authentication set
+func (s *WebhookScaler) getCredentials(gpa *autoscalingv1.GeneralPodAutoscaler) ([]byte, []byte, string, error) {
+	auth := s.modeConfig.Authentication
+	if auth == nil {
+		return nil, nil, "", nil
+	}
+	if s.secrets == nil {
+		return nil, nil, "", errors.Errorf("can not get webhook authentication secret %v", auth.SecretName)
+	}
+	secret, err := s.secrets.Secrets(gpa.Namespace).Get(auth.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "get webhook authentication secret %v failed", auth.SecretName)
+	}
+	return ParseWebhookSecret(secret)
+}
+
+// ParseWebhookSecret returns the client certificate, the key and the bearer token in secret. The certificate
+// and the key should be set together, and at least one of certificate and token should be set.
+func ParseWebhookSecret(secret *corev1.Secret) ([]byte, []byte, string, error) {
+	certPEM := secret.Data[corev1.TLSCertKey]
+	keyPEM := secret.Data[corev1.TLSPrivateKeyKey]
+	token := string(secret.Data[corev1.ServiceAccountTokenKey])
+	if (len(certPEM) == 0) != (len(keyPEM) == 0) {
+		return nil, nil, "", errors.Errorf("%v and %v of secret %v should be set together",
+			corev1.TLSCertKey, corev1.TLSPrivateKeyKey, secret.Name)
+	}
+	if len(certPEM) == 0 && len(token) == 0 {
+		return nil, nil, "", errors.Errorf("secret %v should have %v and %v, or %v", secret.Name,
+			corev1.TLSCertKey, corev1.TLSPrivateKeyKey, corev1.ServiceAccountTokenKey)
+	}
+	if len(certPEM) != 0 {
+		if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			return nil, nil, "", errors.Wrapf(err, "invalid client certificate in secret %v", secret.Name)
+		}
+	}
+	return certPEM, keyPEM, token, nil
+}
+
+// validateResponse checks the response is a reply of request and the replicas in it is valid
+func validateResponse(request *requests.AutoscaleRequest, review *requests.AutoscaleReview) error {
+	if len(review.APIVersion) != 0 && review.APIVersion != requests.APIVersion {
+		return &WebhookRejectedError{Reason: ReasonUnsupportedVersion,
+			Message: fmt.Sprintf("unsupported apiVersion %v of response", review.APIVersion)}
+	}
+	resp := review.Response
+	if resp == nil {
+		return &WebhookRejectedError{Reason: ReasonInvalidResponse, Message: "received empty response"}
+	}
+	if resp.Result != nil && (resp.Result.Status == metav1.StatusFailure ||
+		(resp.Result.Code != 0 && resp.Result.Code != http.StatusOK)) {
+		return rejectedByResult(resp.Result, http.StatusOK)
+	}
+	if resp.UID != request.UID {
+		return &WebhookRejectedError{Reason: ReasonUIDMismatch,
+			Message: fmt.Sprintf("uid %q of response does not match uid %q of request", resp.UID, request.UID)}
+	}
+	if resp.Scale && resp.Replicas < 0 {
+		return &WebhookRejectedError{Reason: ReasonInvalidResponse,
+			Message: fmt.Sprintf("replicas %d of response should not be negative", resp.Replicas)}
+	}
+	// scaling to zero is only allowed by the gpa itself, its idle scaling is validated with the spec
+	if resp.MinReplicas != nil && *resp.MinReplicas < 1 &&
+		(*resp.MinReplicas < 0 || request.MinReplicas == nil || *request.MinReplicas != 0) {
+		return &WebhookRejectedError{Reason: ReasonInvalidResponse,
+			Message: fmt.Sprintf("minReplicas %d of response should be positive unless minReplicas of gpa is 0",
+				*resp.MinReplicas)}
+	}
+	if resp.MaxReplicas != nil && (*resp.MaxReplicas < 1 ||
+		(resp.MinReplicas != nil && *resp.MaxReplicas < *resp.MinReplicas)) {
+		return &WebhookRejectedError{Reason: ReasonInvalidResponse,
+			Message: fmt.Sprintf("maxReplicas %d of response should be positive and not less than minReplicas",
+				*resp.MaxReplicas)}
+	}
+	if resp.TTLSeconds != nil && *resp.TTLSeconds < 0 {
+		return &WebhookRejectedError{Reason: ReasonInvalidResponse,
+			Message: fmt.Sprintf("ttlSeconds %d of response should not be negative", *resp.TTLSeconds)}
+	}
+	return nil
+}
+
+// rejectedByResult returns the rejection by the result of server, code is the status code of answer which
+// is used if the result has no code
+func rejectedByResult(result *metav1.Status, code int32) *WebhookRejectedError {
+	e := &WebhookRejectedError{Code: result.Code, Reason: string(result.Reason), Message: result.Message}
+	if e.Code == 0 {
+		e.Code = code
+	}
+	if len(e.Reason) == 0 {
+		e.Reason = ReasonRejected
+	}
+	return e
+}
+
+// buildURLFromWebhookPolicy - build URL for Webhook
 func (s *WebhookScaler) buildURLFromWebhookPolicy() (u *url.URL, err error) {
 	w := s.modeConfig
 	if w.URL != nil && w.Service != nil {
@@ -128,10 +561,6 @@ func (s *WebhookScaler) buildURLFromWebhookPolicy() (u *url.URL, err error) {
 	scheme := "http"
 	if w.CABundle != nil {
 		scheme = "https"
-
-		if err := setCABundle(w.CABundle); err != nil {
-			return nil, err
-		}
 	}
 
 	if w.URL != nil {
@@ -150,16 +579,18 @@ func (s *WebhookScaler) buildURLFromWebhookPolicy() (u *url.URL, err error) {
 		return nil, errors.New("service name was not provided")
 	}
 
-	if w.Service.Path == nil {
-		empty := ""
-		w.Service.Path = &empty
+	// the defaults are not written back, the webhook mode is hashed for the cached answer
+	path := ""
+	if w.Service.Path != nil {
+		path = *w.Service.Path
 	}
 
-	if w.Service.Namespace == "" {
-		w.Service.Namespace = "default"
+	namespace := w.Service.Namespace
+	if namespace == "" {
+		namespace = "default"
 	}
 
-	return createURL(scheme, w.Service.Name, w.Service.Namespace, *w.Service.Path, w.Service.Port), nil
+	return createURL(scheme, w.Service.Name, namespace, path, w.Service.Port), nil
 }
 
 // moved to a separate method to cover it with unit tests and check that URL corresponds to a proper pattern
@@ -175,18 +606,3 @@ func createURL(scheme, name, namespace, path string, port *int32) *url.URL {
 		Path:   path,
 	}
 }
-
-func setCABundle(caBundle []byte) error {
-	// We can have multiple fleetautoscalers with different CABundles defined,
-	// so we switch client.Transport before each POST request
-	rootCAs := x509.NewCertPool()
-	if ok := rootCAs.AppendCertsFromPEM(caBundle); !ok {
-		return errors.New("no certs were appended from caBundle")
-	}
-	client.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{
-			RootCAs: rootCAs,
-		},
-	}
-	return nil
-}
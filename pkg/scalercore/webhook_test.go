@@ -0,0 +1,359 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+
+	autoscalingv1 "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	"github.com/ocgi/general-pod-autoscaler/pkg/requests"
+)
+
+func TestWebhookAuthentication(t *testing.T) {
+	serverCA, serverCAKey, caBundle := newTestCA(t, "webhook-server-ca")
+	clientCA, clientCAKey, _ := newTestCA(t, "webhook-client-ca")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		webhookHandler(3)(w, r)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{newTestKeyPair(t, serverCA, serverCAKey, x509.ExtKeyUsageServerAuth)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	certPEM, keyPEM := newTestCert(t, clientCA, clientCAKey, x509.ExtKeyUsageClientAuth)
+	secrets := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-auth", Namespace: "test-namespace"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:             certPEM,
+				corev1.TLSPrivateKeyKey:       keyPEM,
+				corev1.ServiceAccountTokenKey: []byte("test-token"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-token", Namespace: "test-namespace"},
+			Data:       map[string][]byte{corev1.ServiceAccountTokenKey: []byte("test-token")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-cert", Namespace: "test-namespace"},
+			Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+		},
+	).CoreV1()
+
+	cases := []struct {
+		name   string
+		auth   *autoscalingv1.WebhookAuthentication
+		hasErr bool
+	}{
+		{name: "client certificate and token", auth: &autoscalingv1.WebhookAuthentication{SecretName: "webhook-auth"}},
+		{name: "no authentication", hasErr: true},
+		{name: "no client certificate", auth: &autoscalingv1.WebhookAuthentication{SecretName: "webhook-token"}, hasErr: true},
+		{name: "no token", auth: &autoscalingv1.WebhookAuthentication{SecretName: "webhook-cert"}, hasErr: true},
+		{name: "secret not found", auth: &autoscalingv1.WebhookAuthentication{SecretName: "not-found"}, hasErr: true},
+	}
+	gpa := &autoscalingv1.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"},
+	}
+	for _, c := range cases {
+		mode := &autoscalingv1.WebhookMode{
+			WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL, CABundle: caBundle},
+			Authentication:      c.auth,
+		}
+		replicas, err := NewWebhookScaler(mode, nil, nil, secrets, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 1)
+		if c.hasErr {
+			if err == nil {
+				t.Errorf("%v: desired error, actual replicas: %v", c.name, replicas)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", c.name, err)
+			continue
+		}
+		if replicas != 3 {
+			t.Errorf("%v: desired replicas: 3, actual: %v", c.name, replicas)
+		}
+	}
+}
+
+func TestParseWebhookSecret(t *testing.T) {
+	ca, caKey, _ := newTestCA(t, "webhook-client-ca")
+	certPEM, keyPEM := newTestCert(t, ca, caKey, x509.ExtKeyUsageClientAuth)
+	cases := []struct {
+		name   string
+		data   map[string][]byte
+		hasErr bool
+	}{
+		{name: "token", data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token")}},
+		{name: "client certificate", data: map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM}},
+		{name: "empty", hasErr: true},
+		{name: "certificate without key", data: map[string][]byte{corev1.TLSCertKey: certPEM}, hasErr: true},
+		{name: "invalid certificate", data: map[string][]byte{corev1.TLSCertKey: keyPEM, corev1.TLSPrivateKeyKey: keyPEM},
+			hasErr: true},
+	}
+	for _, c := range cases {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "webhook-auth"}, Data: c.data}
+		if _, _, _, err := ParseWebhookSecret(secret); (err != nil) != c.hasErr {
+			t.Errorf("%v: desired error: %v, actual: %v", c.name, c.hasErr, err)
+		}
+	}
+}
+
+func TestWebhookRequest(t *testing.T) {
+	var (
+		received        requests.AutoscaleReview
+		responseVersion string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(requests.AutoscaleReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: responseVersion, Kind: requests.Kind},
+			Response: &requests.AutoscaleResponse{UID: received.Request.UID, Scale: true, Replicas: 4},
+		})
+	}))
+	defer server.Close()
+
+	gpa := &autoscalingv1.GeneralPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"},
+		Spec: autoscalingv1.GeneralPodAutoscalerSpec{
+			MinReplicas: intPtr(2),
+			MaxReplicas: 10,
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				Kind:       "Squad",
+				Name:       "squad-example",
+				APIVersion: "carrier.ocgi.dev/v1alpha1",
+			},
+		},
+		Status: autoscalingv1.GeneralPodAutoscalerStatus{
+			CurrentMetrics: []autoscalingv1.MetricStatus{
+				{
+					Type: autoscalingv1.ResourceMetricSourceType,
+					Resource: &autoscalingv1.ResourceMetricStatus{
+						Name:    corev1.ResourceCPU,
+						Current: autoscalingv1.MetricValueStatus{AverageValue: resource.NewMilliQuantity(500, resource.DecimalSI)},
+					},
+				},
+			},
+		},
+	}
+	mode := &autoscalingv1.WebhookMode{
+		WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL},
+		Parameters:          map[string]string{"buffer": "2"},
+	}
+	s := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", clock.RealClock{})
+	s.(TargetObserver).SetTargetStatus(TargetStatus{
+		Selector:  "app=squad-example",
+		ReadyPods: intPtr(2),
+		TotalPods: intPtr(3),
+	})
+	replicas, err := s.GetReplicas(gpa, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas != 4 {
+		t.Errorf("desired replicas: 4, actual: %v", replicas)
+	}
+	if received.APIVersion != requests.APIVersion || received.Kind != requests.Kind {
+		t.Errorf("desired %v %v, actual: %v %v", requests.APIVersion, requests.Kind, received.APIVersion, received.Kind)
+	}
+	desired := &requests.AutoscaleRequest{
+		UID:             received.Request.UID,
+		Name:            "squad-example",
+		Namespace:       "test-namespace",
+		Parameters:      map[string]string{"buffer": "2"},
+		CurrentReplicas: 3,
+		MinReplicas:     intPtr(2),
+		MaxReplicas:     10,
+		Target:          &requests.TargetReference{Kind: "Squad", APIVersion: "carrier.ocgi.dev/v1alpha1"},
+		Selector:        "app=squad-example",
+		ReadyPods:       intPtr(2),
+		TotalPods:       intPtr(3),
+		CurrentMetrics:  gpa.Status.CurrentMetrics,
+	}
+	if !apiequality.Semantic.DeepEqual(desired, received.Request) {
+		t.Errorf("desired request: %+v, actual: %+v", desired, received.Request)
+	}
+
+	// a server replies with other version is not understood
+	responseVersion = "autoscaling.ocgi.dev/v2"
+	if _, err := s.GetReplicas(gpa, 3); err == nil {
+		t.Error("response of unsupported apiVersion should fail")
+	}
+}
+
+func TestWebhookResponseValidation(t *testing.T) {
+	cases := []struct {
+		name string
+		// reply builds the response of request uid
+		reply    func(uid types.UID) *requests.AutoscaleResponse
+		code     int
+		replicas int32
+		// minReplicas is the minReplicas of gpa
+		minReplicas *int32
+		// rejected is the desired rejection, nil if the response is valid
+		rejected *WebhookRejectedError
+	}{
+		{
+			name: "valid",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, Scale: true, Replicas: 5}
+			},
+			replicas: 5,
+		},
+		{
+			name: "not scale",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, Result: &metav1.Status{Code: http.StatusOK}}
+			},
+			replicas: 3,
+		},
+		{
+			name: "uid mismatch",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: "other", Scale: true, Replicas: 5}
+			},
+			rejected: &WebhookRejectedError{Reason: ReasonUIDMismatch},
+		},
+		{
+			name: "negative replicas",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, Scale: true, Replicas: -1}
+			},
+			rejected: &WebhookRejectedError{Reason: ReasonInvalidResponse},
+		},
+		{
+			name: "max below min",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, MinReplicas: intPtr(5), MaxReplicas: intPtr(4)}
+			},
+			rejected: &WebhookRejectedError{Reason: ReasonInvalidResponse},
+		},
+		{
+			name: "min replicas zero",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, MinReplicas: intPtr(0)}
+			},
+			minReplicas: intPtr(1),
+			rejected:    &WebhookRejectedError{Reason: ReasonInvalidResponse},
+		},
+		{
+			name: "min replicas zero of gpa scaled to zero",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, MinReplicas: intPtr(0)}
+			},
+			minReplicas: intPtr(0),
+			replicas:    3,
+		},
+		{
+			name: "negative ttl",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, Scale: true, Replicas: 5, TTLSeconds: intPtr(-1)}
+			},
+			rejected: &WebhookRejectedError{Reason: ReasonInvalidResponse},
+		},
+		{
+			name:     "empty response",
+			reply:    func(uid types.UID) *requests.AutoscaleResponse { return nil },
+			rejected: &WebhookRejectedError{Reason: ReasonInvalidResponse},
+		},
+		{
+			name: "result with failure",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, Result: &metav1.Status{Status: metav1.StatusFailure,
+					Code: http.StatusConflict, Reason: "Draining", Message: "workload is draining"}}
+			},
+			rejected: &WebhookRejectedError{Code: http.StatusConflict, Reason: "Draining", Message: "workload is draining"},
+		},
+		{
+			name: "non-200 with result",
+			reply: func(uid types.UID) *requests.AutoscaleResponse {
+				return &requests.AutoscaleResponse{UID: uid, Result: &metav1.Status{Message: "no metrics of workload"}}
+			},
+			code: http.StatusServiceUnavailable,
+			rejected: &WebhookRejectedError{Code: http.StatusServiceUnavailable, Reason: ReasonRejected,
+				Message: "no metrics of workload"},
+		},
+		{
+			name:     "non-200 without result",
+			reply:    func(uid types.UID) *requests.AutoscaleResponse { return nil },
+			code:     http.StatusInternalServerError,
+			rejected: &WebhookRejectedError{Code: http.StatusInternalServerError, Reason: ReasonBadStatusCode},
+		},
+	}
+	for _, c := range cases {
+		gpa := &autoscalingv1.GeneralPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-gpa", Namespace: "test-namespace"},
+			Spec:       autoscalingv1.GeneralPodAutoscalerSpec{MinReplicas: c.minReplicas},
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var review requests.AutoscaleReview
+			if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			review.Response = c.reply(review.Request.UID)
+			if c.code != 0 {
+				w.WriteHeader(c.code)
+			}
+			json.NewEncoder(w).Encode(review)
+		}))
+		mode := &autoscalingv1.WebhookMode{WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &server.URL}}
+		replicas, err := NewWebhookScaler(mode, nil, nil, nil, "test-namespace/test-gpa", clock.RealClock{}).GetReplicas(gpa, 3)
+		server.Close()
+		if c.rejected == nil {
+			if err != nil {
+				t.Errorf("%v: %v", c.name, err)
+			} else if replicas != c.replicas {
+				t.Errorf("%v: desired replicas: %v, actual: %v", c.name, c.replicas, replicas)
+			}
+			continue
+		}
+		rejected, ok := err.(*WebhookRejectedError)
+		if !ok {
+			t.Errorf("%v: desired rejection, actual: %v", c.name, err)
+			continue
+		}
+		if rejected.Code != c.rejected.Code || rejected.Reason != c.rejected.Reason ||
+			(len(c.rejected.Message) != 0 && rejected.Message != c.rejected.Message) {
+			t.Errorf("%v: desired rejection: %+v, actual: %+v", c.name, c.rejected, rejected)
+		}
+	}
+}
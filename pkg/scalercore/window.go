@@ -0,0 +1,115 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// windowLookbacks are the periods searched back from now for the last opening of a window,
+// from the shortest, so a frequent start is found without walking through a whole year
+var windowLookbacks = []time.Duration{
+	time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	31 * 24 * time.Hour,
+	366 * 24 * time.Hour,
+}
+
+// Window is a time window, it opens at the fire times of Start, and closes at the next fire time
+// of End after it, or after Duration.
+type Window struct {
+	Start    cron.Schedule
+	End      cron.Schedule
+	Duration time.Duration
+}
+
+// ParseWindow parses the window of start and end, or of start and duration, start and end should match
+// crontab format. Exactly one of end and duration should be set.
+func ParseWindow(start, end string, duration *metav1.Duration) (*Window, error) {
+	if len(start) == 0 {
+		return nil, errors.New("start should not empty")
+	}
+	startSched, err := cron.ParseStandard(start)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse start %v failed", start)
+	}
+	w := &Window{Start: startSched}
+	switch {
+	case len(end) != 0 && duration != nil:
+		return nil, errors.New("only one of end and duration should set")
+	case len(end) != 0:
+		w.End, err = cron.ParseStandard(end)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse end %v failed", end)
+		}
+	case duration != nil:
+		if duration.Duration <= 0 {
+			return nil, errors.New("duration should be greater than 0")
+		}
+		w.Duration = duration.Duration
+	default:
+		return nil, errors.New("one of end and duration should set")
+	}
+	return w, nil
+}
+
+// Active returns the opening and the closing time of the window that now is in, nil is returned
+// if the window is closed. The schedules are evaluated in the location of now.
+func (w *Window) Active(now time.Time) (*time.Time, *time.Time) {
+	opened, ok := lastFireTime(w.Start, now)
+	if !ok {
+		return nil, nil
+	}
+	closed := opened.Add(w.Duration)
+	if w.End != nil {
+		closed = w.End.Next(opened)
+	}
+	if !now.Before(closed) {
+		return nil, nil
+	}
+	return &opened, &closed
+}
+
+// lastFireTime returns the last fire time of sched not after now
+func lastFireTime(sched cron.Schedule, now time.Time) (time.Time, bool) {
+	for _, lookback := range windowLookbacks {
+		var (
+			last  time.Time
+			found bool
+		)
+		// Next returns the time strictly after, so step back a second to include the fire time at from
+		for t := sched.Next(now.Add(-lookback - time.Second)); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+			last, found = t, true
+		}
+		if found {
+			return last, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// windowName returns the name of the window of start and end, or of start and duration
+func windowName(start, end string, duration *metav1.Duration) string {
+	if duration != nil {
+		return fmt.Sprintf("start %s duration %s", start, duration.Duration)
+	}
+	return fmt.Sprintf("start %s end %s", start, end)
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 The OCGI Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scalercore
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWindowActive(t *testing.T) {
+	// 2020-12-14 is a Monday
+	day := func(d, hour, min int) *time.Time {
+		t := time.Date(2020, 12, d, hour, min, 0, 0, time.UTC)
+		return &t
+	}
+	for _, c := range []struct {
+		name          string
+		start         string
+		end           string
+		duration      *metav1.Duration
+		now           *time.Time
+		desiredOpened *time.Time
+		desiredClosed *time.Time
+	}{
+		{
+			name:          "in window",
+			start:         "0 9 * * *",
+			end:           "0 18 * * *",
+			now:           day(16, 17, 59),
+			desiredOpened: day(16, 9, 0),
+			desiredClosed: day(16, 18, 0),
+		},
+		{
+			name:  "before window",
+			start: "0 9 * * *",
+			end:   "0 18 * * *",
+			now:   day(16, 8, 59),
+		},
+		{
+			name:  "window closed",
+			start: "0 9 * * *",
+			end:   "0 18 * * *",
+			now:   day(16, 18, 0),
+		},
+		{
+			name:          "window opened now",
+			start:         "0 9 * * *",
+			end:           "0 18 * * *",
+			now:           day(16, 9, 0),
+			desiredOpened: day(16, 9, 0),
+			desiredClosed: day(16, 18, 0),
+		},
+		{
+			name:          "across midnight",
+			start:         "0 22 * * *",
+			end:           "0 6 * * *",
+			now:           day(16, 2, 0),
+			desiredOpened: day(15, 22, 0),
+			desiredClosed: day(16, 6, 0),
+		},
+		{
+			name:          "duration, opened days ago",
+			start:         "0 9 * * 1",
+			duration:      &metav1.Duration{Duration: 48 * time.Hour},
+			now:           day(16, 8, 59),
+			desiredOpened: day(14, 9, 0),
+			desiredClosed: day(16, 9, 0),
+		},
+		{
+			name:     "duration passed",
+			start:    "0 9 * * 1",
+			duration: &metav1.Duration{Duration: 48 * time.Hour},
+			now:      day(16, 9, 0),
+		},
+		{
+			name:          "opened weeks ago",
+			start:         "0 0 1 * *",
+			end:           "0 0 20 * *",
+			now:           day(18, 12, 0),
+			desiredOpened: day(1, 0, 0),
+			desiredClosed: day(20, 0, 0),
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			w, err := ParseWindow(c.start, c.end, c.duration)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opened, closed := w.Active(*c.now)
+			if c.desiredOpened == nil {
+				if opened != nil || closed != nil {
+					t.Errorf("desired window closed, actual: %v - %v", opened, closed)
+				}
+				return
+			}
+			if opened == nil || closed == nil {
+				t.Fatalf("desired window %v - %v, actual closed", c.desiredOpened, c.desiredClosed)
+			}
+			if !opened.Equal(*c.desiredOpened) || !closed.Equal(*c.desiredClosed) {
+				t.Errorf("desired window %v - %v, actual: %v - %v", c.desiredOpened, c.desiredClosed, opened, closed)
+			}
+		})
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		start    string
+		end      string
+		duration *metav1.Duration
+		err      bool
+	}{
+		{name: "start and end", start: "0 9 * * *", end: "0 18 * * *"},
+		{name: "start and duration", start: "0 9 * * *", duration: &metav1.Duration{Duration: time.Hour}},
+		{name: "start not set", end: "0 18 * * *", err: true},
+		{name: "neither end nor duration", start: "0 9 * * *", err: true},
+		{name: "both end and duration", start: "0 9 * * *", end: "0 18 * * *", duration: &metav1.Duration{Duration: time.Hour}, err: true},
+		{name: "invalid start", start: "0 25 * * *", end: "0 18 * * *", err: true},
+		{name: "invalid end", start: "0 9 * * *", end: "0 18 * *", err: true},
+		{name: "zero duration", start: "0 9 * * *", duration: &metav1.Duration{}, err: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseWindow(c.start, c.end, c.duration)
+			if c.err != (err != nil) {
+				t.Errorf("desired error: %v, actual: %v", c.err, err)
+			}
+		})
+	}
+}
@@ -24,15 +24,18 @@ import (
 	"k8s.io/klog"
 
 	"github.com/robfig/cron"
-	"k8s.io/api/admissionregistration/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	pathvalidation "k8s.io/apimachinery/pkg/api/validation/path"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/util/webhook"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	mapset "github.com/deckarep/golang-set"
 	autoscaling "github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	listers "github.com/ocgi/general-pod-autoscaler/pkg/client/listers/autoscaling/v1alpha1"
 )
 
 const (
@@ -40,19 +43,23 @@ const (
 	MaxPeriodSeconds int32 = 1800
 	// MaxStabilizationWindowSeconds is the largest allowed stabilization window (in seconds)
 	MaxStabilizationWindowSeconds int32 = 3600
+	// maxWebhookRetries is the largest allowed number of retries of a failed webhook call
+	maxWebhookRetries int32 = 10
 )
 
 // ValidateHorizontalPodAutoscalerName can be used to check whether the given autoscaler name is valid.
 // Prefix indicates this name will be used as part of generation, in which case trailing dashes are allowed.
 var ValidateHorizontalPodAutoscalerName = apimachineryvalidation.NameIsDNSSubdomain
 
-func validateHorizontalPodAutoscalerSpec(autoscaler autoscaling.GeneralPodAutoscalerSpec, fldPath *field.Path,
-	minReplicasLowerBound int32) field.ErrorList {
+func validateHorizontalPodAutoscalerSpec(autoscaler autoscaling.GeneralPodAutoscalerSpec, namespace string,
+	fldPath *field.Path, minReplicasLowerBound int32, calendars listers.HolidayCalendarLister,
+	secrets corev1client.SecretsGetter) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	if autoscaler.AutoScalingDrivenMode.CronMetricMode != nil {
 		klog.Infof("Run cronHpa validate")
-		if refErrs := validateCronMetric(autoscaler.AutoScalingDrivenMode.CronMetricMode, fldPath.Child("cronMetric"), minReplicasLowerBound); len(refErrs) > 0 {
+		if refErrs := validateCronMetric(autoscaler.AutoScalingDrivenMode.CronMetricMode, fldPath.Child("cronMetric"),
+			minReplicasLowerBound, calendars); len(refErrs) > 0 {
 			allErrs = append(allErrs, refErrs...)
 		}
 	} else {
@@ -71,17 +78,18 @@ func validateHorizontalPodAutoscalerSpec(autoscaler autoscaling.GeneralPodAutosc
 		allErrs = append(allErrs, refErrs...)
 	}
 	if autoscaler.AutoScalingDrivenMode.MetricMode != nil {
-		if refErrs := validateMetrics(autoscaler.AutoScalingDrivenMode.MetricMode.Metrics, fldPath.Child("metrics"), autoscaler.MinReplicas); len(refErrs) > 0 {
+		if refErrs := validateMetrics(autoscaler.AutoScalingDrivenMode.MetricMode.Metrics, fldPath.Child("metrics")); len(refErrs) > 0 {
 			allErrs = append(allErrs, refErrs...)
 		}
 	}
 	if autoscaler.AutoScalingDrivenMode.WebhookMode != nil {
-		if refErrs := validateWebhook(autoscaler.AutoScalingDrivenMode.WebhookMode.WebhookClientConfig, fldPath.Child("webhook")); len(refErrs) > 0 {
+		if refErrs := validateWebhook(autoscaler.AutoScalingDrivenMode.WebhookMode, namespace, fldPath.Child("webhook"),
+			secrets); len(refErrs) > 0 {
 			allErrs = append(allErrs, refErrs...)
 		}
 	}
 	if autoscaler.AutoScalingDrivenMode.TimeMode != nil {
-		if refErrs := validateTime(autoscaler.AutoScalingDrivenMode.TimeMode.TimeRanges, fldPath.Child("time")); len(refErrs) > 0 {
+		if refErrs := validateTime(autoscaler.AutoScalingDrivenMode.TimeMode, fldPath.Child("time")); len(refErrs) > 0 {
 			allErrs = append(allErrs, refErrs...)
 		}
 	}
@@ -93,6 +101,83 @@ func validateHorizontalPodAutoscalerSpec(autoscaler autoscaling.GeneralPodAutosc
 	if refErrs := validateBehavior(autoscaler.Behavior, fldPath.Child("behavior")); len(refErrs) > 0 {
 		allErrs = append(allErrs, refErrs...)
 	}
+	if refErrs := validateModeSelectPolicy(autoscaler, fldPath.Child("modeSelectPolicy")); len(refErrs) > 0 {
+		allErrs = append(allErrs, refErrs...)
+	}
+	if refErrs := validateOverride(autoscaler.Override, fldPath.Child("override")); len(refErrs) > 0 {
+		allErrs = append(allErrs, refErrs...)
+	}
+	if refErrs := validateIdleScaling(autoscaler, fldPath); len(refErrs) > 0 {
+		allErrs = append(allErrs, refErrs...)
+	}
+	if refErrs := validateMetricAggregation(autoscaler.MetricAggregation, fldPath.Child("metricAggregation")); len(refErrs) > 0 {
+		allErrs = append(allErrs, refErrs...)
+	}
+	return allErrs
+}
+
+// validateMetricAggregation checks the policy of metric aggregation and its quorum
+func validateMetricAggregation(aggregation *autoscaling.MetricAggregation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if aggregation == nil {
+		return allErrs
+	}
+	validPolicies := sets.NewString(string(autoscaling.MaxMetricAggregation), string(autoscaling.MinMetricAggregation),
+		string(autoscaling.AverageMetricAggregation), string(autoscaling.WeightedAverageMetricAggregation),
+		string(autoscaling.QuorumMetricAggregation))
+	if !validPolicies.Has(string(aggregation.Policy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("policy"), aggregation.Policy, validPolicies.List()))
+	}
+	if aggregation.Quorum == nil {
+		return allErrs
+	}
+	if aggregation.Policy != autoscaling.QuorumMetricAggregation {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("quorum"), "may only be set with Quorum policy"))
+	}
+	if *aggregation.Quorum < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("quorum"), *aggregation.Quorum, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// validateOverride checks the replicas held by override
+func validateOverride(override *autoscaling.ScaleOverride, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if override == nil {
+		return allErrs
+	}
+	if override.Replicas != nil && *override.Replicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *override.Replicas, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+// validateModeSelectPolicy checks the policy is Max, Min or the name of a configured mode
+func validateModeSelectPolicy(autoscaler autoscaling.GeneralPodAutoscalerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if autoscaler.ModeSelectPolicy == nil {
+		return allErrs
+	}
+	validPolicies := sets.NewString(string(autoscaling.MaxModeSelect), string(autoscaling.MinModeSelect))
+	mode := autoscaler.AutoScalingDrivenMode
+	if mode.MetricMode != nil {
+		validPolicies.Insert(autoscaling.MetricModeName)
+	}
+	if mode.CronMetricMode != nil {
+		validPolicies.Insert(autoscaling.CronMetricModeName)
+	}
+	if mode.WebhookMode != nil {
+		validPolicies.Insert(autoscaling.WebhookModeName)
+	}
+	if mode.TimeMode != nil {
+		validPolicies.Insert(autoscaling.TimeModeName)
+	}
+	if mode.EventMode != nil {
+		validPolicies.Insert(autoscaling.EventModeName)
+	}
+	if !validPolicies.Has(string(*autoscaler.ModeSelectPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath, *autoscaler.ModeSelectPolicy, validPolicies.List()))
+	}
 	return allErrs
 }
 
@@ -120,8 +205,10 @@ func ValidateCrossVersionObjectReference(ref autoscaling.CrossVersionObjectRefer
 }
 
 // ValidateHorizontalPodAutoscaler validates a HorizontalPodAutoscaler and returns an
-// ErrorList with any errors.
-func ValidateHorizontalPodAutoscaler(autoscaler *autoscaling.GeneralPodAutoscaler) field.ErrorList {
+// ErrorList with any errors. The referenced holiday calendars are checked by calendars if not nil,
+// and the referenced webhook authentication secret is checked by secrets if not nil.
+func ValidateHorizontalPodAutoscaler(autoscaler *autoscaling.GeneralPodAutoscaler,
+	calendars listers.HolidayCalendarLister, secrets corev1client.SecretsGetter) field.ErrorList {
 	allErrs := apimachineryvalidation.ValidateObjectMeta(&autoscaler.ObjectMeta, true, ValidateHorizontalPodAutoscalerName,
 		field.NewPath("metadata"))
 
@@ -129,19 +216,23 @@ func ValidateHorizontalPodAutoscaler(autoscaler *autoscaling.GeneralPodAutoscale
 	// 0 when GPA scale-to-zero feature is enabled
 	var minReplicasLowerBound int32
 
-	allErrs = append(allErrs, validateHorizontalPodAutoscalerSpec(autoscaler.Spec, field.NewPath("spec"), minReplicasLowerBound)...)
+	allErrs = append(allErrs, validateHorizontalPodAutoscalerSpec(autoscaler.Spec, autoscaler.Namespace, field.NewPath("spec"),
+		minReplicasLowerBound, calendars, secrets)...)
 	return allErrs
 }
 
 // ValidateHorizontalPodAutoscalerUpdate validates an update to a HorizontalPodAutoscaler and returns an
-// ErrorList with any errors.
-func ValidateHorizontalPodAutoscalerUpdate(newAutoscaler, oldAutoscaler *autoscaling.GeneralPodAutoscaler) field.ErrorList {
+// ErrorList with any errors. The referenced holiday calendars are checked by calendars if not nil,
+// and the referenced webhook authentication secret is checked by secrets if not nil.
+func ValidateHorizontalPodAutoscalerUpdate(newAutoscaler, oldAutoscaler *autoscaling.GeneralPodAutoscaler,
+	calendars listers.HolidayCalendarLister, secrets corev1client.SecretsGetter) field.ErrorList {
 	allErrs := apimachineryvalidation.ValidateObjectMetaUpdate(&newAutoscaler.ObjectMeta, &oldAutoscaler.ObjectMeta, field.NewPath("metadata"))
 
 	// minReplicasLowerBound represents a minimum value for minReplicas
 	// 0 when GPA scale-to-zero feature is enabled or GPA object already has minReplicas=0
 	var minReplicasLowerBound int32
-	allErrs = append(allErrs, validateHorizontalPodAutoscalerSpec(newAutoscaler.Spec, field.NewPath("spec"), minReplicasLowerBound)...)
+	allErrs = append(allErrs, validateHorizontalPodAutoscalerSpec(newAutoscaler.Spec, newAutoscaler.Namespace,
+		field.NewPath("spec"), minReplicasLowerBound, calendars, secrets)...)
 	return allErrs
 }
 
@@ -163,19 +254,24 @@ type CronSet struct {
 	set      mapset.Set
 }
 
-func validateCronMetric(cronMetricMode *autoscaling.CronMetricMode, fldPath *field.Path, minReplicasLowerBound int32) field.ErrorList {
+func validateCronMetric(cronMetricMode *autoscaling.CronMetricMode, fldPath *field.Path, minReplicasLowerBound int32,
+	calendars listers.HolidayCalendarLister) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if len(cronMetricMode.CronMetrics) == 0 {
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("cronMetrics"), "at least one cronMetrics should set"))
 	}
 
+	if _, err := scalercore.LoadScheduleLocation(cronMetricMode.TimeZone, ""); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), cronMetricMode.TimeZone, err.Error()))
+	}
+
 	var defaultSetNum int
-	start := time.Now()
+	now := time.Now()
 	cycleSetSlice := make([]CronSet, 0)
 	customSetSlice := make([]CronSet, 0)
 	defaultCronSpec := make([]autoscaling.CronMetricSpec, 0)
 	klog.Infof("webhook cronMetrics: %v", cronMetricMode.CronMetrics)
-	for _, cronRange := range cronMetricMode.CronMetrics {
+	for i, cronRange := range cronMetricMode.CronMetrics {
 		if cronRange.MinReplicas != nil && *cronRange.MinReplicas < minReplicasLowerBound {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), *cronRange.MinReplicas,
 				fmt.Sprintf("must be greater than or equal to %d", minReplicasLowerBound)))
@@ -186,6 +282,22 @@ func validateCronMetric(cronMetricMode *autoscaling.CronMetricMode, fldPath *fie
 		if cronRange.MinReplicas != nil && cronRange.MaxReplicas < *cronRange.MinReplicas {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), cronRange.MaxReplicas, "must be greater than or equal to `minReplicas`"))
 		}
+		if len(cronRange.Calendar) != 0 {
+			allErrs = append(allErrs, validateCalendarReference(cronRange, fldPath.Child("cronMetrics").Index(i),
+				calendars)...)
+			// calendar specs are active on holidays only, they are chosen by priority without conflict check
+			continue
+		}
+		if len(cronRange.Start) != 0 {
+			allErrs = append(allErrs, validateWindow(cronRange.Schedule, cronRange.Start, cronRange.End,
+				cronRange.Duration, fldPath.Child("cronMetrics").Index(i))...)
+			if _, err := scalercore.LoadScheduleLocation("", cronRange.TimeZone); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("cronMetrics").Index(i).Child("timeZone"),
+					cronRange.TimeZone, err.Error()))
+			}
+			// windows may last for any long, they are chosen by priority without conflict check
+			continue
+		}
 		if len(cronRange.Schedule) == 0 {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("schedule"), "should not empty"))
 		} else {
@@ -201,6 +313,20 @@ func validateCronMetric(cronMetricMode *autoscaling.CronMetricMode, fldPath *fie
 				allErrs = append(allErrs, field.Forbidden(fldPath.Child("schedule"), err.Error()))
 				continue
 			}
+			loc, err := scalercore.LoadScheduleLocation(cronMetricMode.TimeZone, cronRange.TimeZone)
+			if err != nil {
+				// invalid time zone of mode has been reported
+				if len(cronRange.TimeZone) != 0 {
+					allErrs = append(allErrs, field.Invalid(fldPath.Child("cronMetrics").Index(i).Child("timeZone"),
+						cronRange.TimeZone, err.Error()))
+				}
+				continue
+			}
+			// schedules are expanded in their own time zone, and compared in UTC
+			start := now
+			if loc != nil {
+				start = now.In(loc)
+			}
 			schSet := mapset.NewSet()
 			// year is not zero add to cycleSetSlice to validate
 			if year != 0 {
@@ -209,7 +335,7 @@ func validateCronMetric(cronMetricMode *autoscaling.CronMetricMode, fldPath *fie
 					start.Second(), start.Nanosecond(), start.Location())
 				for {
 					next = sch.Next(next)
-					schSet.Add(next)
+					schSet.Add(next.UTC())
 					if next.Year() != year {
 						break
 					}
@@ -231,9 +357,9 @@ func validateCronMetric(cronMetricMode *autoscaling.CronMetricMode, fldPath *fie
 				}
 				newSchSet := mapset.NewSet()
 				for _, date := range schSet.ToSlice() {
-					dataTime := date.(time.Time)
+					dataTime := date.(time.Time).UTC()
 					newDataTime := time.Date(year, dataTime.Month(), dataTime.Day(), dataTime.Hour(), dataTime.Minute(),
-						dataTime.Second(), dataTime.Nanosecond(), dataTime.Location())
+						dataTime.Second(), dataTime.Nanosecond(), time.UTC)
 					newSchSet.Add(newDataTime)
 				}
 				customSetSlice = append(customSetSlice, CronSet{
@@ -264,6 +390,70 @@ func validateCronMetric(cronMetricMode *autoscaling.CronMetricMode, fldPath *fie
 	return allErrs
 }
 
+// validateCalendarReference checks the schedule of a calendar spec, and the referenced calendar exists
+func validateCalendarReference(cronRange autoscaling.CronMetricSpec, fldPath *field.Path,
+	calendars listers.HolidayCalendarLister) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if cronRange.Schedule == "default" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("calendar"), "should not set with `default` schedule"))
+	} else if len(cronRange.Start) != 0 {
+		allErrs = append(allErrs, validateWindow(cronRange.Schedule, cronRange.Start, cronRange.End,
+			cronRange.Duration, fldPath)...)
+	} else if len(cronRange.Schedule) != 0 {
+		if _, _, err := scalercore.ParseStandardWithYear(cronRange.Schedule); err != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("schedule"), err.Error()))
+		}
+	}
+	if _, err := scalercore.LoadScheduleLocation("", cronRange.TimeZone); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), cronRange.TimeZone, err.Error()))
+	}
+	// calendars is nil if the validator can not access the cluster
+	if calendars == nil {
+		return allErrs
+	}
+	if _, err := calendars.Get(cronRange.Calendar); err != nil {
+		if apierrors.IsNotFound(err) {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("calendar"), cronRange.Calendar))
+		} else {
+			allErrs = append(allErrs, field.InternalError(fldPath.Child("calendar"), err))
+		}
+	}
+	return allErrs
+}
+
+// validateWindow checks the window of start and end, or of start and duration
+func validateWindow(schedule, start, end string, duration *metav1.Duration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(schedule) != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("schedule"), "should not set with `start`"))
+	}
+	if _, err := scalercore.ParseWindow(start, end, duration); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("start"), start, err.Error()))
+	}
+	return allErrs
+}
+
+// ValidateHolidayCalendar validates a HolidayCalendar and returns an ErrorList with any errors.
+func ValidateHolidayCalendar(calendar *autoscaling.HolidayCalendar) field.ErrorList {
+	allErrs := apimachineryvalidation.ValidateObjectMeta(&calendar.ObjectMeta, false,
+		apimachineryvalidation.NameIsDNSSubdomain, field.NewPath("metadata"))
+	fldPath := field.NewPath("spec").Child("holidays")
+	names := sets.NewString()
+	for i, holiday := range calendar.Spec.Holidays {
+		idxPath := fldPath.Index(i)
+		if len(holiday.Name) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "holiday name must set"))
+		} else if names.Has(holiday.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), holiday.Name))
+		}
+		names.Insert(holiday.Name)
+		if _, _, err := scalercore.ParseHoliday(holiday, time.UTC); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath, holiday, err.Error()))
+		}
+	}
+	return allErrs
+}
+
 // checkConflict check CronSet conflict info
 func checkConflict(setSlice []CronSet, allErrs field.ErrorList, fldPath *field.Path) field.ErrorList {
 	for i := 0; i <= len(setSlice); i++ {
@@ -285,35 +475,83 @@ func checkConflict(setSlice []CronSet, allErrs field.ErrorList, fldPath *field.P
 	return allErrs
 }
 
-func validateMetrics(metrics []autoscaling.MetricSpec, fldPath *field.Path, minReplicas *int32) field.ErrorList {
+func validateMetrics(metrics []autoscaling.MetricSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	hasObjectMetrics := false
-	hasExternalMetrics := false
 
 	for i, metricSpec := range metrics {
 		idxPath := fldPath.Index(i)
 		if targetErrs := validateMetricSpec(metricSpec, idxPath); len(targetErrs) > 0 {
 			allErrs = append(allErrs, targetErrs...)
 		}
-		if metricSpec.Type == autoscaling.ObjectMetricSourceType {
-			hasObjectMetrics = true
-		}
-		if metricSpec.Type == autoscaling.ExternalMetricSourceType {
-			hasExternalMetrics = true
-		}
 	}
 
-	if minReplicas != nil && *minReplicas == 0 {
-		if !hasObjectMetrics && !hasExternalMetrics {
-			allErrs = append(allErrs, field.Forbidden(fldPath, "must specify at least one Object or External metric to support scaling to zero replicas"))
-		}
-	}
+	return allErrs
+}
 
+// validateIdleScaling checks the target scaled to zero has a signal which does not need running pods, i.e.
+// an Object or External metric or webhook mode, and idle is set only if minReplicas is 0
+func validateIdleScaling(autoscaler autoscaling.GeneralPodAutoscalerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	scaleToZero := autoscaler.MinReplicas != nil && *autoscaler.MinReplicas == 0
+	if scaleToZero && !hasIdleSignal(autoscaler.AutoScalingDrivenMode) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("minReplicas"),
+			"must specify at least one Object or External metric or webhook to support scaling to zero replicas"))
+	}
+	idle := autoscaler.Idle
+	if idle == nil {
+		return allErrs
+	}
+	idlePath := fldPath.Child("idle")
+	if !scaleToZero {
+		allErrs = append(allErrs, field.Forbidden(idlePath, "requires minReplicas to be 0"))
+	}
+	if idle.IdlePeriodSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(idlePath.Child("idlePeriodSeconds"), idle.IdlePeriodSeconds,
+			"must be greater than or equal to 0"))
+	}
+	if idle.ActivationReplicas != nil && *idle.ActivationReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(idlePath.Child("activationReplicas"), *idle.ActivationReplicas,
+			"must be greater than 0"))
+	}
 	return allErrs
 }
 
-func validateWebhook(wc *v1beta1.WebhookClientConfig, fldPath *field.Path) field.ErrorList {
+// hasIdleSignal returns whether mode has a signal which does not need running pods
+func hasIdleSignal(mode autoscaling.AutoScalingDrivenMode) bool {
+	if mode.WebhookMode != nil {
+		return true
+	}
+	if mode.MetricMode == nil {
+		return false
+	}
+	for _, metricSpec := range mode.MetricMode.Metrics {
+		if metricSpec.Type == autoscaling.ObjectMetricSourceType || metricSpec.Type == autoscaling.ExternalMetricSourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWebhook checks the client config of webhook, and the authentication secret in namespace has
+// the credentials if secrets is not nil
+func validateWebhook(webhookMode *autoscaling.WebhookMode, namespace string, fldPath *field.Path,
+	secrets corev1client.SecretsGetter) field.ErrorList {
 	allErrs := field.ErrorList{}
+	if webhookMode.Authentication != nil {
+		allErrs = append(allErrs, validateWebhookAuthentication(webhookMode.Authentication, namespace,
+			fldPath.Child("authentication"), secrets)...)
+	}
+	allErrs = append(allErrs, validateWebhookResilience(webhookMode, fldPath)...)
+	switch webhookMode.Protocol {
+	case "", autoscaling.HTTPWebhookProtocol, autoscaling.GRPCWebhookProtocol:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), webhookMode.Protocol,
+			[]string{string(autoscaling.HTTPWebhookProtocol), string(autoscaling.GRPCWebhookProtocol)}))
+	}
+	if webhookMode.Batch != nil {
+		allErrs = append(allErrs, validateWebhookBatch(webhookMode, fldPath.Child("batch"))...)
+	}
+	wc := webhookMode.WebhookClientConfig
 	if wc == nil {
 		allErrs = append(allErrs, field.Forbidden(fldPath, "webhook config should not be empty"))
 		return allErrs
@@ -335,16 +573,119 @@ func validateWebhook(wc *v1beta1.WebhookClientConfig, fldPath *field.Path) field
 	return allErrs
 }
 
-func validateTime(timeRanges []autoscaling.TimeRange, fldPath *field.Path) field.ErrorList {
+// validateWebhookResilience checks the timeout, retry and circuit breaker of webhook
+func validateWebhookResilience(webhookMode *autoscaling.WebhookMode, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if webhookMode.Timeout != nil && webhookMode.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), webhookMode.Timeout.Duration,
+			"must be greater than 0"))
+	}
+	if retry := webhookMode.Retry; retry != nil {
+		retryPath := fldPath.Child("retry")
+		if retry.MaxRetries != nil && (*retry.MaxRetries < 0 || *retry.MaxRetries > maxWebhookRetries) {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("maxRetries"), *retry.MaxRetries,
+				fmt.Sprintf("must be between 0 and %d", maxWebhookRetries)))
+		}
+		if retry.Backoff != nil && retry.Backoff.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("backoff"), retry.Backoff.Duration,
+				"must be greater than 0"))
+		}
+		if retry.MaxBackoff != nil && retry.Backoff != nil && retry.MaxBackoff.Duration < retry.Backoff.Duration {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("maxBackoff"), retry.MaxBackoff.Duration,
+				"must be greater than or equal to backoff"))
+		}
+	}
+	if breaker := webhookMode.CircuitBreaker; breaker != nil {
+		breakerPath := fldPath.Child("circuitBreaker")
+		if breaker.FailureThreshold != nil && *breaker.FailureThreshold < 1 {
+			allErrs = append(allErrs, field.Invalid(breakerPath.Child("failureThreshold"), *breaker.FailureThreshold,
+				"must be greater than or equal to 1"))
+		}
+		if breaker.OpenDuration != nil && breaker.OpenDuration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(breakerPath.Child("openDuration"), breaker.OpenDuration.Duration,
+				"must be greater than 0"))
+		}
+		switch breaker.Fallback {
+		case "", autoscaling.KeepCurrentFallback, autoscaling.LastGoodFallback, autoscaling.MinFallback:
+		default:
+			allErrs = append(allErrs, field.NotSupported(breakerPath.Child("fallback"), breaker.Fallback,
+				[]string{string(autoscaling.KeepCurrentFallback), string(autoscaling.LastGoodFallback),
+					string(autoscaling.MinFallback)}))
+		}
+	}
+	return allErrs
+}
+
+// validateWebhookBatch checks the batch of webhook, it is only supported by HTTP protocol
+func validateWebhookBatch(webhookMode *autoscaling.WebhookMode, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	batch := webhookMode.Batch
+	if webhookMode.Protocol == autoscaling.GRPCWebhookProtocol {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "batch is only supported by HTTP protocol"))
+	}
+	if batch.Window != nil && batch.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("window"), batch.Window.Duration,
+			"must be greater than 0"))
+	}
+	if batch.MaxSize != nil && *batch.MaxSize < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxSize"), *batch.MaxSize,
+			"must be greater than or equal to 1"))
+	}
+	return allErrs
+}
+
+// validateWebhookAuthentication checks the authentication secret in namespace exists and has the credentials
+func validateWebhookAuthentication(auth *autoscaling.WebhookAuthentication, namespace string, fldPath *field.Path,
+	secrets corev1client.SecretsGetter) field.ErrorList {
 	allErrs := field.ErrorList{}
-	if len(timeRanges) == 0 {
+	if len(auth.SecretName) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("secretName"), ""))
+		return allErrs
+	}
+	for _, msg := range apimachineryvalidation.NameIsDNSSubdomain(auth.SecretName, false) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("secretName"), auth.SecretName, msg))
+	}
+	// secrets is nil if the validator can not access the cluster
+	if len(allErrs) != 0 || secrets == nil {
+		return allErrs
+	}
+	secret, err := secrets.Secrets(namespace).Get(auth.SecretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("secretName"), auth.SecretName))
+		} else {
+			allErrs = append(allErrs, field.InternalError(fldPath.Child("secretName"), err))
+		}
+		return allErrs
+	}
+	if _, _, _, err := scalercore.ParseWebhookSecret(secret); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("secretName"), auth.SecretName, err.Error()))
+	}
+	return allErrs
+}
+
+func validateTime(timeMode *autoscaling.TimeMode, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(timeMode.TimeRanges) == 0 {
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("timeRanges"), "at least one timeRanges should set"))
 	}
-	for _, timeRange := range timeRanges {
+	if _, err := scalercore.LoadScheduleLocation(timeMode.TimeZone, ""); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), timeMode.TimeZone, err.Error()))
+	}
+	for i, timeRange := range timeMode.TimeRanges {
+		if len(timeRange.TimeZone) != 0 {
+			if _, err := scalercore.LoadScheduleLocation("", timeRange.TimeZone); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("timeRanges").Index(i).Child("timeZone"),
+					timeRange.TimeZone, err.Error()))
+			}
+		}
 		if timeRange.DesiredReplicas == 0 {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("desiredReplicas"), "should not 0"))
 		}
-		if len(timeRange.Schedule) == 0 {
+		if len(timeRange.Start) != 0 {
+			allErrs = append(allErrs, validateWindow(timeRange.Schedule, timeRange.Start, timeRange.End,
+				timeRange.Duration, fldPath.Child("timeRanges").Index(i))...)
+		} else if len(timeRange.Schedule) == 0 {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("schedule"), "should not empty"))
 		} else {
 			_, err := cron.Parse(timeRange.Schedule)
@@ -361,7 +702,8 @@ func validateEvent(triggers []autoscaling.ScaleTriggers, fldPath *field.Path) fi
 	if len(triggers) == 0 {
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("triggers"), "at least one trigger should set"))
 	}
-	for _, trigger := range triggers {
+	for i, trigger := range triggers {
+		idxPath := fldPath.Child("triggers").Index(i)
 		if len(trigger.Type) == 0 {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("type"), "trigger type must set"))
 
@@ -369,6 +711,16 @@ func validateEvent(triggers []autoscaling.ScaleTriggers, fldPath *field.Path) fi
 		if len(trigger.Metadata) == 0 {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("medadata"), "trigger medadata must set"))
 		}
+		if len(trigger.Type) == 0 || len(trigger.Metadata) == 0 {
+			continue
+		}
+		if !scalercore.IsTriggerRegistered(trigger.Type) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("type"), trigger.Type, scalercore.RegisteredTriggers()))
+			continue
+		}
+		if _, err := scalercore.NewTriggerScaler(trigger); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("metadata"), trigger.Metadata, err.Error()))
+		}
 	}
 	return allErrs
 }
@@ -456,6 +808,10 @@ func validateMetricSpec(spec autoscaling.MetricSpec, fldPath *field.Path) field.
 		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), spec.Type, validMetricSourceTypesList))
 	}
 
+	if spec.Weight != nil && *spec.Weight < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("weight"), *spec.Weight, "must be greater than 0"))
+	}
+
 	typesPresent := sets.NewString()
 	if spec.Object != nil {
 		typesPresent.Insert("object")
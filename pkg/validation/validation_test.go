@@ -18,9 +18,15 @@ import (
 	"testing"
 	"time"
 
+	admregv1b "k8s.io/api/admissionregistration/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	listers "github.com/ocgi/general-pod-autoscaler/pkg/client/listers/autoscaling/v1alpha1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -106,7 +112,7 @@ func TestValidationCronFirst(t *testing.T) {
 	var minReplicasLowerBound int32
 	fldPath := field.NewPath("spec")
 	t.Run(tc.name, func(t *testing.T) {
-		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound)
+		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound, nil)
 		t.Logf("get validation err: %v", errList)
 		if len(errList) >= 1 {
 			t.Errorf("desired has err, actual err: %v", errList)
@@ -167,7 +173,7 @@ func TestValidationCronTwo(t *testing.T) {
 	var minReplicasLowerBound int32
 	fldPath := field.NewPath("spec")
 	t.Run(tc.name, func(t *testing.T) {
-		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound)
+		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound, nil)
 		t.Logf("get validation err: %v", errList)
 		// has conflict, must with error
 		if len(errList) < 1 {
@@ -246,7 +252,7 @@ func TestValidationCronThree(t *testing.T) {
 	var minReplicasLowerBound int32
 	fldPath := field.NewPath("spec")
 	t.Run(tc.name, func(t *testing.T) {
-		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound)
+		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound, nil)
 		t.Logf("get validation err: %v", errList)
 		// has conflict, must with error
 		if len(errList) < 1 {
@@ -325,7 +331,7 @@ func TestValidationCronFour(t *testing.T) {
 	var minReplicasLowerBound int32
 	fldPath := field.NewPath("spec")
 	t.Run(tc.name, func(t *testing.T) {
-		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound)
+		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound, nil)
 		t.Logf("get validation err: %v", errList)
 		// has conflict, must with error
 		if len(errList) >= 1 {
@@ -404,7 +410,7 @@ func TestValidationCronFive(t *testing.T) {
 	var minReplicasLowerBound int32
 	fldPath := field.NewPath("spec")
 	t.Run(tc.name, func(t *testing.T) {
-		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound)
+		errList := validateCronMetric(&tc.mode, fldPath.Child("cronMetric"), minReplicasLowerBound, nil)
 		t.Logf("get validation err: %v", errList)
 		// has conflict, must with error
 		if len(errList) < 1 {
@@ -412,3 +418,498 @@ func TestValidationCronFive(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateModeSelectPolicy(t *testing.T) {
+	policy := func(p v1alpha1.ModeSelectPolicy) *v1alpha1.ModeSelectPolicy {
+		return &p
+	}
+	mode := v1alpha1.AutoScalingDrivenMode{
+		MetricMode:  &v1alpha1.MetricMode{},
+		WebhookMode: &v1alpha1.WebhookMode{},
+	}
+	for _, c := range []struct {
+		name   string
+		policy *v1alpha1.ModeSelectPolicy
+		err    bool
+	}{
+		{name: "not set"},
+		{name: "max", policy: policy(v1alpha1.MaxModeSelect)},
+		{name: "min", policy: policy(v1alpha1.MinModeSelect)},
+		{name: "configured mode", policy: policy(v1alpha1.WebhookModeName)},
+		{name: "mode not configured", policy: policy(v1alpha1.TimeModeName), err: true},
+		{name: "unknown", policy: policy("Average"), err: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			spec := v1alpha1.GeneralPodAutoscalerSpec{AutoScalingDrivenMode: mode, ModeSelectPolicy: c.policy}
+			errList := validateModeSelectPolicy(spec, field.NewPath("spec").Child("modeSelectPolicy"))
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateOverride(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		override *v1alpha1.ScaleOverride
+		err      bool
+	}{
+		{name: "not set"},
+		{name: "paused", override: &v1alpha1.ScaleOverride{}},
+		{name: "held", override: &v1alpha1.ScaleOverride{Replicas: intPtr(3)}},
+		{name: "held at zero", override: &v1alpha1.ScaleOverride{Replicas: intPtr(0)}, err: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			errList := validateOverride(c.override, field.NewPath("spec").Child("override"))
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateIdleScaling(t *testing.T) {
+	external := &v1alpha1.MetricMode{Metrics: []v1alpha1.MetricSpec{{Type: v1alpha1.ExternalMetricSourceType}}}
+	resource := &v1alpha1.MetricMode{Metrics: []v1alpha1.MetricSpec{{Type: v1alpha1.ResourceMetricSourceType}}}
+	for _, c := range []struct {
+		name        string
+		mode        v1alpha1.AutoScalingDrivenMode
+		minReplicas *int32
+		idle        *v1alpha1.IdleScaling
+		err         bool
+	}{
+		{name: "not scaled to zero", mode: v1alpha1.AutoScalingDrivenMode{MetricMode: resource}, minReplicas: intPtr(1)},
+		{name: "external metric", mode: v1alpha1.AutoScalingDrivenMode{MetricMode: external}, minReplicas: intPtr(0),
+			idle: &v1alpha1.IdleScaling{IdlePeriodSeconds: 300, ActivationReplicas: intPtr(2)}},
+		{name: "webhook", mode: v1alpha1.AutoScalingDrivenMode{MetricMode: resource, WebhookMode: &v1alpha1.WebhookMode{}},
+			minReplicas: intPtr(0)},
+		{name: "resource metric only", mode: v1alpha1.AutoScalingDrivenMode{MetricMode: resource}, minReplicas: intPtr(0),
+			err: true},
+		{name: "idle without zero min replicas", mode: v1alpha1.AutoScalingDrivenMode{MetricMode: external},
+			minReplicas: intPtr(1), idle: &v1alpha1.IdleScaling{}, err: true},
+		{name: "negative idle period", mode: v1alpha1.AutoScalingDrivenMode{MetricMode: external}, minReplicas: intPtr(0),
+			idle: &v1alpha1.IdleScaling{IdlePeriodSeconds: -1}, err: true},
+		{name: "zero activation replicas", mode: v1alpha1.AutoScalingDrivenMode{MetricMode: external},
+			minReplicas: intPtr(0), idle: &v1alpha1.IdleScaling{ActivationReplicas: intPtr(0)}, err: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			spec := v1alpha1.GeneralPodAutoscalerSpec{AutoScalingDrivenMode: c.mode, MinReplicas: c.minReplicas,
+				Idle: c.idle}
+			errList := validateIdleScaling(spec, field.NewPath("spec"))
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateMetricAggregation(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		aggregation *v1alpha1.MetricAggregation
+		err         bool
+	}{
+		{name: "not set"},
+		{name: "weighted average", aggregation: &v1alpha1.MetricAggregation{Policy: v1alpha1.WeightedAverageMetricAggregation}},
+		{name: "quorum", aggregation: &v1alpha1.MetricAggregation{Policy: v1alpha1.QuorumMetricAggregation, Quorum: intPtr(2)}},
+		{name: "unknown", aggregation: &v1alpha1.MetricAggregation{Policy: "Median"}, err: true},
+		{name: "quorum without quorum policy", aggregation: &v1alpha1.MetricAggregation{
+			Policy: v1alpha1.MaxMetricAggregation, Quorum: intPtr(2)}, err: true},
+		{name: "zero quorum", aggregation: &v1alpha1.MetricAggregation{Policy: v1alpha1.QuorumMetricAggregation,
+			Quorum: intPtr(0)}, err: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			errList := validateMetricAggregation(c.aggregation, field.NewPath("spec").Child("metricAggregation"))
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateTimeZone(t *testing.T) {
+	def := v1alpha1.CronMetricSpec{
+		Schedule:    "default",
+		MinReplicas: intPtr(9),
+		MaxReplicas: 10,
+	}
+	var cpuUtilization int32 = 30
+	cpu := v1alpha1.MetricSpec{
+		Type: v1alpha1.ContainerResourceMetricSourceType,
+		ContainerResource: &v1alpha1.ContainerResourceMetricSource{
+			Name: v1.ResourceCPU,
+			Target: v1alpha1.MetricTarget{
+				Type:               v1alpha1.UtilizationMetricType,
+				AverageUtilization: &cpuUtilization,
+			},
+			Container: "container",
+		},
+	}
+	fldPath := field.NewPath("spec")
+	for _, c := range []struct {
+		name string
+		mode v1alpha1.CronMetricMode
+		err  bool
+	}{
+		{
+			name: "valid time zones",
+			mode: v1alpha1.CronMetricMode{
+				TimeZone: "Asia/Shanghai",
+				CronMetrics: []v1alpha1.CronMetricSpec{
+					{Schedule: "* 20-21 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, MetricSpec: cpu, TimeZone: "Europe/Berlin"},
+					def,
+				},
+			},
+		},
+		{
+			name: "invalid mode time zone",
+			mode: v1alpha1.CronMetricMode{
+				TimeZone:    "Mars/Olympus",
+				CronMetrics: []v1alpha1.CronMetricSpec{{Schedule: "* 20-21 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, MetricSpec: cpu}, def},
+			},
+			err: true,
+		},
+		{
+			name: "invalid range time zone",
+			mode: v1alpha1.CronMetricMode{
+				CronMetrics: []v1alpha1.CronMetricSpec{
+					{Schedule: "* 20-21 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, MetricSpec: cpu, TimeZone: "Mars/Olympus"},
+					def,
+				},
+			},
+			err: true,
+		},
+		{
+			name: "same wall clock in different time zones, no conflict",
+			mode: v1alpha1.CronMetricMode{
+				TimeZone: "Asia/Shanghai",
+				CronMetrics: []v1alpha1.CronMetricSpec{
+					{Schedule: "* 20-21 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, MetricSpec: cpu},
+					{Schedule: "* 20-21 * * *", MinReplicas: intPtr(6), MaxReplicas: 8, MetricSpec: cpu, TimeZone: "UTC"},
+					def,
+				},
+			},
+		},
+		{
+			name: "same time in different time zones, conflict",
+			mode: v1alpha1.CronMetricMode{
+				TimeZone: "Asia/Shanghai",
+				CronMetrics: []v1alpha1.CronMetricSpec{
+					{Schedule: "* 20-21 * * *", MinReplicas: intPtr(5), MaxReplicas: 7, MetricSpec: cpu},
+					{Schedule: "* 12-13 * * *", MinReplicas: intPtr(6), MaxReplicas: 8, MetricSpec: cpu, TimeZone: "UTC"},
+					def,
+				},
+			},
+			err: true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			errList := validateCronMetric(&c.mode, fldPath.Child("cronMetric"), 0, nil)
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+
+	timeMode := &v1alpha1.TimeMode{
+		TimeZone:   "Mars/Olympus",
+		TimeRanges: []v1alpha1.TimeRange{{Schedule: "*/1 2-3 * * *", DesiredReplicas: 1, TimeZone: "Mars/Olympus"}},
+	}
+	if errList := validateTime(timeMode, fldPath.Child("time")); len(errList) != 2 {
+		t.Errorf("desired 2 time zone errors, actual: %v", errList)
+	}
+}
+
+func TestValidateCalendar(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&v1alpha1.HolidayCalendar{ObjectMeta: metav1.ObjectMeta{Name: "cn-public-holidays"}}); err != nil {
+		t.Fatal(err)
+	}
+	calendars := listers.NewHolidayCalendarLister(indexer)
+	def := v1alpha1.CronMetricSpec{
+		Schedule:    "default",
+		MinReplicas: intPtr(9),
+		MaxReplicas: 10,
+	}
+	fldPath := field.NewPath("spec")
+	for _, c := range []struct {
+		name      string
+		spec      v1alpha1.CronMetricSpec
+		calendars listers.HolidayCalendarLister
+		err       bool
+	}{
+		{
+			name:      "calendar exists",
+			spec:      v1alpha1.CronMetricSpec{Calendar: "cn-public-holidays", MinReplicas: intPtr(5), MaxReplicas: 7},
+			calendars: calendars,
+		},
+		{
+			name:      "calendar exists with schedule",
+			spec:      v1alpha1.CronMetricSpec{Calendar: "cn-public-holidays", Schedule: "* 20-22 * * *", MinReplicas: intPtr(5), MaxReplicas: 7},
+			calendars: calendars,
+		},
+		{
+			name:      "calendar not found",
+			spec:      v1alpha1.CronMetricSpec{Calendar: "missing", MinReplicas: intPtr(5), MaxReplicas: 7},
+			calendars: calendars,
+			err:       true,
+		},
+		{
+			name: "calendar not checked without lister",
+			spec: v1alpha1.CronMetricSpec{Calendar: "missing", MinReplicas: intPtr(5), MaxReplicas: 7},
+		},
+		{
+			name:      "calendar with invalid schedule",
+			spec:      v1alpha1.CronMetricSpec{Calendar: "cn-public-holidays", Schedule: "* 25 * * *", MinReplicas: intPtr(5), MaxReplicas: 7},
+			calendars: calendars,
+			err:       true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			mode := &v1alpha1.CronMetricMode{CronMetrics: []v1alpha1.CronMetricSpec{c.spec, def}}
+			errList := validateCronMetric(mode, fldPath.Child("cronMetric"), 0, c.calendars)
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateHolidayCalendar(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		holidays []v1alpha1.Holiday
+		err      bool
+	}{
+		{
+			name: "valid",
+			holidays: []v1alpha1.Holiday{
+				{Name: "national-day", Start: "2023-10-01", End: "2023-10-07"},
+				{Name: "new-year", Start: "2024-01-01"},
+			},
+		},
+		{name: "name not set", holidays: []v1alpha1.Holiday{{Start: "2024-01-01"}}, err: true},
+		{
+			name: "duplicate name",
+			holidays: []v1alpha1.Holiday{
+				{Name: "new-year", Start: "2023-01-01"},
+				{Name: "new-year", Start: "2024-01-01"},
+			},
+			err: true,
+		},
+		{name: "invalid start", holidays: []v1alpha1.Holiday{{Name: "new-year", Start: "2024/01/01"}}, err: true},
+		{name: "end before start", holidays: []v1alpha1.Holiday{{Name: "new-year", Start: "2024-01-02", End: "2024-01-01"}}, err: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			calendar := &v1alpha1.HolidayCalendar{
+				ObjectMeta: metav1.ObjectMeta{Name: "cn-public-holidays"},
+				Spec:       v1alpha1.HolidayCalendarSpec{Holidays: c.holidays},
+			}
+			errList := ValidateHolidayCalendar(calendar)
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateWindow(t *testing.T) {
+	def := v1alpha1.CronMetricSpec{
+		Schedule:    "default",
+		MinReplicas: intPtr(9),
+		MaxReplicas: 10,
+	}
+	hour := &metav1.Duration{Duration: time.Hour}
+	fldPath := field.NewPath("spec")
+	for _, c := range []struct {
+		name string
+		spec v1alpha1.CronMetricSpec
+		err  bool
+	}{
+		{name: "start and end", spec: v1alpha1.CronMetricSpec{Start: "0 9 * * *", End: "0 18 * * *", MinReplicas: intPtr(5), MaxReplicas: 7}},
+		{name: "start and duration", spec: v1alpha1.CronMetricSpec{Start: "0 9 * * *", Duration: hour, MinReplicas: intPtr(5), MaxReplicas: 7}},
+		{name: "start without end", spec: v1alpha1.CronMetricSpec{Start: "0 9 * * *", MinReplicas: intPtr(5), MaxReplicas: 7}, err: true},
+		{name: "start with schedule", spec: v1alpha1.CronMetricSpec{Schedule: "* 9 * * *", Start: "0 9 * * *", Duration: hour, MinReplicas: intPtr(5), MaxReplicas: 7}, err: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			mode := &v1alpha1.CronMetricMode{CronMetrics: []v1alpha1.CronMetricSpec{c.spec, def}}
+			errList := validateCronMetric(mode, fldPath.Child("cronMetric"), 0, nil)
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+
+	timeMode := &v1alpha1.TimeMode{
+		TimeRanges: []v1alpha1.TimeRange{
+			{Start: "0 9 * * *", End: "0 18 * * *", DesiredReplicas: 1},
+			{Start: "0 9 * * *", DesiredReplicas: 1},
+		},
+	}
+	if errList := validateTime(timeMode, fldPath.Child("time")); len(errList) != 1 {
+		t.Errorf("desired 1 window error, actual: %v", errList)
+	}
+}
+
+func TestValidateWebhookAuthentication(t *testing.T) {
+	secrets := fake.NewSimpleClientset(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-token", Namespace: "default"},
+			Data:       map[string][]byte{v1.ServiceAccountTokenKey: []byte("token")},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-cert", Namespace: "default"},
+			Data:       map[string][]byte{v1.TLSCertKey: []byte("cert")},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-empty", Namespace: "default"},
+		},
+	).CoreV1()
+	url := "https://webhook.example.com/scale"
+	fldPath := field.NewPath("spec")
+	for _, c := range []struct {
+		name      string
+		auth      *v1alpha1.WebhookAuthentication
+		namespace string
+		secrets   corev1client.SecretsGetter
+		err       bool
+	}{
+		{
+			name:      "token secret",
+			auth:      &v1alpha1.WebhookAuthentication{SecretName: "webhook-token"},
+			namespace: "default",
+			secrets:   secrets,
+		},
+		{
+			name:      "secret in other namespace",
+			auth:      &v1alpha1.WebhookAuthentication{SecretName: "webhook-token"},
+			namespace: "other",
+			secrets:   secrets,
+			err:       true,
+		},
+		{
+			name:      "certificate without key",
+			auth:      &v1alpha1.WebhookAuthentication{SecretName: "webhook-cert"},
+			namespace: "default",
+			secrets:   secrets,
+			err:       true,
+		},
+		{
+			name:      "no credentials",
+			auth:      &v1alpha1.WebhookAuthentication{SecretName: "webhook-empty"},
+			namespace: "default",
+			secrets:   secrets,
+			err:       true,
+		},
+		{
+			name:      "empty secret name",
+			auth:      &v1alpha1.WebhookAuthentication{},
+			namespace: "default",
+			err:       true,
+		},
+		{
+			name:      "secret not checked without client",
+			auth:      &v1alpha1.WebhookAuthentication{SecretName: "missing"},
+			namespace: "default",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			mode := &v1alpha1.WebhookMode{
+				WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url},
+				Authentication:      c.auth,
+			}
+			errList := validateWebhook(mode, c.namespace, fldPath.Child("webhook"), c.secrets)
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookResilience(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+	fldPath := field.NewPath("spec", "webhook")
+	for _, c := range []struct {
+		name string
+		mode v1alpha1.WebhookMode
+		err  bool
+	}{
+		{
+			name: "valid",
+			mode: v1alpha1.WebhookMode{
+				Timeout: duration(3 * time.Second),
+				Retry: &v1alpha1.WebhookRetry{MaxRetries: intPtr(3), Backoff: duration(100 * time.Millisecond),
+					MaxBackoff: duration(time.Second)},
+				CircuitBreaker: &v1alpha1.WebhookCircuitBreaker{FailureThreshold: intPtr(5),
+					OpenDuration: duration(time.Minute), Fallback: v1alpha1.LastGoodFallback},
+			},
+		},
+		{
+			name: "defaults",
+			mode: v1alpha1.WebhookMode{Retry: &v1alpha1.WebhookRetry{}, CircuitBreaker: &v1alpha1.WebhookCircuitBreaker{}},
+		},
+		{
+			name: "zero timeout",
+			mode: v1alpha1.WebhookMode{Timeout: duration(0)},
+			err:  true,
+		},
+		{
+			name: "too many retries",
+			mode: v1alpha1.WebhookMode{Retry: &v1alpha1.WebhookRetry{MaxRetries: intPtr(11)}},
+			err:  true,
+		},
+		{
+			name: "max backoff less than backoff",
+			mode: v1alpha1.WebhookMode{Retry: &v1alpha1.WebhookRetry{Backoff: duration(time.Second),
+				MaxBackoff: duration(time.Millisecond)}},
+			err: true,
+		},
+		{
+			name: "zero failure threshold",
+			mode: v1alpha1.WebhookMode{CircuitBreaker: &v1alpha1.WebhookCircuitBreaker{FailureThreshold: intPtr(0)}},
+			err:  true,
+		},
+		{
+			name: "unknown fallback",
+			mode: v1alpha1.WebhookMode{CircuitBreaker: &v1alpha1.WebhookCircuitBreaker{Fallback: "Max"}},
+			err:  true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			errList := validateWebhookResilience(&c.mode, fldPath)
+			if c.err != (len(errList) > 0) {
+				t.Errorf("desired error: %v, actual: %v", c.err, errList)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookProtocol(t *testing.T) {
+	url := "https://webhook.example.com:9443"
+	for _, c := range []struct {
+		protocol v1alpha1.WebhookProtocol
+		batch    *v1alpha1.WebhookBatch
+		err      bool
+	}{
+		{protocol: ""},
+		{protocol: v1alpha1.HTTPWebhookProtocol},
+		{protocol: v1alpha1.GRPCWebhookProtocol},
+		{protocol: "WebSocket", err: true},
+		{protocol: v1alpha1.HTTPWebhookProtocol, batch: &v1alpha1.WebhookBatch{MaxSize: intPtr(50)}},
+		{protocol: v1alpha1.HTTPWebhookProtocol, batch: &v1alpha1.WebhookBatch{MaxSize: intPtr(0)}, err: true},
+		{protocol: v1alpha1.GRPCWebhookProtocol, batch: &v1alpha1.WebhookBatch{}, err: true},
+	} {
+		mode := &v1alpha1.WebhookMode{
+			WebhookClientConfig: &admregv1b.WebhookClientConfig{URL: &url},
+			Protocol:            c.protocol,
+			Batch:               c.batch,
+		}
+		errList := validateWebhook(mode, "default", field.NewPath("spec", "webhook"), nil)
+		if c.err != (len(errList) > 0) {
+			t.Errorf("%v: desired error: %v, actual: %v", c.protocol, c.err, errList)
+		}
+	}
+}
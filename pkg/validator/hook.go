@@ -27,9 +27,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog"
 
 	"github.com/ocgi/general-pod-autoscaler/pkg/apis/autoscaling/v1alpha1"
+	listers "github.com/ocgi/general-pod-autoscaler/pkg/client/listers/autoscaling/v1alpha1"
 	"github.com/ocgi/general-pod-autoscaler/pkg/validation"
 )
 
@@ -41,6 +43,10 @@ var (
 
 type webhookServer struct {
 	*http.Server
+	// calendars checks the holiday calendars referenced by GPA exist
+	calendars listers.HolidayCalendarLister
+	// secrets checks the webhook authentication secrets referenced by GPA
+	secrets corev1client.SecretsGetter
 }
 
 func init() {
@@ -49,8 +55,8 @@ func init() {
 	runtimeScheme.AddKnownTypes(v1alpha1.SchemeGroupVersion)
 }
 
-func NewWebhookServer() *webhookServer {
-	return &webhookServer{}
+func NewWebhookServer(calendars listers.HolidayCalendarLister, secrets corev1client.SecretsGetter) *webhookServer {
+	return &webhookServer{calendars: calendars, secrets: secrets}
 }
 
 // validate deployments and services
@@ -64,7 +70,9 @@ func (whsvr *webhookServer) mutate(ar *v1beta1.AdmissionReview) *v1beta1.Admissi
 	var causes []metav1.StatusCause
 	switch req.Kind.Kind {
 	case "GeneralPodAutoscaler":
-		patch, causes, err = forGPA(req)
+		patch, causes, err = forGPA(req, whsvr.calendars, whsvr.secrets)
+	case "HolidayCalendar":
+		patch, causes, err = forHolidayCalendar(req)
 
 	default:
 		return &v1beta1.AdmissionResponse{
@@ -159,7 +167,8 @@ func (whsvr *webhookServer) Serve(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func forGPA(req *v1beta1.AdmissionRequest) ([]byte, []metav1.StatusCause, error) {
+func forGPA(req *v1beta1.AdmissionRequest, calendars listers.HolidayCalendarLister,
+	secrets corev1client.SecretsGetter) ([]byte, []metav1.StatusCause, error) {
 	var errs field.ErrorList
 	causes := make([]metav1.StatusCause, 0)
 	defer func() {
@@ -182,7 +191,7 @@ func forGPA(req *v1beta1.AdmissionRequest) ([]byte, []metav1.StatusCause, error)
 	}
 	if req.Operation == v1beta1.Create {
 		// validate
-		errs = validation.ValidateHorizontalPodAutoscaler(&gpa)
+		errs = validation.ValidateHorizontalPodAutoscaler(&gpa, calendars, secrets)
 		if len(errs) > 0 {
 			return nil, causes, errs.ToAggregate()
 		}
@@ -193,10 +202,32 @@ func forGPA(req *v1beta1.AdmissionRequest) ([]byte, []metav1.StatusCause, error)
 			return nil, nil, err
 		}
 		// validate
-		errs = validation.ValidateHorizontalPodAutoscalerUpdate(&gpa, &oldGPA)
+		errs = validation.ValidateHorizontalPodAutoscalerUpdate(&gpa, &oldGPA, calendars, secrets)
 		if len(errs) > 0 {
 			return nil, causes, errs.ToAggregate()
 		}
 	}
 	return nil, nil, nil
 }
+
+func forHolidayCalendar(req *v1beta1.AdmissionRequest) ([]byte, []metav1.StatusCause, error) {
+	var calendar v1alpha1.HolidayCalendar
+	if err := json.Unmarshal(req.Object.Raw, &calendar); err != nil {
+		klog.Errorf("Could not unmarshal raw object: %v", err)
+		return nil, nil, err
+	}
+	errs := validation.ValidateHolidayCalendar(&calendar)
+	if len(errs) == 0 {
+		return nil, nil, nil
+	}
+	causes := make([]metav1.StatusCause, 0, len(errs))
+	for i := range errs {
+		err := errs[i]
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseType(err.Type),
+			Message: err.ErrorBody(),
+			Field:   err.Field,
+		})
+	}
+	return nil, causes, errs.ToAggregate()
+}